@@ -0,0 +1,126 @@
+package di
+
+import (
+	"testing"
+)
+
+type benchLeafTest struct{}
+
+type benchNodeTest struct {
+	Leaf *benchLeafTest
+}
+
+type benchConfigTest struct {
+	Addr string `json:"addr"`
+}
+
+func (c benchConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+type benchClientTest struct {
+	Addr string
+}
+
+// newBenchRegistry registers a leaf, a node depending on it and a client configured by benchConfigTest,
+// creating each once so the benchmarks measure the hot path.
+func newBenchRegistry(b *testing.B) (Registry, Context) {
+	registry := NewRegistry(WithSilent())
+	ctx := NewContext(benchConfigTest{Addr: "localhost"})
+
+	err := Registrations(
+		Register[*benchLeafTest](func(ctx Context, opts *RegistryOpts) (*benchLeafTest, error) {
+			return &benchLeafTest{}, nil
+		}, WithRegistry(registry)),
+		Register[*benchNodeTest](func(ctx Context, opts *RegistryOpts) (*benchNodeTest, error) {
+			leaf, err := Create[*benchLeafTest](ctx, WithRegistry(opts.Registry))
+			return &benchNodeTest{Leaf: leaf}, err
+		}, WithRegistry(registry)),
+		RegisterConfiguration[benchConfigTest](func(ctx Context, opts *RegistryOpts) (benchConfigTest, error) {
+			return benchConfigTest{Addr: "localhost"}, nil
+		}, WithRegistry(registry)),
+		RegisterPair[*benchClientTest, benchConfigTest](func(ctx Context, opts *RegistryOpts, cfg benchConfigTest) (*benchClientTest, error) {
+			return &benchClientTest{Addr: cfg.Addr}, nil
+		}, func(ctx Context, opts *RegistryOpts) (benchConfigTest, error) {
+			return benchConfigTest{Addr: "localhost"}, nil
+		}, WithRegistry(registry)),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if _, err = Create[*benchNodeTest](ctx, WithRegistry(registry)); err != nil {
+		b.Fatal(err)
+	}
+	if _, err = CreatePair[*benchClientTest, benchConfigTest](ctx, WithRegistry(registry)); err != nil {
+		b.Fatal(err)
+	}
+
+	return registry, ctx
+}
+
+func BenchmarkCreate(b *testing.B) {
+	registry, ctx := newBenchRegistry(b)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := Create[*benchNodeTest](ctx, WithRegistry(registry)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCreatePair(b *testing.B) {
+	registry, ctx := newBenchRegistry(b)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := CreatePair[*benchClientTest, benchConfigTest](ctx, WithRegistry(registry)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCreateConfiguration(b *testing.B) {
+	registry, ctx := newBenchRegistry(b)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := CreateConfiguration[benchConfigTest](ctx, WithRegistry(registry)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSafeTypeAssert(b *testing.B) {
+	var instance any = &benchLeafTest{}
+
+	b.Run("direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, ok := SafeTypeAssert[*benchLeafTest](instance); !ok {
+				b.Fatal("assertion failed")
+			}
+		}
+	})
+
+	b.Run("dereference", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, ok := SafeTypeAssert[benchLeafTest](instance); !ok {
+				b.Fatal("assertion failed")
+			}
+		}
+	})
+}
+
+func BenchmarkResolveDIReferences(b *testing.B) {
+	document := `{"cache": {"ttl": 10, "driver": "redis"}, "service": {"ttl": "${di.cache.ttl}", "driver": "${di.cache.driver:-memcached}"}}`
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := ResolveDIReferences(document); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package di
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstracts the current time for the time based features of the registry,
+// so tests can drive expirations deterministically by registering a FrozenClock.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the Clock used when none is registered in the container.
+var SystemClock Clock = systemClock{}
+
+// FrozenClock is a Clock that only moves when told to. It's safe for concurrent use.
+type FrozenClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewFrozenClock returns a FrozenClock stopped at now.
+func NewFrozenClock(now time.Time) *FrozenClock {
+	return &FrozenClock{now: now}
+}
+
+func (c *FrozenClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.now
+}
+
+// Set moves the clock to now.
+func (c *FrozenClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
+// Advance moves the clock forward by d.
+func (c *FrozenClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// ClockFor returns the Clock registered in the registry selected by the options,
+// falling back to SystemClock when none is registered.
+//
+//	di.Register[di.Clock](func(ctx di.Context, opts *di.RegistryOpts) (di.Clock, error) {
+//		return di.NewFrozenClock(start), nil
+//	})
+func ClockFor(ctx Context, options ...func(opts *RegistryOpts)) Clock {
+	if !IsRegistered[Clock](options...) {
+		return SystemClock
+	}
+
+	clock, err := Create[Clock](ctx, options...)
+	if err != nil || clock == nil {
+		return SystemClock
+	}
+
+	return clock
+}
@@ -0,0 +1,33 @@
+package di
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrozenClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFrozenClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+
+	clock.Set(start)
+	assert.Equal(t, start, clock.Now())
+}
+
+func TestClockFor(t *testing.T) {
+	registry := NewRegistry()
+	assert.Equal(t, SystemClock, ClockFor(NewContext(), WithRegistry(registry)))
+
+	frozen := NewFrozenClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, Register[Clock](func(ctx Context, opts *RegistryOpts) (Clock, error) {
+		return frozen, nil
+	}, WithRegistry(registry)))
+
+	assert.Same(t, frozen, ClockFor(NewContext(), WithRegistry(registry)))
+}
@@ -0,0 +1,350 @@
+package di
+
+import (
+	"bytes"
+	goctx "context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gojson "github.com/goccy/go-json"
+	"github.com/pixie-sh/errors-go"
+)
+
+// ConfigChange describes a reload of a ReloadableConfiguration to its OnChange subscribers.
+type ConfigChange struct {
+	// Paths are the dot separated paths of the nodes that changed, added or removed,
+	// usable with HotInstanceEvictor.ConfigurationChanged to rebuild the instances built from them.
+	Paths []string
+}
+
+// ConfigurationSource loads the document of a ReloadableConfiguration, e.g. from a file or a remote store.
+// Implementations must be safe for concurrent use.
+type ConfigurationSource interface {
+	// Load returns the JSON document of the configuration and its version, changing along with it.
+	Load(ctx goctx.Context) (data []byte, version string, err error)
+}
+
+// configurationVersioner is implemented by sources able to tell their version without loading the
+// document, polled by ReloadableConfiguration.Watch.
+type configurationVersioner interface {
+	Version(ctx goctx.Context) (string, error)
+}
+
+// ReloadableConfiguration is a Configuration loaded from a ConfigurationSource into T, the struct
+// describing it, with its ${di.path} references resolved. Reload and Watch swap the loaded value
+// atomically, so the contexts created with it see the new configuration on their next lookup:
+//
+//	cfg, err := di.NewFileConfiguration[AppConfig]("config.json")
+//	cfg.OnChange(func(change di.ConfigChange) { registry.ConfigurationChanged(change.Paths...) })
+//	registry.Go(ctx, "config-watcher", func(ctx di.Context) error { return cfg.Watch(ctx, time.Second) })
+//
+// The RawConfiguration of a context is decoded once, use Configuration lookups for reloaded values.
+type ReloadableConfiguration[T any] struct {
+	source    ConfigurationSource
+	envPrefix string
+	lookup    []LookupOption
+	current   atomic.Pointer[configurationSnapshot[T]]
+
+	mu          sync.Mutex
+	subscribers map[int]func(change ConfigChange)
+	nextID      int
+}
+
+// FileConfiguration is a ReloadableConfiguration loaded from a JSON file, or TOML for a .toml extension.
+type FileConfiguration[T any] = ReloadableConfiguration[T]
+
+// configurationSnapshot is a loaded version of a ReloadableConfiguration, the Configuration lookups are served from.
+type configurationSnapshot[T any] struct {
+	value   T
+	raw     ConfigRawData
+	version string
+	lookup  []LookupOption
+}
+
+func (s *configurationSnapshot[T]) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(s.value, lookupPath, s.lookup...)
+}
+
+// configurationSnapshotter is implemented by configurations changing over time, lookups are cached
+// against their current immutable snapshot so a reload doesn't serve cached stale nodes.
+type configurationSnapshotter interface {
+	snapshot() Configuration
+}
+
+// ConfigurationOption configures a ReloadableConfiguration.
+type ConfigurationOption func(c *configurationOptions)
+
+type configurationOptions struct {
+	envPrefix string
+	lookup    []LookupOption
+}
+
+// WithEnvOverrides merges the environment variables starting with prefix over the document every time
+// it's loaded, before its ${di.path} references are resolved, see LoadConfigurationFromEnv.
+func WithEnvOverrides(prefix string) ConfigurationOption {
+	return func(c *configurationOptions) {
+		c.envPrefix = prefix
+	}
+}
+
+// WithLookupOptions serves the Configuration lookups with options, e.g. WithCaseInsensitiveLookup.
+func WithLookupOptions(options ...LookupOption) ConfigurationOption {
+	return func(c *configurationOptions) {
+		c.lookup = append(c.lookup, options...)
+	}
+}
+
+// NewFileConfiguration loads the JSON or TOML file at path into a FileConfiguration.
+func NewFileConfiguration[T any](path string, options ...ConfigurationOption) (*FileConfiguration[T], error) {
+	return NewReloadableConfiguration[T](goctx.Background(), fileSource{path: path}, options...)
+}
+
+// NewReloadableConfiguration loads the document of source into a ReloadableConfiguration.
+func NewReloadableConfiguration[T any](ctx goctx.Context, source ConfigurationSource, options ...ConfigurationOption) (*ReloadableConfiguration[T], error) {
+	var opts configurationOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	c := &ReloadableConfiguration[T]{source: source, envPrefix: opts.envPrefix, lookup: opts.lookup, subscribers: map[int]func(change ConfigChange){}}
+
+	snapshot, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.current.Store(snapshot)
+	return c, nil
+}
+
+// Current returns the configuration loaded last.
+func (c *ReloadableConfiguration[T]) Current() T {
+	return c.current.Load().value
+}
+
+func (c *ReloadableConfiguration[T]) LookupNode(lookupPath string) (any, error) {
+	return c.current.Load().LookupNode(lookupPath)
+}
+
+func (c *ReloadableConfiguration[T]) snapshot() Configuration {
+	return c.current.Load()
+}
+
+// OnChange subscribes fn to the reloads changing the configuration, it returns the function unsubscribing it.
+// Subscribers are called in the goroutine reloading, after the new configuration is swapped in.
+func (c *ReloadableConfiguration[T]) OnChange(fn func(change ConfigChange)) (unsubscribe func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.nextID
+	c.nextID++
+	c.subscribers[id] = fn
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		delete(c.subscribers, id)
+	}
+}
+
+// Reload loads the document again, swapping the configuration in and notifying the subscribers when it changed.
+// The current configuration is kept when the document cannot be loaded.
+func (c *ReloadableConfiguration[T]) Reload() error {
+	return c.reload(goctx.Background())
+}
+
+func (c *ReloadableConfiguration[T]) reload(ctx goctx.Context) error {
+	snapshot, err := c.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	previous := c.current.Swap(snapshot)
+	paths := changedPaths(previous.raw, snapshot.raw, "")
+	if len(paths) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	ids := make([]int, 0, len(c.subscribers))
+	for id := range c.subscribers {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	subscribers := make([]func(change ConfigChange), 0, len(ids))
+	for _, id := range ids {
+		subscribers = append(subscribers, c.subscribers[id])
+	}
+	c.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(ConfigChange{Paths: paths})
+	}
+
+	return nil
+}
+
+// Watch polls the source every interval, reloading the configuration when its version changes,
+// until ctx is done. Failed reloads are logged and retried on the next change.
+func (c *ReloadableConfiguration[T]) Watch(ctx goctx.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if versioner, ok := c.source.(configurationVersioner); ok {
+			version, err := versioner.Version(ctx)
+			if err != nil {
+				Logger.With("source", c.source).Warn("di failed to check configuration source '%s': %s", c.source, err)
+				continue
+			}
+
+			if version == c.current.Load().version {
+				continue
+			}
+		}
+
+		if err := c.reload(ctx); err != nil {
+			Logger.With("source", c.source).Warn("di failed to reload configuration source '%s': %s", c.source, err)
+		}
+	}
+}
+
+func (c *ReloadableConfiguration[T]) load(ctx goctx.Context) (*configurationSnapshot[T], error) {
+	data, version, err := c.source.Load(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load configuration source %s", c.source, ConfigurationLookupErrorCode)
+	}
+
+	if len(c.envPrefix) > 0 {
+		if data, err = mergeEnvOverrides(data, c.envPrefix); err != nil {
+			return nil, errors.Wrap(err, "failed to load configuration source %s", c.source, ConfigurationLookupErrorCode)
+		}
+	}
+
+	// references are resolved once, so both views hold the same secrets and providers are hit once
+	resolved, err := ResolveDIReferences(string(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve DI references of configuration source %s", c.source, ConfigurationLookupErrorCode)
+	}
+
+	snapshot := &configurationSnapshot[T]{version: version, lookup: c.lookup}
+	if err = gojson.Unmarshal([]byte(resolved), &snapshot.value); err != nil {
+		return nil, errors.Wrap(err, "failed to load configuration source %s", c.source, ConfigurationLookupErrorCode)
+	}
+
+	if err = gojson.Unmarshal([]byte(resolved), &snapshot.raw); err != nil {
+		return nil, errors.Wrap(err, "failed to load configuration source %s", c.source, ConfigurationLookupErrorCode)
+	}
+
+	return snapshot, nil
+}
+
+// fileSource is the ConfigurationSource of a FileConfiguration, versioned by the modification time
+// and size of the file.
+type fileSource struct {
+	path string
+}
+
+func (s fileSource) String() string {
+	return s.path
+}
+
+func (s fileSource) Version(_ goctx.Context) (string, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+func (s fileSource) Load(ctx goctx.Context) ([]byte, string, error) {
+	version, err := s.Version(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if strings.EqualFold(filepath.Ext(s.path), ".toml") {
+		if data, err = tomlToJSON(data); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return data, version, nil
+}
+
+// mergeEnvOverrides merges the environment variables starting with prefix over the JSON document data,
+// keeping its ${di.path} references for the resolution.
+func mergeEnvOverrides(data []byte, prefix string) ([]byte, error) {
+	var document ConfigRawData
+	if err := gojson.Unmarshal([]byte(makeJSONValid(string(data))), &document); err != nil {
+		return nil, err
+	}
+
+	return gojson.Marshal(MergeConfigurations(document, LoadConfigurationFromEnv(prefix)))
+}
+
+// changedPaths returns the sorted paths of the nodes differing between previous and next, descending
+// into the maps present in both.
+func changedPaths(previous, next ConfigRawData, prefix string) []string {
+	var paths []string
+	for key := range merged(previous, next) {
+		path := key
+		if len(prefix) > 0 {
+			path = prefix + "." + key
+		}
+
+		previousChild, previousIsMap := previous[key].(map[string]any)
+		nextChild, nextIsMap := next[key].(map[string]any)
+		if previousIsMap && nextIsMap {
+			paths = append(paths, changedPaths(previousChild, nextChild, path)...)
+			continue
+		}
+
+		if !reflect.DeepEqual(previous[key], next[key]) && !sameJSON(previous[key], next[key]) {
+			paths = append(paths, path)
+		}
+	}
+
+	slices.Sort(paths)
+	return paths
+}
+
+// merged returns the keys of both maps.
+func merged(a, b ConfigRawData) map[string]struct{} {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for key := range a {
+		keys[key] = struct{}{}
+	}
+	for key := range b {
+		keys[key] = struct{}{}
+	}
+
+	return keys
+}
+
+// sameJSON reports whether a and b encode to the same JSON.
+func sameJSON(a, b any) bool {
+	encodedA, errA := gojson.Marshal(a)
+	encodedB, errB := gojson.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
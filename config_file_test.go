@@ -0,0 +1,108 @@
+package di
+
+import (
+	goctx "context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fileConfigTest struct {
+	Database struct {
+		Host     string `json:"host"`
+		Password string `json:"password"`
+	} `json:"database"`
+	Cache struct {
+		TTL int `json:"ttl"`
+	} `json:"cache"`
+}
+
+// countingSecretProviderTest resolves every secret to value, counting the reads.
+type countingSecretProviderTest struct {
+	reads *atomic.Int32
+	value string
+}
+
+func (p countingSecretProviderTest) Secret(_ goctx.Context, _ string) (string, error) {
+	p.reads.Add(1)
+	return p.value, nil
+}
+
+func TestFileConfiguration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	write(`{"database": {"host": "db", "password": "old"}, "cache": {"ttl": 10}}`)
+	cfg, err := NewFileConfiguration[fileConfigTest](path)
+	require.NoError(t, err)
+
+	registry := NewTestRegistry(t)
+	ctx := NewContext(cfg)
+	password := func() string {
+		value, err := ConfigurationLookup[string](ctx, &RegistryOpts{Registry: registry, ConfigNodePath: "database.password"})
+		require.NoError(t, err)
+		return value
+	}
+	assert.Equal(t, "old", password())
+
+	var changes []ConfigChange
+	unsubscribe := cfg.OnChange(func(change ConfigChange) {
+		changes = append(changes, change)
+	})
+
+	write(`{"database": {"host": "db", "password": "new"}, "cache": {"ttl": 10}}`)
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, "new", password())
+	assert.Equal(t, "new", cfg.Current().Database.Password)
+	require.Len(t, changes, 1)
+	assert.Equal(t, []string{"database.password"}, changes[0].Paths)
+
+	t.Run("unchanged file notifies nothing", func(t *testing.T) {
+		require.NoError(t, cfg.Reload())
+		assert.Len(t, changes, 1)
+	})
+
+	t.Run("invalid file keeps the configuration", func(t *testing.T) {
+		write(`{"database": `)
+		assert.Error(t, cfg.Reload())
+		assert.Equal(t, "new", password())
+	})
+
+	t.Run("unsubscribed handlers aren't notified", func(t *testing.T) {
+		unsubscribe()
+		write(`{"database": {"host": "db", "password": "new"}, "cache": {"ttl": 20}}`)
+		require.NoError(t, cfg.Reload())
+		assert.Len(t, changes, 1)
+		assert.Equal(t, 20, cfg.Current().Cache.TTL)
+	})
+
+	t.Run("secrets resolved once per load", func(t *testing.T) {
+		var reads atomic.Int32
+		RegisterSecretProvider("filetest", countingSecretProviderTest{reads: &reads, value: "rotated"})
+		t.Cleanup(func() { assert.True(t, UnregisterSecretProvider("filetest")) })
+
+		write(`{"database": {"host": "db", "password": "${secret.filetest:db.password}"}, "cache": {"ttl": 10}}`)
+		require.NoError(t, cfg.Reload())
+		assert.Equal(t, int32(1), reads.Load())
+		assert.Equal(t, "rotated", password())
+		assert.Equal(t, "rotated", cfg.Current().Database.Password)
+	})
+}
+
+func TestFileConfigurationLookupOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"database": {"host": "db"}}`), 0o600))
+
+	cfg, err := NewFileConfiguration[fileConfigTest](path, WithLookupOptions(WithCaseInsensitiveLookup()))
+	require.NoError(t, err)
+
+	node, err := cfg.LookupNode("Database.HOST")
+	require.NoError(t, err)
+	assert.Equal(t, "db", node)
+}
@@ -0,0 +1,73 @@
+package di
+
+import (
+	"github.com/pixie-sh/errors-go"
+)
+
+// CreateConfigurationAt creates the configuration T found at path in the configuration of ctx,
+// without registering T nor naming the node with an injection token:
+//
+//	chargebee, err := di.CreateConfigurationAt[ChargebeeConfig](ctx, "payment_business_layer.chargebee")
+//
+// The node is asserted when it already is a T, or decoded with Decode when it's a raw map, then
+// checked for its required keys. The ${ctx.*} references of the node are resolved, and the options
+// may give the registry whose node cache is used and defaults with WithConfigDefaults. Nothing is
+// kept in the registry, every call looks the node up again.
+func CreateConfigurationAt[T any](ctx Context, path string, options ...func(opts *RegistryOpts)) (T, error) {
+	var result T
+
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	if ctx == nil || ctx.Configuration() == nil {
+		return result, errors.New("cannot create %s at %s without configuration", TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), path, ConfigurationLookupErrorCode)
+	}
+
+	defaults, hasDefaults := configDefaultsFor[T](&registryOpts)
+	node, err := lookupNode(ctx, &registryOpts, path)
+	if (err != nil || node == nil) && hasDefaults {
+		node, err = defaults, nil
+	}
+
+	if err != nil || node == nil {
+		return result, errors.Wrap(err, "failed to lookup configuration node %s", path, ConfigurationLookupErrorCode)
+	}
+
+	node, err = resolveContextReferences(ctx, node)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to resolve context references of %s", path, ConfigurationLookupErrorCode)
+	}
+
+	if hasDefaults {
+		node, err = applyDefaults(node, defaults)
+		if err != nil {
+			return result, errors.Wrap(err, "failed to apply the defaults of %s", path, ConfigurationLookupErrorCode)
+		}
+	}
+
+	typed, good := typeAssertFor[T](node, &registryOpts)
+	if !good {
+		if _, raw := node.(map[string]any); !raw {
+			return result, errors.New("configuration node %s is a %T, not a %s", path, node, TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), ConfigurationLookupErrorCode)
+		}
+
+		typed, err = Decode[T](node)
+		if err != nil {
+			return result, errors.Wrap(err, "failed to decode configuration node %s", path, ConfigurationLookupErrorCode)
+		}
+	}
+
+	if err = checkRequired(typed); err != nil {
+		return result, errors.Wrap(err, "configuration node %s is incomplete", path, RequiredConfigurationErrorCode)
+	}
+
+	return typed, nil
+}
@@ -0,0 +1,56 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type atChargebeeConfigTest struct {
+	Site   string `json:"site"`
+	APIKey string `json:"api_key,required"`
+}
+
+type atRootConfigTest struct {
+	PaymentBusinessLayer ConfigRawData         `json:"payment_business_layer"`
+	Billing              atChargebeeConfigTest `json:"billing"`
+}
+
+func (c atRootConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+func TestCreateConfigurationAt(t *testing.T) {
+	ctx := NewContext(atRootConfigTest{
+		PaymentBusinessLayer: ConfigRawData{
+			"chargebee": map[string]any{"site": "acme", "api_key": "secret"},
+			"partial":   map[string]any{"site": "acme"},
+			"timeout":   "5s",
+		},
+		Billing: atChargebeeConfigTest{Site: "billing", APIKey: "key"},
+	})
+
+	chargebee, err := CreateConfigurationAt[atChargebeeConfigTest](ctx, "payment_business_layer.chargebee")
+	require.NoError(t, err)
+	assert.Equal(t, atChargebeeConfigTest{Site: "acme", APIKey: "secret"}, chargebee)
+
+	billing, err := CreateConfigurationAt[atChargebeeConfigTest](ctx, "billing")
+	require.NoError(t, err)
+	assert.Equal(t, "billing", billing.Site)
+
+	defaulted, err := CreateConfigurationAt[atChargebeeConfigTest](ctx, "payment_business_layer.missing", WithConfigDefaults(atChargebeeConfigTest{Site: "default", APIKey: "default"}))
+	require.NoError(t, err)
+	assert.Equal(t, "default", defaulted.Site)
+
+	_, err = CreateConfigurationAt[atChargebeeConfigTest](ctx, "payment_business_layer.partial")
+	_, isIncomplete := errors.Has(err, RequiredConfigurationErrorCode)
+	assert.True(t, isIncomplete)
+
+	_, err = CreateConfigurationAt[atChargebeeConfigTest](ctx, "payment_business_layer.timeout")
+	assert.ErrorContains(t, err, "is a string")
+
+	_, err = CreateConfigurationAt[atChargebeeConfigTest](ctx, "payment_business_layer.missing")
+	assert.ErrorContains(t, err, "failed to lookup configuration node payment_business_layer.missing")
+}
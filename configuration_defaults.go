@@ -0,0 +1,122 @@
+package di
+
+import (
+	"reflect"
+
+	gojson "github.com/goccy/go-json"
+	"github.com/pixie-sh/errors-go"
+)
+
+// DefaultsProvider is implemented by configuration structs holding default values. ConfigurationLookup
+// merges the configuration node looked up over Defaults, so a partial config file only sets what
+// differs from them, and an absent node resolves to the defaults:
+//
+//	func (HTTPConfig) Defaults() HTTPConfig { return HTTPConfig{Port: 8080, ReadTimeout: "5s"} }
+//
+// Defaults given with WithConfigDefaults take precedence over the DefaultsProvider of the type.
+type DefaultsProvider[T any] interface {
+	Defaults() T
+}
+
+// WithConfigDefaults returns a function that sets the defaults of a configuration, of the configuration
+// type, merged under the node looked up by ConfigurationLookup, see DefaultsProvider.
+// Given at registration, they apply to every creation of the registered configuration.
+func WithConfigDefaults(defaults any) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.ConfigDefaults = defaults
+	}
+}
+
+// configDefaultsHandler wraps a configuration creation function so its lookups merge defaults,
+// unless the creation brings its own.
+func configDefaultsHandler[CT any](fn TypedCreateInstanceNoConfigHandler[CT], defaults any) TypedCreateInstanceNoConfigHandler[CT] {
+	return func(ctx Context, opts *RegistryOpts) (CT, error) {
+		if opts.ConfigDefaults == nil {
+			opts.ConfigDefaults = defaults
+		}
+
+		return fn(ctx, opts)
+	}
+}
+
+// configDefaultsFor returns the defaults of the configuration T looked up with opts, if any.
+func configDefaultsFor[T any](opts *RegistryOpts) (T, bool) {
+	if opts.ConfigDefaults != nil {
+		return SafeTypeAssert[T](opts.ConfigDefaults)
+	}
+
+	var zero T
+	if provider, ok := any(zero).(DefaultsProvider[T]); ok {
+		return provider.Defaults(), true
+	}
+
+	if provider, ok := any(&zero).(DefaultsProvider[T]); ok {
+		return provider.Defaults(), true
+	}
+
+	return zero, false
+}
+
+// applyDefaults returns node merged over defaults. Raw map nodes are merged key by key, typed nodes
+// get their zero fields set from defaults.
+func applyDefaults[T any](node any, defaults T) (any, error) {
+	if raw, ok := node.(map[string]any); ok {
+		encoded, err := gojson.Marshal(defaults)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode configuration defaults", ConfigurationLookupErrorCode)
+		}
+
+		var base ConfigRawData
+		if err = gojson.Unmarshal(encoded, &base); err != nil {
+			return nil, errors.Wrap(err, "failed to encode configuration defaults", ConfigurationLookupErrorCode)
+		}
+
+		merged, err := Decode[T](mergeRawData(base, raw))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode configuration node over its defaults", ConfigurationLookupErrorCode)
+		}
+
+		return merged, nil
+	}
+
+	typed, ok := SafeTypeAssert[T](node)
+	if !ok {
+		return node, nil
+	}
+
+	filled := reflect.ValueOf(&typed).Elem()
+	fillZeroFields(filled, reflect.ValueOf(defaults))
+	return typed, nil
+}
+
+// fillZeroFields sets the zero fields of the settable struct value from defaults, descending into nested
+// structs and the pointers set on both sides. Pointees are filled in copies, the pointers shared with the
+// configuration tree and the node cache are swapped for them rather than written through.
+func fillZeroFields(value reflect.Value, defaults reflect.Value) {
+	if value.Kind() == reflect.Ptr && !value.IsNil() && !defaults.IsNil() {
+		if !value.CanSet() {
+			return
+		}
+
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(value.Elem())
+		fillZeroFields(copied.Elem(), defaults.Elem())
+		value.Set(copied)
+		return
+	}
+
+	if value.Kind() != reflect.Struct {
+		if value.IsZero() && value.CanSet() {
+			value.Set(defaults)
+		}
+		return
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		if !value.Type().Field(i).IsExported() {
+			continue
+		}
+
+		fillZeroFields(value.Field(i), defaults.Field(i))
+	}
+}
@@ -0,0 +1,96 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type defaultsTLSTest struct {
+	Enabled bool   `json:"enabled"`
+	Cert    string `json:"cert"`
+}
+
+func (c defaultsTLSTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+type defaultsHTTPTest struct {
+	Host string          `json:"host"`
+	Port int             `json:"port"`
+	TLS  defaultsTLSTest `json:"tls"`
+}
+
+func (c defaultsHTTPTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+func (defaultsHTTPTest) Defaults() defaultsHTTPTest {
+	return defaultsHTTPTest{Host: "0.0.0.0", Port: 8080, TLS: defaultsTLSTest{Cert: "/etc/tls/cert.pem"}}
+}
+
+type defaultsRootTest struct {
+	Raw   ConfigRawData    `json:"raw"`
+	Typed defaultsHTTPTest `json:"typed"`
+	Cache defaultsTLSTest  `json:"cache"`
+}
+
+func (c defaultsRootTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+type defaultsServerTest struct {
+	HTTP *defaultsHTTPTest `json:"http"`
+}
+
+func (c defaultsServerTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+func TestConfigurationDefaults(t *testing.T) {
+	ctx := NewContext(defaultsRootTest{
+		Raw:   ConfigRawData{"port": 9090, "tls": map[string]any{"enabled": true}},
+		Typed: defaultsHTTPTest{Host: "api"},
+	})
+	registry := NewTestRegistry(t)
+
+	t.Run("raw node", func(t *testing.T) {
+		cfg, err := ConfigurationLookup[defaultsHTTPTest](ctx, &RegistryOpts{Registry: registry, ConfigNodePath: "raw"})
+		require.NoError(t, err)
+		assert.Equal(t, defaultsHTTPTest{Host: "0.0.0.0", Port: 9090, TLS: defaultsTLSTest{Enabled: true, Cert: "/etc/tls/cert.pem"}}, cfg)
+	})
+
+	t.Run("typed node", func(t *testing.T) {
+		cfg, err := ConfigurationLookup[defaultsHTTPTest](ctx, &RegistryOpts{Registry: registry, ConfigNodePath: "typed"})
+		require.NoError(t, err)
+		assert.Equal(t, defaultsHTTPTest{Host: "api", Port: 8080, TLS: defaultsTLSTest{Cert: "/etc/tls/cert.pem"}}, cfg)
+	})
+
+	t.Run("absent node", func(t *testing.T) {
+		cfg, err := ConfigurationLookup[defaultsHTTPTest](ctx, &RegistryOpts{Registry: registry, ConfigNodePath: "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, defaultsHTTPTest{}.Defaults(), cfg)
+	})
+
+	t.Run("registered defaults", func(t *testing.T) {
+		require.NoError(t, RegisterConfiguration[defaultsTLSTest](func(ctx Context, opts *RegistryOpts) (defaultsTLSTest, error) {
+			return ConfigurationLookup[defaultsTLSTest](ctx, opts)
+		}, WithRegistry(registry), WithConfigNodePath("cache"), WithConfigDefaults(defaultsTLSTest{Enabled: true, Cert: "cache.pem"})))
+
+		cfg, err := CreateConfiguration[defaultsTLSTest](ctx, WithRegistry(registry), WithConfigNodePath("cache"))
+		require.NoError(t, err)
+		assert.Equal(t, defaultsTLSTest{Enabled: true, Cert: "cache.pem"}, cfg)
+	})
+
+	t.Run("shared pointers", func(t *testing.T) {
+		shared := &defaultsHTTPTest{Host: "api"}
+		ctx := NewContext(defaultsServerTest{HTTP: shared})
+		defaults := defaultsServerTest{HTTP: &defaultsHTTPTest{Port: 8080}}
+
+		cfg, err := ConfigurationLookup[defaultsServerTest](ctx, &RegistryOpts{Registry: registry, ConfigDefaults: defaults})
+		require.NoError(t, err)
+		assert.Equal(t, defaultsHTTPTest{Host: "api", Port: 8080}, *cfg.HTTP)
+		assert.Equal(t, defaultsHTTPTest{Host: "api"}, *shared, "the configuration tree is left untouched")
+	})
+}
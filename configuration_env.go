@@ -0,0 +1,78 @@
+package di
+
+import (
+	"os"
+	"strings"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// LoadConfigurationFromEnv maps the environment variables starting with prefix and an underscore
+// into a nested ConfigRawData, APP_DATABASE_URL becoming database.url for the prefix APP. Keys are
+// lower cased, a single underscore separates nodes and a double one stands for an underscore,
+// APP_CACHE_KEY__PREFIX becoming cache.key_prefix. Values that are valid JSON, like 30, true or
+// {"a": 1}, are decoded, the others are kept as strings.
+//
+// Merge it over a file-based configuration with MergeConfigurations, or WithEnvOverrides for a
+// FileConfiguration, so containers can override single values with env vars only.
+func LoadConfigurationFromEnv(prefix string) ConfigRawData {
+	raw := ConfigRawData{}
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	for _, env := range os.Environ() {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+
+		node := raw
+		parts := envPathParts(strings.TrimPrefix(name, prefix))
+		for i, part := range parts {
+			if i == len(parts)-1 {
+				node[part] = envValue(value)
+				break
+			}
+
+			child, isMap := node[part].(map[string]any)
+			if !isMap {
+				child = map[string]any{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+
+	return raw
+}
+
+// envPathParts splits the name of an environment variable into lower cased node names.
+func envPathParts(name string) []string {
+	parts := strings.Split(strings.ReplaceAll(strings.ToLower(name), "__", "\x00"), "_")
+	for i, part := range parts {
+		parts[i] = strings.ReplaceAll(part, "\x00", "_")
+	}
+
+	return parts
+}
+
+// envValue decodes value when it's valid JSON.
+func envValue(value string) any {
+	var decoded any
+	if gojson.Valid([]byte(value)) && gojson.Unmarshal([]byte(value), &decoded) == nil {
+		return decoded
+	}
+
+	return value
+}
+
+// MergeConfigurations returns base with the nodes of overrides merged over it, in order. Nested
+// maps are merged recursively, any other node of an override replaces the one of base.
+// Neither base nor the overrides are modified.
+func MergeConfigurations(base ConfigRawData, overrides ...ConfigRawData) ConfigRawData {
+	merged := mergeRawData(nil, base)
+	for _, override := range overrides {
+		merged = mergeRawData(merged, override)
+	}
+
+	return merged
+}
@@ -0,0 +1,47 @@
+package di
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigurationFromEnv(t *testing.T) {
+	t.Setenv("APP_DATABASE_PASSWORD", "from-env")
+	t.Setenv("APP_DATABASE_HOST", "db.internal")
+	t.Setenv("APP_CACHE_TTL", "30")
+	t.Setenv("APP_CACHE_KEY__PREFIX", "cache:")
+	t.Setenv("OTHER_CACHE_TTL", "60")
+
+	raw := LoadConfigurationFromEnv("APP")
+	assert.Equal(t, ConfigRawData{
+		"database": map[string]any{"password": "from-env", "host": "db.internal"},
+		"cache":    map[string]any{"ttl": float64(30), "key_prefix": "cache:"},
+	}, raw)
+
+	base := ConfigRawData{"database": map[string]any{"host": "localhost", "port": float64(5432)}}
+	merged := MergeConfigurations(base, raw)
+	assert.Equal(t, map[string]any{"host": "db.internal", "password": "from-env", "port": float64(5432)}, merged["database"])
+	assert.Equal(t, "localhost", base["database"].(map[string]any)["host"])
+
+	t.Run("file configuration overrides", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{
+			"database": {"host": "localhost", "password": "from-file"},
+			"cache": {"ttl": 10},
+			"service": {"database": ${di.database}}
+		}`), 0o600))
+
+		cfg, err := NewFileConfiguration[fileConfigTest](path, WithEnvOverrides("APP"))
+		require.NoError(t, err)
+		assert.Equal(t, "from-env", cfg.Current().Database.Password)
+		assert.Equal(t, 30, cfg.Current().Cache.TTL)
+
+		host, err := ExtractNodeFromJSONPath(cfg.current.Load().raw, "service.database.host")
+		require.NoError(t, err)
+		assert.Equal(t, "db.internal", host)
+	})
+}
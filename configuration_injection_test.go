@@ -207,3 +207,57 @@ func TestConfigurationInjectionWithPair(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, dbService.IsConnected)
 }
+
+type cacheNodeConfig struct {
+	Address string `json:"address"`
+}
+
+func (c cacheNodeConfig) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+type optionalSectionsConfig struct {
+	Cache   *cacheNodeConfig `json:"cache"`
+	Storage *cacheNodeConfig `json:"storage"`
+}
+
+func (o optionalSectionsConfig) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(o, lookupPath)
+}
+
+func TestHasConfigNode(t *testing.T) {
+	ctx := NewContext(optionalSectionsConfig{Cache: &cacheNodeConfig{Address: "localhost:6379"}})
+
+	assert.True(t, HasConfigNode(ctx, "cache"))
+	assert.True(t, HasConfigNode(ctx, "cache.address"))
+	assert.False(t, HasConfigNode(ctx, "storage"))
+	assert.False(t, HasConfigNode(ctx, "unknown"))
+	assert.False(t, HasConfigNode(NewContext(), "cache"))
+}
+
+func TestOptionalConfigNode(t *testing.T) {
+	registry := NewRegistry()
+	ctx := NewContext(optionalSectionsConfig{Cache: &cacheNodeConfig{Address: "localhost:6379"}})
+
+	require.NoError(t, RegisterConfiguration[*cacheNodeConfig](
+		ConfigurationLookup[*cacheNodeConfig],
+		WithRegistry(registry),
+		WithOptionalConfigNode(),
+	))
+
+	cfg, err := CreateConfiguration[*cacheNodeConfig](ctx, WithRegistry(registry), WithConfigNodePath("cache"))
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, "localhost:6379", cfg.Address)
+
+	otherRegistry := NewRegistry()
+	require.NoError(t, RegisterConfiguration[*cacheNodeConfig](
+		ConfigurationLookup[*cacheNodeConfig],
+		WithRegistry(otherRegistry),
+		WithOptionalConfigNode(),
+	))
+
+	cfg, err = CreateConfiguration[*cacheNodeConfig](ctx, WithRegistry(otherRegistry), WithConfigNodePath("storage"))
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+}
@@ -0,0 +1,84 @@
+package di
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// checkRequired fails listing every required field of the struct v points to, or nested in it,
+// still holding its zero value. Fields are required with a `di:"required"` tag or the required
+// option of their json tag, `json:"host,required"`. Nested structs are checked unless they're nil pointers.
+func checkRequired(v any) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	missing := missingRequired(value, "")
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return errors.New("missing required configuration keys: %s", strings.Join(missing, ", "), RequiredConfigurationErrorCode)
+}
+
+// missingRequired returns the paths of the required fields of value left zero, under the node at prefix.
+func missingRequired(value reflect.Value, prefix string) []string {
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var missing []string
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		required := field.Tag.Get("di") == "required" || slices.Contains(strings.Split(options, ","), "required")
+
+		// embedded structs without a json name hold promoted fields, at the level of value
+		path := prefix
+		if !field.Anonymous || len(name) > 0 {
+			if len(name) == 0 {
+				name = field.Name
+			}
+			path = joinNodePath(prefix, name)
+		}
+
+		if required && fieldValue.IsZero() {
+			missing = append(missing, path)
+			continue
+		}
+
+		for fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
+			fieldValue = fieldValue.Elem()
+		}
+
+		missing = append(missing, missingRequired(fieldValue, path)...)
+	}
+
+	return missing
+}
+
+// joinNodePath returns the path of the child node name of the node at path.
+func joinNodePath(path string, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+
+	return path + "." + name
+}
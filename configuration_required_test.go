@@ -0,0 +1,49 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type requiredCredentialsTest struct {
+	User     string `json:"user" di:"required"`
+	Password string `json:"password,required"`
+}
+
+type requiredConfigTest struct {
+	Host        string                   `json:"host" di:"required"`
+	Port        int                      `json:"port"`
+	Credentials requiredCredentialsTest  `json:"credentials"`
+	Replica     *requiredCredentialsTest `json:"replica"`
+}
+
+func (c requiredConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+func TestRequiredConfiguration(t *testing.T) {
+	_, err := Decode[requiredConfigTest](map[string]any{"port": 5432, "credentials": map[string]any{"user": "app"}})
+	_, isMissing := errors.Has(err, RequiredConfigurationErrorCode)
+	assert.True(t, isMissing)
+	assert.ErrorContains(t, err, "missing required configuration keys: host, credentials.password")
+
+	cfg, err := Decode[requiredConfigTest](map[string]any{"host": "db", "credentials": map[string]any{"user": "app", "password": "secret"}})
+	require.NoError(t, err)
+	assert.Equal(t, "db", cfg.Host)
+
+	t.Run("creation", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, RegisterConfiguration[requiredConfigTest](func(ctx Context, opts *RegistryOpts) (requiredConfigTest, error) {
+			return ConfigurationLookup[requiredConfigTest](ctx, opts)
+		}, WithRegistry(registry)))
+
+		ctx := NewContext(requiredConfigTest{Credentials: requiredCredentialsTest{User: "app"}, Replica: &requiredCredentialsTest{}})
+		_, err := CreateConfiguration[requiredConfigTest](ctx, WithRegistry(registry))
+		_, isMissing := errors.Has(err, RequiredConfigurationErrorCode)
+		assert.True(t, isMissing)
+		assert.ErrorContains(t, err, "host, credentials.password, replica.user, replica.password")
+	})
+}
@@ -1,14 +1,29 @@
 package di
 
 import (
+	goctx "context"
 	"fmt"
 	gojson "github.com/goccy/go-json"
 	"github.com/pixie-sh/errors-go"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
+var (
+	// diReferenceRegex matches both quoted and unquoted ${di.path.to.node} references,
+	// e.g. "session_cache": ${di.singleton} or "session_cache": "${di.singleton}"
+	diReferenceRegex = regexp.MustCompile(`["']?(\$\{di\.([^}]+)\})["']?`)
+	// unquotedDIReferenceRegex matches the ${di.xxx} references used as unquoted values
+	unquotedDIReferenceRegex = regexp.MustCompile(`:\s*(\$\{di\.[^}]+\})([,\s\}])`)
+	// diReferencePathRegex matches the ${di.xxx} references, capturing their path
+	diReferencePathRegex = regexp.MustCompile(`\$\{di\.([^}]+)\}`)
+
+	lookupNameReplacer = strings.NewReplacer("_", "", "-", "")
+	lookupPathReplacer = strings.NewReplacer("[", ".", "]", "")
+)
+
 func ConfigurationLookup[T any](ctx Context, opts *RegistryOpts) (T, error) {
 	var result T
 
@@ -25,25 +40,193 @@ func ConfigurationLookup[T any](ctx Context, opts *RegistryOpts) (T, error) {
 		return result, errors.Wrap(err, "assembleConfigurationLookupPath error", ConfigurationLookupErrorCode)
 	}
 
-	abstractNode, err := ctx.Configuration().LookupNode(lookupPath)
+	defaults, hasDefaults := configDefaultsFor[T](opts)
+	abstractNode, err := lookupNode(ctx, opts, lookupPath)
+	if (err != nil || abstractNode == nil) && hasDefaults {
+		abstractNode, err = defaults, nil
+	}
+
 	if err != nil || abstractNode == nil {
 		return result, errors.Wrap(err, "di.Context.Configuration().LookupNode() failed", ConfigurationLookupErrorCode)
 	}
 
-	typed, good := SafeTypeAssert[T](abstractNode)
+	opts.configPath = lookupPath
+
+	abstractNode, err = resolveContextReferences(ctx, abstractNode)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to resolve context references of %s", lookupPath, ConfigurationLookupErrorCode)
+	}
+
+	if hasDefaults {
+		abstractNode, err = applyDefaults(abstractNode, defaults)
+		if err != nil {
+			return result, errors.Wrap(err, "failed to apply the defaults of %s", lookupPath, ConfigurationLookupErrorCode)
+		}
+	}
+
+	overlay, overlaid := tokenConfigFor(ctx, opts)
+	if overlaid {
+		return overlayNode[T](abstractNode, overlay)
+	}
+
+	typed, good := typeAssertFor[T](abstractNode, opts)
 	if !good {
 		return result, errors.New("di.Context.Configuration().LookupNode() returned an invalid type", ConfigurationLookupErrorCode)
 	}
 
+	if err = checkRequired(typed); err != nil {
+		return result, errors.Wrap(err, "configuration node %s is incomplete", lookupPath, RequiredConfigurationErrorCode)
+	}
+
 	return typed, nil
 }
 
-func ConfigurationNodeLookup(c any, path string) (any, error) {
+// LookupNodeAs returns the node of cfg at path as T, asserted when the node already is a T, or decoded
+// when it's a raw map, e.g. a ConfigRawData node decoded into the struct describing it.
+func LookupNodeAs[T any](cfg Configuration, path string) (T, error) {
+	var result T
+
+	if cfg == nil {
+		return result, errors.New("configuration cannot be nil", ConfigurationLookupErrorCode)
+	}
+
+	node, err := cfg.LookupNode(path)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to lookup configuration node %s", path, ConfigurationLookupErrorCode)
+	}
+
+	if typed, good := SafeTypeAssert[T](node); good {
+		if err = checkRequired(typed); err != nil {
+			return result, errors.Wrap(err, "configuration node %s is incomplete", path, RequiredConfigurationErrorCode)
+		}
+
+		return typed, nil
+	}
+
+	if _, raw := node.(map[string]any); !raw {
+		return result, errors.New("configuration node %s is a %T, not a %s", path, node, TypeName[T](), ConfigurationLookupErrorCode)
+	}
+
+	result, err = Decode[T](node)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to decode configuration node %s", path, ConfigurationLookupErrorCode)
+	}
+
+	return result, nil
+}
+
+// tokenConfigFor returns the context overlay of the token the lookup resolves for,
+// the options injection token or else the innermost breadcrumb.
+func tokenConfigFor(ctx Context, opts *RegistryOpts) (any, bool) {
+	token := opts.InjectionToken
+	if len(token) == 0 {
+		breadcrumbs := ctx.Breadcrumbs()
+		if len(breadcrumbs) == 0 {
+			return nil, false
+		}
+
+		token = InjectionToken(breadcrumbs[len(breadcrumbs)-1])
+	}
+
+	return ctx.TokenConfig(token)
+}
+
+// overlayNode merges overlay on top of node and decodes the result into T.
+func overlayNode[T any](node any, overlay any) (T, error) {
+	var result T
+
+	base, err := Decode[ConfigRawData](node)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to decode configuration node for overlay", ConfigurationLookupErrorCode)
+	}
+
+	top, err := Decode[ConfigRawData](overlay)
+	if err != nil {
+		return result, errors.Wrap(err, "failed to decode configuration overlay", ConfigurationLookupErrorCode)
+	}
+
+	result, err = Decode[T](mergeRawData(base, top))
+	if err != nil {
+		return result, errors.Wrap(err, "failed to decode overlaid configuration node", ConfigurationLookupErrorCode)
+	}
+
+	return result, nil
+}
+
+// mergeRawData returns base with top merged on it, recursing into nested maps.
+// Neither input is modified.
+func mergeRawData(base, top ConfigRawData) ConfigRawData {
+	merged := make(ConfigRawData, len(base)+len(top))
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range top {
+		baseChild, baseIsMap := merged[key].(map[string]any)
+		topChild, topIsMap := value.(map[string]any)
+		if baseIsMap && topIsMap {
+			merged[key] = mergeRawData(baseChild, topChild)
+			continue
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// HasConfigNode reports whether the context configuration has a non nil node at path.
+// It allows providers to branch on optional configuration sections without parsing
+// the error chain returned by CreateConfiguration.
+func HasConfigNode(ctx Context, path string) bool {
+	if ctx == nil || ctx.Configuration() == nil {
+		return false
+	}
+
+	node, err := ctx.Configuration().LookupNode(path)
+	if err != nil || node == nil {
+		return false
+	}
+
+	value := reflect.ValueOf(node)
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return !value.IsNil()
+	default:
+	}
+
+	return true
+}
+
+// LookupOption configures ConfigurationNodeLookup.
+type LookupOption func(o *lookupOptions)
+
+type lookupOptions struct {
+	caseInsensitive bool
+}
+
+// WithCaseInsensitiveLookup matches the path parts against the field names, json tag names and map keys
+// ignoring case, underscores and dashes once no exact match is found, so max_idle_conns matches a
+// MaxIdleConns field.
+func WithCaseInsensitiveLookup() LookupOption {
+	return func(o *lookupOptions) {
+		o.caseInsensitive = true
+	}
+}
+
+// ConfigurationNodeLookup returns the node of c at the dot separated path, following struct fields by name
+// or json tag, maps with string keys by key and slices by index, e.g. "servers.0.host" or "servers[0].host".
+func ConfigurationNodeLookup(c any, path string, options ...LookupOption) (any, error) {
 	if path == "" {
 		return c, nil
 	}
 
-	parts := strings.Split(path, ".")
+	var opts lookupOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	parts := splitLookupPath(path)
 	current := reflect.ValueOf(c)
 
 	for _, part := range parts {
@@ -55,29 +238,39 @@ func ConfigurationNodeLookup(c any, path string) (any, error) {
 			current = current.Elem()
 		}
 
+		// Maps with string keys are traversed by key, e.g. caches.primary of a map[string]RedisConfig
+		if current.Kind() == reflect.Map {
+			value, err := mapNodeLookup(current, part, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			current = value
+			continue
+		}
+
+		// Slices and arrays are traversed by index, e.g. servers.0.host or servers[0].host
+		if current.Kind() == reflect.Slice || current.Kind() == reflect.Array {
+			index, err := lookupPathIndex(part, current.Len())
+			if err != nil {
+				return nil, err
+			}
+
+			current = current.Index(index)
+			if current.Kind() == reflect.Interface && !current.IsNil() {
+				current = current.Elem()
+			}
+			continue
+		}
+
 		// Only struct types can have fields
 		if current.Kind() != reflect.Struct {
 			return nil, errors.New("cannot access field '" + part + "' on non-struct type")
 		}
 
-		// Get the field by name
-		field := current.FieldByName(part)
-		if !field.IsValid() {
-			// Try to find a JSON tag that matches the part
-			foundField := false
-			t := current.Type()
-			for i := 0; i < t.NumField(); i++ {
-				structField := t.Field(i)
-				jsonTag := structField.Tag.Get("json")
-				if jsonTag == part || strings.Split(jsonTag, ",")[0] == part {
-					field = current.Field(i)
-					foundField = true
-					break
-				}
-			}
-			if !foundField {
-				return nil, errors.New("field '" + part + "' not found")
-			}
+		field, found := structFieldLookup(current, part, opts)
+		if !found {
+			return nil, errors.New("field '" + part + "' not found")
 		}
 
 		current = field
@@ -87,6 +280,103 @@ func ConfigurationNodeLookup(c any, path string) (any, error) {
 	return current.Interface(), nil
 }
 
+// structFieldLookup returns the field of the struct s named part, or with part as json tag name.
+func structFieldLookup(s reflect.Value, part string, opts lookupOptions) (reflect.Value, bool) {
+	// Get the field by name
+	if field := s.FieldByName(part); field.IsValid() {
+		return field, true
+	}
+
+	// Try to find a JSON tag that matches the part
+	t := s.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := jsonTagName(t.Field(i)); ok && name == part {
+			return s.Field(i), true
+		}
+	}
+
+	if !opts.caseInsensitive {
+		return reflect.Value{}, false
+	}
+
+	normalized := normalizeLookupName(part)
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := jsonTagName(t.Field(i))
+		if (ok && normalizeLookupName(name) == normalized) || normalizeLookupName(t.Field(i).Name) == normalized {
+			return s.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// jsonTagName returns the name given to the field by its json tag, if any. A "-" tag hides the field
+// from JSON and gives no name, while "-," names it "-".
+func jsonTagName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	return name, len(name) > 0
+}
+
+// normalizeLookupName returns name lower cased without underscores and dashes, for case insensitive lookups.
+func normalizeLookupName(name string) string {
+	return strings.ToLower(lookupNameReplacer.Replace(name))
+}
+
+// splitLookupPath splits a dot separated lookup path into its parts, the bracketed indexes being
+// parts of their own: servers[0].host splits as servers.0.host.
+func splitLookupPath(path string) []string {
+	path = lookupPathReplacer.Replace(path)
+	return strings.Split(path, ".")
+}
+
+// lookupPathIndex returns the index of a list of length size addressed by the path part.
+func lookupPathIndex(part string, size int) (int, error) {
+	index, err := strconv.Atoi(part)
+	if err != nil {
+		return 0, errors.New("cannot access field '" + part + "' on list, expected an index")
+	}
+
+	if index < 0 || index >= size {
+		return 0, errors.New("index " + part + " out of range of list of length " + strconv.Itoa(size))
+	}
+
+	return index, nil
+}
+
+// mapNodeLookup returns the value of the map m at key, unwrapping the interface values of untyped maps.
+func mapNodeLookup(m reflect.Value, key string, opts lookupOptions) (reflect.Value, error) {
+	if m.Type().Key().Kind() != reflect.String {
+		return reflect.Value{}, errors.New("cannot access key '" + key + "' on map without string keys")
+	}
+
+	value := m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key()))
+	if !value.IsValid() && opts.caseInsensitive {
+		normalized := normalizeLookupName(key)
+		iter := m.MapRange()
+		for iter.Next() {
+			if normalizeLookupName(iter.Key().String()) == normalized {
+				value = iter.Value()
+				break
+			}
+		}
+	}
+
+	if !value.IsValid() {
+		return reflect.Value{}, errors.New("key '" + key + "' not found")
+	}
+
+	if value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	return value, nil
+}
+
 func assembleConfigurationLookupPath(ctx Context, opts *RegistryOpts) (string, error) {
 	lp := opts.ConfigNodePath
 	if len(lp) > 0 {
@@ -99,10 +389,26 @@ func assembleConfigurationLookupPath(ctx Context, opts *RegistryOpts) (string, e
 // ResolveDIReferences processes a JSON string and replaces "${di.XXXXX}" references
 // with the actual JSON nodes they point to. This function can be used independently
 // of any specific struct type.
+//
+// A reference may carry a fallback, "${di.XXXXX:-fallback}", used when the node is missing.
+// The fallback is used as is when it's valid JSON, e.g. 30, true or null, otherwise as a string;
+// an empty fallback resolves to null.
+//
+// The "${secret.name}" references in string values are replaced first with the secrets of the
+// SecretProvider, see SetSecretProvider, and the "${enc:ciphertext}" values with their plaintext,
+// see SetDecryptor.
 func ResolveDIReferences(jsonStr string) (string, error) {
-	// Regular expression to match both quoted and unquoted ${di.path.to.node} patterns
-	// This will match: "session_cache": ${di.singleton} or "session_cache": "${di.singleton}"
-	re := regexp.MustCompile(`["']?(\$\{di\.([^}]+)\})["']?`)
+	jsonStr, err := resolveSecretReferences(goctx.Background(), jsonStr)
+	if err != nil {
+		return "", err
+	}
+
+	jsonStr, err = resolveEncryptedReferences(goctx.Background(), jsonStr)
+	if err != nil {
+		return "", err
+	}
+
+	re := diReferenceRegex
 
 	// First, we need to make the JSON valid by quoting unquoted DI references
 	validJSON := makeJSONValid(jsonStr)
@@ -123,8 +429,10 @@ func ResolveDIReferences(jsonStr string) (string, error) {
 			continue
 		}
 
-		fullMatch := match[1] // ${di.singleton}
-		diPath := match[2]    // singleton
+		fullMatch := match[1] // ${di.singleton} or ${di.singleton:-fallback}
+
+		// singleton, with the fallback literal used when the node is missing, if any
+		diPath, fallback, hasFallback := strings.Cut(match[2], ":-")
 
 		// Skip if we already processed this reference
 		if _, exists := replacements[fullMatch]; exists {
@@ -133,6 +441,11 @@ func ResolveDIReferences(jsonStr string) (string, error) {
 
 		// Extract the referenced node from the raw data
 		referencedNode, err := ExtractNodeFromJSONPath(rawData, diPath)
+		if err != nil && hasFallback {
+			replacements[fullMatch] = fallbackJSON(fallback)
+			continue
+		}
+
 		if err != nil {
 			return "", fmt.Errorf("failed to resolve DI reference %s: %w", fullMatch, err)
 		}
@@ -157,45 +470,56 @@ func ResolveDIReferences(jsonStr string) (string, error) {
 	return result, nil
 }
 
+// fallbackJSON returns the JSON of the fallback literal of a DI reference.
+func fallbackJSON(fallback string) string {
+	if len(fallback) == 0 {
+		return "null"
+	}
+
+	if gojson.Valid([]byte(fallback)) {
+		return fallback
+	}
+
+	encoded, _ := gojson.Marshal(fallback)
+	return string(encoded)
+}
+
 // makeJSONValid converts unquoted DI references to quoted strings to make valid JSON
 func makeJSONValid(jsonStr string) string {
-	// Regular expression to find unquoted ${di.xxx} patterns
-	re := regexp.MustCompile(`:\s*(\$\{di\.[^}]+\})([,\s\}])`)
-
 	// Replace unquoted DI references with quoted versions
-	result := re.ReplaceAllString(jsonStr, `: "$1"$2`)
+	result := unquotedDIReferenceRegex.ReplaceAllString(jsonStr, `: "$1"$2`)
 
 	return result
 }
 
 // ExtractNodeFromJSONPath navigates through a map[string]interface{} structure
-// to find the node at the given dot-separated path.
+// to find the node at the given dot-separated path. Lists are navigated by index,
+// e.g. "servers.0.host" or "servers[0].host".
 func ExtractNodeFromJSONPath(data map[string]interface{}, path string) (interface{}, error) {
 	if path == "" {
 		return data, nil
 	}
 
-	parts := strings.Split(path, ".")
-	current := data
-
-	for i, part := range parts {
-		value, exists := current[part]
-		if !exists {
-			return nil, fmt.Errorf("path component '%s' not found in path '%s'", part, path)
-		}
+	var current interface{} = data
+	for _, part := range splitLookupPath(path) {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, exists := node[part]
+			if !exists {
+				return nil, fmt.Errorf("path component '%s' not found in path '%s'", part, path)
+			}
 
-		// If this is the last part, return the value
-		if i == len(parts)-1 {
-			return value, nil
-		}
+			current = value
+		case []interface{}:
+			index, err := lookupPathIndex(part, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("path component '%s' is not a valid index in path '%s': %w", part, path, err)
+			}
 
-		// Otherwise, ensure the value is a map for the next iteration
-		nextMap, ok := value.(map[string]interface{})
-		if !ok {
+			current = node[index]
+		default:
 			return nil, fmt.Errorf("path component '%s' is not an object, cannot navigate further in path '%s'", part, path)
 		}
-
-		current = nextMap
 	}
 
 	return current, nil
@@ -222,7 +546,7 @@ func UnmarshalJSONWithDIResolution(data []byte, dest interface{}) error {
 // FindDIReferences scans a JSON string and returns all DI references found.
 // This can be useful for validation or preprocessing.
 func FindDIReferences(jsonStr string) []string {
-	re := regexp.MustCompile(`\$\{di\.([^}]+)\}`)
+	re := diReferencePathRegex
 	matches := re.FindAllString(jsonStr, -1)
 
 	// Remove duplicates
@@ -241,7 +565,7 @@ func FindDIReferences(jsonStr string) []string {
 // ValidateDIReferences checks if all DI references in a JSON string can be resolved
 // against the provided data structure. Returns an error if any reference is invalid.
 func ValidateDIReferences(jsonStr string, data map[string]interface{}) error {
-	re := regexp.MustCompile(`\$\{di\.([^}]+)\}`)
+	re := diReferencePathRegex
 	matches := re.FindAllStringSubmatch(jsonStr, -1)
 
 	for _, match := range matches {
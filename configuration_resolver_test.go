@@ -0,0 +1,216 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDIReferencesFallback(t *testing.T) {
+	var resolved struct {
+		Cache struct {
+			TTL int `json:"ttl"`
+		} `json:"cache"`
+		Service struct {
+			TTL     int               `json:"ttl"`
+			Driver  string            `json:"driver"`
+			Backend map[string]string `json:"backend"`
+			Enabled bool              `json:"enabled"`
+			Region  string            `json:"region"`
+		} `json:"service"`
+	}
+
+	require.NoError(t, UnmarshalJSONWithDIResolution([]byte(`{
+		"cache": {"ttl": 10},
+		"service": {
+			"ttl": "${di.cache.ttl:-30}",
+			"driver": "${di.cache.driver:-redis}",
+			"backend": "${di.shared.backend:-}",
+			"enabled": ${di.features.enabled:-true},
+			"region": "${di.region:-eu-west-1}"
+		}
+	}`), &resolved))
+
+	assert.Equal(t, 10, resolved.Service.TTL)
+	assert.Equal(t, "redis", resolved.Service.Driver)
+	assert.Nil(t, resolved.Service.Backend)
+	assert.True(t, resolved.Service.Enabled)
+	assert.Equal(t, "eu-west-1", resolved.Service.Region)
+
+	_, err := ResolveDIReferences(`{"service": {"ttl": "${di.cache.ttl}"}}`)
+	assert.ErrorContains(t, err, "failed to resolve DI reference ${di.cache.ttl}")
+}
+
+type redisTestConfig struct {
+	Addr string `json:"addr"`
+	DB   int    `json:"db"`
+}
+
+func TestConfigurationNodeLookupMaps(t *testing.T) {
+	secondary := map[string]redisTestConfig{"secondary": {Addr: "replica:6379"}}
+	cfg := struct {
+		Caches   map[string]redisTestConfig  `json:"caches"`
+		Replicas *map[string]redisTestConfig `json:"replicas"`
+		Pools    map[string]*redisTestConfig `json:"pools"`
+		Raw      map[string]any              `json:"raw"`
+		Ports    map[int]string              `json:"ports"`
+	}{
+		Caches:   map[string]redisTestConfig{"primary": {Addr: "redis:6379", DB: 1}},
+		Replicas: &secondary,
+		Pools:    map[string]*redisTestConfig{"sessions": {Addr: "sessions:6379"}},
+		Raw:      map[string]any{"limits": map[string]any{"rps": 100}},
+		Ports:    map[int]string{80: "http"},
+	}
+
+	node, err := ConfigurationNodeLookup(cfg, "caches.primary")
+	require.NoError(t, err)
+	assert.Equal(t, redisTestConfig{Addr: "redis:6379", DB: 1}, node)
+
+	node, err = ConfigurationNodeLookup(&cfg, "caches.primary.db")
+	require.NoError(t, err)
+	assert.Equal(t, 1, node)
+
+	node, err = ConfigurationNodeLookup(cfg, "replicas.secondary.addr")
+	require.NoError(t, err)
+	assert.Equal(t, "replica:6379", node)
+
+	node, err = ConfigurationNodeLookup(cfg, "pools.sessions.addr")
+	require.NoError(t, err)
+	assert.Equal(t, "sessions:6379", node)
+
+	node, err = ConfigurationNodeLookup(cfg, "raw.limits.rps")
+	require.NoError(t, err)
+	assert.Equal(t, 100, node)
+
+	_, err = ConfigurationNodeLookup(cfg, "caches.missing")
+	assert.ErrorContains(t, err, "key 'missing' not found")
+
+	_, err = ConfigurationNodeLookup(cfg, "ports.80")
+	assert.ErrorContains(t, err, "map without string keys")
+}
+
+func TestConfigurationNodeLookupSlices(t *testing.T) {
+	type server struct {
+		Host string `json:"host"`
+	}
+
+	cfg := struct {
+		Servers []server  `json:"servers"`
+		Shards  [2]string `json:"shards"`
+		Raw     []any     `json:"raw"`
+	}{
+		Servers: []server{{Host: "a"}, {Host: "b"}},
+		Shards:  [2]string{"s0", "s1"},
+		Raw:     []any{map[string]any{"host": "c"}},
+	}
+
+	for path, expected := range map[string]any{
+		"servers.1.host":  "b",
+		"servers[0].host": "a",
+		"servers[1]":      server{Host: "b"},
+		"shards.1":        "s1",
+		"raw[0].host":     "c",
+	} {
+		node, err := ConfigurationNodeLookup(cfg, path)
+		require.NoError(t, err, path)
+		assert.Equal(t, expected, node, path)
+	}
+
+	_, err := ConfigurationNodeLookup(cfg, "servers.2.host")
+	assert.ErrorContains(t, err, "index 2 out of range of list of length 2")
+
+	_, err = ConfigurationNodeLookup(cfg, "servers.first")
+	assert.ErrorContains(t, err, "expected an index")
+}
+
+func TestExtractNodeFromJSONPathSlices(t *testing.T) {
+	var resolved struct {
+		Primary struct {
+			Host string `json:"host"`
+		} `json:"primary"`
+		Broker string `json:"broker"`
+	}
+
+	require.NoError(t, UnmarshalJSONWithDIResolution([]byte(`{
+		"servers": [{"host": "a"}, {"host": "b"}],
+		"brokers": ["kafka-0", "kafka-1"],
+		"primary": "${di.servers[1]}",
+		"broker": "${di.brokers.0}"
+	}`), &resolved))
+
+	assert.Equal(t, "b", resolved.Primary.Host)
+	assert.Equal(t, "kafka-0", resolved.Broker)
+
+	_, err := ExtractNodeFromJSONPath(map[string]any{"brokers": []any{"kafka-0"}}, "brokers.3")
+	assert.ErrorContains(t, err, "path component '3' is not a valid index in path 'brokers.3'")
+}
+
+func TestConfigurationNodeLookupCaseInsensitive(t *testing.T) {
+	cfg := struct {
+		MaxIdleConns int               `json:"maxIdle"`
+		Hidden       string            `json:"-"`
+		Dash         string            `json:"-,"`
+		Pools        map[string]string `json:"pools"`
+	}{MaxIdleConns: 10, Hidden: "hidden", Dash: "dash", Pools: map[string]string{"Primary_Pool": "p"}}
+
+	_, err := ConfigurationNodeLookup(cfg, "max_idle_conns")
+	assert.ErrorContains(t, err, "field 'max_idle_conns' not found")
+
+	node, err := ConfigurationNodeLookup(cfg, "-")
+	require.NoError(t, err)
+	assert.Equal(t, "dash", node)
+
+	for path, expected := range map[string]any{
+		"max_idle_conns":    10,
+		"MAXIDLE":           10,
+		"max-idle":          10,
+		"pools.primarypool": "p",
+		"hidden":            "hidden",
+	} {
+		node, err = ConfigurationNodeLookup(cfg, path, WithCaseInsensitiveLookup())
+		require.NoError(t, err, path)
+		assert.Equal(t, expected, node, path)
+	}
+}
+
+type lookupAsConfig struct {
+	Cache redisTestConfig `json:"cache"`
+	Raw   ConfigRawData   `json:"raw"`
+}
+
+func (c lookupAsConfig) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+func TestLookupNodeAs(t *testing.T) {
+	cfg := lookupAsConfig{
+		Cache: redisTestConfig{Addr: "redis:6379", DB: 2},
+		Raw:   ConfigRawData{"replica": map[string]any{"addr": "replica:6379", "db": 3}},
+	}
+
+	cache, err := LookupNodeAs[redisTestConfig](cfg, "cache")
+	require.NoError(t, err)
+	assert.Equal(t, redisTestConfig{Addr: "redis:6379", DB: 2}, cache)
+
+	pointer, err := LookupNodeAs[*redisTestConfig](cfg, "cache")
+	require.NoError(t, err)
+	assert.Equal(t, "redis:6379", pointer.Addr)
+
+	replica, err := LookupNodeAs[redisTestConfig](cfg, "raw.replica")
+	require.NoError(t, err)
+	assert.Equal(t, redisTestConfig{Addr: "replica:6379", DB: 3}, replica)
+
+	db, err := LookupNodeAs[int](cfg, "cache.db")
+	require.NoError(t, err)
+	assert.Equal(t, 2, db)
+
+	_, err = LookupNodeAs[int](cfg, "cache.addr")
+	assert.ErrorContains(t, err, "configuration node cache.addr is a string, not a int")
+
+	_, err = LookupNodeAs[int](cfg, "cache.missing")
+	assert.ErrorContains(t, err, "failed to lookup configuration node cache.missing")
+
+	_, err = LookupNodeAs[int](nil, "cache")
+	assert.Error(t, err)
+}
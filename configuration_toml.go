@@ -0,0 +1,51 @@
+package di
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+	gojson "github.com/goccy/go-json"
+)
+
+// UnmarshalTOMLWithDIResolution is the TOML counterpart of UnmarshalJSONWithDIResolution.
+// The document is converted to JSON before its ${di.path} references are resolved, so string
+// values referencing other nodes behave as in JSON, and dest is decoded by its json tags:
+//
+//	[database]
+//	host = "db.internal"
+//
+//	[service]
+//	database = "${di.database}"
+func UnmarshalTOMLWithDIResolution(data []byte, dest interface{}) error {
+	jsonData, err := tomlToJSON(data)
+	if err != nil {
+		return err
+	}
+
+	return UnmarshalJSONWithDIResolution(jsonData, dest)
+}
+
+// ParseTOMLConfiguration parses a TOML document into ConfigRawData, resolving its ${di.path} references.
+func ParseTOMLConfiguration(data []byte) (ConfigRawData, error) {
+	raw := ConfigRawData{}
+	if err := UnmarshalTOMLWithDIResolution(data, &raw); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// tomlToJSON converts a TOML document to JSON.
+func tomlToJSON(data []byte) ([]byte, error) {
+	var document map[string]interface{}
+	if err := toml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal TOML: %w", err)
+	}
+
+	jsonData, err := gojson.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert TOML to JSON: %w", err)
+	}
+
+	return jsonData, nil
+}
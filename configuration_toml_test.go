@@ -0,0 +1,51 @@
+package di
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tomlConfigTest = `
+[database]
+host = "db.internal"
+password = "secret"
+
+[cache]
+ttl = 10
+
+[service]
+database = "${di.database}"
+`
+
+func TestTOMLConfiguration(t *testing.T) {
+	raw, err := ParseTOMLConfiguration([]byte(tomlConfigTest))
+	require.NoError(t, err)
+
+	node, err := ExtractNodeFromJSONPath(raw, "service.database.host")
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", node)
+
+	var cfg fileConfigTest
+	require.NoError(t, UnmarshalTOMLWithDIResolution([]byte(tomlConfigTest), &cfg))
+	assert.Equal(t, "secret", cfg.Database.Password)
+	assert.Equal(t, 10, cfg.Cache.TTL)
+
+	_, err = ParseTOMLConfiguration([]byte("[database"))
+	assert.Error(t, err)
+
+	t.Run("file configuration", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		require.NoError(t, os.WriteFile(path, []byte(tomlConfigTest), 0o600))
+
+		fileCfg, err := NewFileConfiguration[fileConfigTest](path)
+		require.NoError(t, err)
+
+		password, err := ConfigurationLookup[string](NewContext(fileCfg), &RegistryOpts{Registry: NewTestRegistry(t), ConfigNodePath: "database.password"})
+		require.NoError(t, err)
+		assert.Equal(t, "secret", password)
+	})
+}
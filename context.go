@@ -2,7 +2,10 @@ package di
 
 import (
 	goctx "context"
+	"maps"
 	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pixie-sh/errors-go"
@@ -20,11 +23,19 @@ type Context interface {
 
 	//RawConfiguration do not use this unless you are fully aware of the implications
 	//this map may be null if the context was created without a configuration or scoped configuration
+	//it's decoded from Configuration on first access, panicking if the configuration cannot be decoded
 	RawConfiguration() ConfigRawData
 	Configuration() Configuration
 
 	Inner() goctx.Context
 	Clone() Context
+	// WithInner returns a clone of the context wrapping inner instead, keeping the injection state
+	WithInner(inner goctx.Context) Context
+	// WithValue, WithCancel and WithDeadline wrap the inner context like their context package
+	// counterparts, returning a Context keeping the configuration, breadcrumbs and injection state
+	WithValue(key, val any) Context
+	WithCancel() (Context, goctx.CancelFunc)
+	WithDeadline(deadline time.Time) (Context, goctx.CancelFunc)
 
 	Breadcrumbs() []string
 	AppendBreadcrumb(token InjectionToken)
@@ -33,6 +44,30 @@ type Context interface {
 	ScopedConfiguration(node Configuration)
 	IsScoped() bool
 	ClearScoped()
+
+	// PushScope returns a copy of the context re-rooting its configuration at node, so the creations
+	// made with it look their configuration up relative to node, from fresh breadcrumbs. PopScope returns
+	// a copy back in the scope enclosing the innermost pushed one, false when none was pushed.
+	PushScope(node Configuration) Context
+	PopScope() (Context, bool)
+
+	// WithTokenConfig returns a copy of the context carrying overlay for token. When a configuration
+	// is looked up for that token, the overlay is merged on top of the node found in the configuration.
+	// Overlays are usually ConfigRawData, struct overlays override every field not tagged omitempty.
+	WithTokenConfig(token InjectionToken, overlay any) Context
+	TokenConfig(token InjectionToken) (any, bool)
+
+	// CreateInfo describes the creation the context is handed to, see CreateInfo
+	CreateInfo() CreateInfo
+}
+
+// CreateInfo describes a creation to its provider: the type created, the type depending on it,
+// how deep it is in the resolution tree and whether it's created by RegistryLifecycle.WarmUp or lazily on use.
+type CreateInfo struct {
+	TypeName string // registration name of the type created
+	Parent   string // registration name of the type depending on it, empty for the root of the resolution
+	Depth    int    // 0 for the root of the resolution, incremented for each nested creation
+	WarmUp   bool   // created by RegistryLifecycle.WarmUp rather than on first use
 }
 
 // context implements the Context interface and wraps the standard context
@@ -41,9 +76,62 @@ type context struct {
 	ctx goctx.Context
 
 	rawCfg                   ConfigRawData
+	lazyRawCfg               *lazyRawConfiguration
 	cfg                      Configuration
 	injectionTokenBreadcrumb []string
 	isScoped                 bool
+
+	// rootRegistry is the registry the resolution tree was started with
+	rootRegistry Registry
+	// resolving is the name of the type being created, the dependent of nested creations
+	resolving string
+	// parent is the name of the type depending on resolving, depth its distance to the root
+	parent string
+	depth  int
+	// chain links the names of the types being created from resolving up to the root, see chainLink
+	chain *chainLink
+	link  chainLink
+	// warmUp marks the resolutions started by RegistryLifecycle.WarmUp
+	warmUp bool
+	// creating holds the hot instance keys whose factory runs up the resolution, see lockHotInstance
+	creating []string
+	// tokenConfigs holds the configuration overlays per injection token, copied on write
+	tokenConfigs map[InjectionToken]any
+	// timeout tracks the factories executing under the deadline of WithCreateTimeout, if any
+	timeout *createTimeout
+	// scopes holds the scopes enclosing the ones pushed by PushScope, innermost last, copied on write
+	scopes []contextScope
+	// memo holds the instances created in the resolution tree, see WithResolutionMemo
+	memo *resolutionMemo
+	// overrides holds the instances substituted to the registrations, see WithOverride, copied on write
+	overrides map[overrideKey]any
+}
+
+// contextScope is the configuration scope of a context, saved by PushScope for PopScope.
+type contextScope struct {
+	rawCfg      ConfigRawData
+	lazyRawCfg  *lazyRawConfiguration
+	cfg         Configuration
+	breadcrumbs []string
+	isScoped    bool
+}
+
+// chainLink is a node of the chain of types being created. Each injection context embeds the link
+// of the type it creates, pointing to the one of its parent, so tracking the chain doesn't allocate.
+type chainLink struct {
+	name string
+	prev *chainLink
+}
+
+// names lists the chain from the root to l.
+func (l *chainLink) names() []string {
+	var names []string
+	for link := l; link != nil; link = link.prev {
+		names = append(names, link.name)
+	}
+
+	slices.Reverse(names)
+	return names
 }
 
 func (s *context) ClearScoped() {
@@ -61,6 +149,7 @@ func (s *context) IsScoped() bool {
 func (s *context) ScopedConfiguration(node Configuration) {
 	s.cfg = node
 	s.rawCfg = nil
+	s.lazyRawCfg = nil
 	s.isScoped = true
 }
 
@@ -74,6 +163,10 @@ func (s *context) AppendBreadcrumb(token InjectionToken) {
 }
 
 func (s *context) RawConfiguration() ConfigRawData {
+	if s.lazyRawCfg != nil {
+		return s.lazyRawCfg.get()
+	}
+
 	return s.rawCfg
 }
 func (s *context) Configuration() Configuration {
@@ -108,25 +201,120 @@ func (s *context) Clone() Context {
 	return &context{
 		s.ctx,
 		s.rawCfg,
+		s.lazyRawCfg,
 		s.cfg,
-		slices.Clone(s.injectionTokenBreadcrumb),
+		s.injectionTokenBreadcrumb[:len(s.injectionTokenBreadcrumb):len(s.injectionTokenBreadcrumb)],
 		false,
+		s.rootRegistry,
+		s.resolving,
+		s.parent,
+		s.depth,
+		s.chain,
+		chainLink{},
+		s.warmUp,
+		s.creating,
+		s.tokenConfigs,
+		s.timeout,
+		s.scopes,
+		s.memo,
+		s.overrides,
+	}
+}
+
+func (s *context) WithInner(inner goctx.Context) Context {
+	clone := s.Clone().(*context)
+	clone.isScoped = s.isScoped
+	clone.ctx = inner
+	return clone
+}
+
+func (s *context) WithValue(key, val any) Context {
+	return s.WithInner(goctx.WithValue(s.ctx, key, val))
+}
+
+func (s *context) WithCancel() (Context, goctx.CancelFunc) {
+	inner, cancel := goctx.WithCancel(s.ctx)
+	return s.WithInner(inner), cancel
+}
+
+func (s *context) WithDeadline(deadline time.Time) (Context, goctx.CancelFunc) {
+	inner, cancel := goctx.WithDeadline(s.ctx, deadline)
+	return s.WithInner(inner), cancel
+}
+
+func (s *context) WithTokenConfig(token InjectionToken, overlay any) Context {
+	clone := s.Clone().(*context)
+	clone.isScoped = s.isScoped
+	clone.tokenConfigs = maps.Clone(s.tokenConfigs)
+	if clone.tokenConfigs == nil {
+		clone.tokenConfigs = map[InjectionToken]any{}
 	}
+
+	clone.tokenConfigs[token] = overlay
+	return clone
+}
+
+func (s *context) TokenConfig(token InjectionToken) (any, bool) {
+	overlay, ok := s.tokenConfigs[token]
+	return overlay, ok
+}
+
+func (s *context) PushScope(node Configuration) Context {
+	clone := s.Clone().(*context)
+	clone.scopes = append(s.scopes[:len(s.scopes):len(s.scopes)], contextScope{
+		rawCfg:      s.rawCfg,
+		lazyRawCfg:  s.lazyRawCfg,
+		cfg:         s.cfg,
+		breadcrumbs: s.injectionTokenBreadcrumb,
+		isScoped:    s.isScoped,
+	})
+
+	clone.ScopedConfiguration(node)
+	clone.ClearBreadcrumbs()
+	return clone
+}
+
+func (s *context) PopScope() (Context, bool) {
+	if len(s.scopes) == 0 {
+		return s, false
+	}
+
+	enclosing := s.scopes[len(s.scopes)-1]
+	clone := s.Clone().(*context)
+	clone.scopes = s.scopes[: len(s.scopes)-1 : len(s.scopes)-1]
+	clone.rawCfg, clone.lazyRawCfg, clone.cfg = enclosing.rawCfg, enclosing.lazyRawCfg, enclosing.cfg
+	clone.injectionTokenBreadcrumb = enclosing.breadcrumbs[:len(enclosing.breadcrumbs):len(enclosing.breadcrumbs)]
+	clone.isScoped = enclosing.isScoped
+	return clone, true
+}
+
+func (s *context) CreateInfo() CreateInfo {
+	return CreateInfo{TypeName: s.resolving, Parent: s.parent, Depth: s.depth, WarmUp: s.warmUp}
 }
 
 // NewContext creates a new Context instance with optional context and configuration data.
 // It accepts variable arguments that can be a context.NewContext, Context, ConfigRawData or Configuration.
 // If no context is provided, it uses context.Background().
-// New NewContext will inherit configuration from parent contexts unless explicitly overridden.
+// New NewContext will inherit configuration from parent contexts unless explicitly overridden,
+// or merged over the parent one with the WithConfigMerge option.
 func NewContext(args ...any) Context {
 	var ctx goctx.Context
 	var parentDiCtx *context
 	var rawData ConfigRawData
 	var cfg Configuration
-	var err error
+	var lazyRawCfg *lazyRawConfiguration
+	var tokenConfigs map[InjectionToken]any
+	var overrides map[overrideKey]any
+	var options contextOptions
 
 	for i := 0; i < len(args); i++ {
 		switch v := args[i].(type) {
+		case ContextOption:
+			if v != nil {
+				v(&options)
+			}
+			args = append(args[:i], args[i+1:]...)
+			i--
 		case Context:
 			var ok bool
 			parentDiCtx, ok = v.(*context)
@@ -149,18 +337,27 @@ func NewContext(args ...any) Context {
 		}
 	}
 
-	if parentDiCtx != nil {
-		if rawData == nil {
-			rawData = parentDiCtx.RawConfiguration()
-		}
+	if parentDiCtx != nil && options.mergeConfig && (rawData != nil || cfg != nil) {
+		merged := MergeConfigurations(parentDiCtx.decodedConfiguration(), rawConfigurationOf(rawData, cfg))
+		rawData, cfg = nil, mergedConfiguration(merged)
+	}
 
+	if parentDiCtx != nil {
 		if cfg == nil {
 			cfg = parentDiCtx.Configuration()
+			lazyRawCfg = parentDiCtx.lazyRawCfg
+		}
+
+		if rawData == nil && cfg == nil {
+			rawData = parentDiCtx.RawConfiguration()
 		}
 
 		if ctx == nil {
 			ctx = parentDiCtx.Inner()
 		}
+
+		tokenConfigs = parentDiCtx.tokenConfigs
+		overrides = parentDiCtx.overrides
 	}
 
 	if ctx == nil {
@@ -168,13 +365,90 @@ func NewContext(args ...any) Context {
 	}
 
 	if cfg != nil {
-		rawData, err = Decode[ConfigRawData](cfg)
-		errors.Must(err)
+		rawData = nil
+		if lazyRawCfg == nil {
+			lazyRawCfg = &lazyRawConfiguration{cfg: cfg}
+		}
+	} else if rawData == nil {
+		rawData = make(ConfigRawData)
 	}
 
-	if rawData == nil {
-		rawData = make(ConfigRawData)
+	return &context{ctx, rawData, lazyRawCfg, cfg, nil, false, nil, "", "", 0, nil, chainLink{}, false, nil, tokenConfigs, nil, nil, nil, overrides}
+}
+
+// ContextOption tunes how NewContext builds a context out of its arguments, see WithConfigMerge.
+type ContextOption func(opts *contextOptions)
+
+type contextOptions struct {
+	mergeConfig bool
+}
+
+// WithConfigMerge makes NewContext deep-merge the configuration given to it over the one of the
+// parent context, rather than replacing it, so a child overriding a section keeps the others:
+//
+//	child := di.NewContext(parent, di.ConfigRawData{"cache": di.ConfigRawData{"ttl": "1m"}}, di.WithConfigMerge())
+//
+// Nested maps are merged recursively like MergeConfigurations does, any other node of the child
+// replaces the one of the parent. Both configurations are decoded into raw maps to be merged,
+// panicking like RawConfiguration when one cannot be decoded, so the nodes of the merged one are
+// raw maps too, read with LookupNodeAs or CreateConfigurationAt.
+func WithConfigMerge() ContextOption {
+	return func(opts *contextOptions) {
+		opts.mergeConfig = true
+	}
+}
+
+// mergedConfiguration is the Configuration of a context built with WithConfigMerge.
+type mergedConfiguration ConfigRawData
+
+func (m mergedConfiguration) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(ConfigRawData(m), lookupPath)
+}
+
+// decodedConfiguration returns the configuration of the context as a raw map, decoding
+// scoped configurations which RawConfiguration leaves nil.
+func (s *context) decodedConfiguration() ConfigRawData {
+	if s.lazyRawCfg != nil {
+		return s.lazyRawCfg.get()
 	}
 
-	return &context{ctx, rawData, cfg, nil, false}
+	return rawConfigurationOf(s.rawCfg, s.cfg)
+}
+
+// rawConfigurationOf returns rawCfg, or cfg decoded into a raw map when rawCfg is nil.
+func rawConfigurationOf(rawCfg ConfigRawData, cfg Configuration) ConfigRawData {
+	if rawCfg != nil || cfg == nil {
+		return rawCfg
+	}
+
+	if raw, ok := cfg.(mergedConfiguration); ok {
+		return ConfigRawData(raw)
+	}
+
+	raw, err := Decode[ConfigRawData](cfg)
+	errors.Must(err)
+	return raw
+}
+
+// lazyRawConfiguration decodes a configuration into its raw map on first use, since most
+// resolutions only look nodes up. It's shared by the contexts holding the same configuration,
+// along with the nodes looked up in it, see configurationNodes.
+type lazyRawConfiguration struct {
+	once  sync.Once
+	cfg   Configuration
+	raw   ConfigRawData
+	nodes atomic.Pointer[configurationNodes]
+}
+
+// get returns the decoded configuration, panicking when it cannot be decoded.
+func (l *lazyRawConfiguration) get() ConfigRawData {
+	l.once.Do(func() {
+		raw := rawConfigurationOf(nil, l.cfg)
+		if raw == nil {
+			raw = make(ConfigRawData)
+		}
+		l.raw = raw
+	})
+
+	return l.raw
 }
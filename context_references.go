@@ -0,0 +1,124 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	gojson "github.com/goccy/go-json"
+	"github.com/pixie-sh/errors-go"
+)
+
+var (
+	contextKeysMu sync.RWMutex
+	contextKeys   = map[string]any{}
+
+	contextReferenceRegex = regexp.MustCompile(`\$\{ctx\.([^}]+)\}`)
+)
+
+// RegisterContextKey maps name to the context value key, so configuration nodes may reference
+// the value with ${ctx.name}. ConfigurationLookup replaces the references found in the string
+// values of a node with the values of the creation context, e.g. with
+//
+//	di.RegisterContextKey("tenant_id", tenantIDKey{})
+//
+// the node {"key_prefix": "cache:${ctx.tenant_id}:"} resolves per request. Registering a name again
+// replaces its key.
+func RegisterContextKey(name string, key any) {
+	contextKeysMu.Lock()
+	defer contextKeysMu.Unlock()
+
+	contextKeys[name] = key
+}
+
+// resolveContextReferences returns node with the ${ctx.name} references of its string values replaced
+// by the context values, keeping its type. node is returned as is when it holds no reference.
+func resolveContextReferences(ctx Context, node any) (any, error) {
+	contextKeysMu.RLock()
+	noKeys := len(contextKeys) == 0
+	contextKeysMu.RUnlock()
+	if noKeys || node == nil {
+		return node, nil
+	}
+
+	if text, ok := node.(string); ok {
+		return replaceContextReferences(ctx, text)
+	}
+
+	data, err := gojson.Marshal(node)
+	if err != nil || !contextReferenceRegex.Match(data) {
+		return node, nil
+	}
+
+	var raw any
+	if err = gojson.Unmarshal(data, &raw); err != nil {
+		return node, nil
+	}
+
+	raw, err = replaceContextReferencesIn(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := reflect.New(reflect.TypeOf(node))
+	if err = DecodeStruct(raw, resolved.Interface()); err != nil {
+		return nil, errors.Wrap(err, "failed to decode configuration node with context references", ConfigurationLookupErrorCode)
+	}
+
+	return resolved.Elem().Interface(), nil
+}
+
+// replaceContextReferencesIn replaces the references of the strings nested in raw, as decoded from JSON.
+func replaceContextReferencesIn(ctx Context, raw any) (any, error) {
+	var err error
+	switch value := raw.(type) {
+	case string:
+		return replaceContextReferences(ctx, value)
+	case map[string]any:
+		for key, child := range value {
+			if value[key], err = replaceContextReferencesIn(ctx, child); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for i, child := range value {
+			if value[i], err = replaceContextReferencesIn(ctx, child); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return raw, nil
+}
+
+// replaceContextReferences replaces the ${ctx.name} references of text with the context values.
+func replaceContextReferences(ctx Context, text string) (string, error) {
+	if !strings.Contains(text, "${ctx.") {
+		return text, nil
+	}
+
+	var errs []error
+	resolved := contextReferenceRegex.ReplaceAllStringFunc(text, func(reference string) string {
+		name := contextReferenceRegex.FindStringSubmatch(reference)[1]
+
+		contextKeysMu.RLock()
+		key, registered := contextKeys[name]
+		contextKeysMu.RUnlock()
+		if !registered {
+			errs = append(errs, errors.New("context key %s is not registered", name, ConfigurationLookupErrorCode))
+			return reference
+		}
+
+		value := ctx.Value(key)
+		if value == nil {
+			errs = append(errs, errors.New("context value %s is not set", name, ConfigurationLookupErrorCode))
+			return reference
+		}
+
+		return fmt.Sprint(value)
+	})
+
+	return resolved, errors.Join(errs...)
+}
@@ -0,0 +1,51 @@
+package di
+
+import (
+	goctx "context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantIDKeyTest struct{}
+
+type cacheConfigTest struct {
+	KeyPrefix string   `json:"key_prefix"`
+	Hosts     []string `json:"hosts"`
+	Size      int      `json:"size"`
+}
+
+type tenantConfigTest struct {
+	Cache cacheConfigTest `json:"cache"`
+	Name  string          `json:"name"`
+}
+
+func (c tenantConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+func TestContextReferences(t *testing.T) {
+	RegisterContextKey("tenant_id", tenantIDKeyTest{})
+
+	cfg := tenantConfigTest{
+		Cache: cacheConfigTest{KeyPrefix: "cache:${ctx.tenant_id}:", Hosts: []string{"${ctx.tenant_id}.cache.local"}, Size: 64},
+		Name:  "tenant ${ctx.tenant_id}",
+	}
+	ctx := NewContext(goctx.WithValue(goctx.Background(), tenantIDKeyTest{}, "acme"), cfg)
+
+	cache, err := ConfigurationLookup[cacheConfigTest](ctx, &RegistryOpts{ConfigNodePath: "cache"})
+	require.NoError(t, err)
+	assert.Equal(t, cacheConfigTest{KeyPrefix: "cache:acme:", Hosts: []string{"acme.cache.local"}, Size: 64}, cache)
+
+	name, err := ConfigurationLookup[string](ctx, &RegistryOpts{ConfigNodePath: "name"})
+	require.NoError(t, err)
+	assert.Equal(t, "tenant acme", name)
+
+	_, err = ConfigurationLookup[string](NewContext(cfg), &RegistryOpts{ConfigNodePath: "name"})
+	assert.ErrorContains(t, err, "context value tenant_id is not set")
+
+	cfg.Name = "${ctx.region}"
+	_, err = ConfigurationLookup[string](NewContext(ctx, cfg), &RegistryOpts{ConfigNodePath: "name"})
+	assert.ErrorContains(t, err, "context key region is not registered")
+}
@@ -138,6 +138,46 @@ func TestContext_WithParentAndOverrides(t *testing.T) {
 	}
 }
 
+func TestContext_WithConfigMerge(t *testing.T) {
+	parentCtx := NewContext(ConfigRawData{
+		"database": ConfigRawData{"host": "db.local", "port": 5432},
+		"cache":    ConfigRawData{"ttl": "5m", "size": 100},
+	})
+
+	ctx := NewContext(parentCtx, ConfigRawData{"cache": ConfigRawData{"ttl": "1m"}}, WithConfigMerge())
+
+	expected := ConfigRawData{
+		"database": ConfigRawData{"host": "db.local", "port": 5432},
+		"cache":    ConfigRawData{"ttl": "1m", "size": 100},
+	}
+	if !reflect.DeepEqual(ctx.RawConfiguration(), expected) {
+		t.Errorf("Expected merged config %v, got %v", expected, ctx.RawConfiguration())
+	}
+
+	host, err := ConfigurationLookup[string](ctx, &RegistryOpts{ConfigNodePath: "database.host"})
+	if err != nil || host != "db.local" {
+		t.Errorf("Expected lookups in the merged config, got %v, %v", host, err)
+	}
+
+	if parentCtx.RawConfiguration()["cache"].(ConfigRawData)["ttl"] != "5m" {
+		t.Errorf("Expected the parent config to be left untouched, got %v", parentCtx.RawConfiguration())
+	}
+
+	cfg := replicasConfigTest{}
+	cfg.Replica.Host = "replica.local"
+	cfg.Replica.PoolSize = 10
+
+	typed := NewContext(NewContext(cfg), ConfigRawData{"replica": ConfigRawData{"pool_size": 50}}, WithConfigMerge())
+	poolCfg, err := CreateConfigurationAt[replicaPoolConfigTest](typed, "replica")
+	if err != nil || poolCfg.Host != "replica.local" || poolCfg.PoolSize != 50 {
+		t.Errorf("Expected the override merged over the typed parent config, got %+v, %v", poolCfg, err)
+	}
+
+	if inherited := NewContext(parentCtx, WithConfigMerge()); !reflect.DeepEqual(inherited.RawConfiguration(), parentCtx.RawConfiguration()) {
+		t.Errorf("Expected the parent config to be inherited as is without child config, got %v", inherited.RawConfiguration())
+	}
+}
+
 func TestContext_WithStdContextAndConfig(t *testing.T) {
 	// Test with both standard context and config
 	stdCtx := goctx.WithValue(goctx.Background(), "key", "value")
@@ -306,3 +346,195 @@ func TestContext_Value(t *testing.T) {
 		t.Errorf("Expected nil for nonexistent key, got %v", ctx.Value("nonexistent"))
 	}
 }
+
+type replicaPoolConfigTest struct {
+	Host     string `json:"host"`
+	PoolSize int    `json:"pool_size"`
+	Timeouts struct {
+		Read  int `json:"read"`
+		Write int `json:"write"`
+	} `json:"timeouts"`
+}
+
+type replicasConfigTest struct {
+	Replica replicaPoolConfigTest `json:"replica"`
+}
+
+func (r replicasConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(r, lookupPath)
+}
+
+func (r replicaPoolConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(r, lookupPath)
+}
+
+func TestContext_WithTokenConfig(t *testing.T) {
+	cfg := replicasConfigTest{}
+	cfg.Replica.Host = "replica.local"
+	cfg.Replica.PoolSize = 10
+	cfg.Replica.Timeouts.Read = 5
+	cfg.Replica.Timeouts.Write = 7
+
+	base := NewContext(cfg)
+	ctx := base.WithTokenConfig("replica", ConfigRawData{
+		"pool_size": 50,
+		"timeouts":  ConfigRawData{"read": 1},
+	})
+
+	if _, ok := base.TokenConfig("replica"); ok {
+		t.Errorf("Expected overlay not to leak into the parent context")
+	}
+
+	if _, ok := NewContext(ctx).TokenConfig("replica"); !ok {
+		t.Errorf("Expected overlay to be inherited by child contexts")
+	}
+
+	registry := NewRegistry()
+	err := RegisterConfiguration[replicaPoolConfigTest](ConfigurationLookup[replicaPoolConfigTest], WithRegistry(registry), WithToken("replica"))
+	if err != nil {
+		t.Fatalf("Expected no error registering configuration, got %v", err)
+	}
+
+	poolCfg, err := CreateConfiguration[replicaPoolConfigTest](ctx, WithRegistry(registry), WithToken("replica"), WithConfigNodePath("replica"))
+	if err != nil {
+		t.Fatalf("Expected no error creating configuration, got %v", err)
+	}
+
+	expected := cfg.Replica
+	expected.PoolSize = 50
+	expected.Timeouts.Read = 1
+	if !reflect.DeepEqual(poolCfg, expected) {
+		t.Errorf("Expected overlaid configuration %+v, got %+v", expected, poolCfg)
+	}
+
+	poolCfg, err = ConfigurationLookup[replicaPoolConfigTest](base, &RegistryOpts{InjectionToken: "replica", ConfigNodePath: "replica"})
+	if err != nil {
+		t.Fatalf("Expected no error looking up configuration, got %v", err)
+	}
+
+	if !reflect.DeepEqual(poolCfg, cfg.Replica) {
+		t.Errorf("Expected configuration without overlay %+v, got %+v", cfg.Replica, poolCfg)
+	}
+}
+
+type undecodableConfig int
+
+func (u undecodableConfig) LookupNode(lookupPath string) (any, error) {
+	return int(u), nil
+}
+
+func TestContext_LazyRawConfiguration(t *testing.T) {
+	ctx := NewContext(undecodableConfig(42))
+	child := NewContext(ctx.Clone())
+
+	node, err := child.Configuration().LookupNode("")
+	if err != nil || node != 42 {
+		t.Errorf("Expected configuration lookups to work without decoding, got %v, %v", node, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected RawConfiguration to panic decoding an undecodable configuration")
+		}
+	}()
+
+	child.RawConfiguration()
+}
+
+func TestContext_RawConfigurationDecodedOnce(t *testing.T) {
+	cfg := replicasConfigTest{}
+	cfg.Replica.PoolSize = 10
+
+	ctx := NewContext(cfg)
+	raw := ctx.RawConfiguration()
+	if _, ok := raw["replica"]; !ok {
+		t.Fatalf("Expected raw configuration to hold the replica node, got %v", raw)
+	}
+
+	raw["decoded"] = true
+	if _, ok := NewContext(ctx).RawConfiguration()["decoded"]; !ok {
+		t.Errorf("Expected child contexts sharing the configuration to reuse the decoded map")
+	}
+}
+
+func TestContext_PushScope(t *testing.T) {
+	cfg := replicasConfigTest{}
+	cfg.Replica.Host = "replica.local"
+	cfg.Replica.PoolSize = 10
+
+	root := NewContext(cfg)
+	root.AppendBreadcrumb("database")
+
+	if _, ok := root.PopScope(); ok {
+		t.Errorf("Expected PopScope to report no scope on an unscoped context")
+	}
+
+	replica := root.PushScope(cfg.Replica)
+	if !replica.IsScoped() || len(replica.Breadcrumbs()) != 0 {
+		t.Errorf("Expected a scoped context with fresh breadcrumbs, got scoped %v and %v", replica.IsScoped(), replica.Breadcrumbs())
+	}
+
+	if root.IsScoped() || !reflect.DeepEqual(root.Breadcrumbs(), []string{"database"}) {
+		t.Errorf("Expected PushScope to leave the context untouched, got scoped %v and %v", root.IsScoped(), root.Breadcrumbs())
+	}
+
+	host, err := ConfigurationLookup[string](replica, &RegistryOpts{ConfigNodePath: "host"})
+	if err != nil || host != "replica.local" {
+		t.Errorf("Expected lookups relative to the pushed node, got %v, %v", host, err)
+	}
+
+	nested := replica.PushScope(replicaPoolConfigTest{Host: "nested.local"})
+	nested.AppendBreadcrumb("nested")
+
+	popped, ok := nested.PopScope()
+	if !ok || popped.Configuration() != replica.Configuration() || !popped.IsScoped() || len(popped.Breadcrumbs()) != 0 {
+		t.Errorf("Expected PopScope to restore the replica scope, got %v, scoped %v and %v", popped.Configuration(), popped.IsScoped(), popped.Breadcrumbs())
+	}
+
+	popped, ok = popped.PopScope()
+	if !ok || popped.Configuration() != root.Configuration() || popped.IsScoped() || !reflect.DeepEqual(popped.Breadcrumbs(), []string{"database"}) {
+		t.Errorf("Expected PopScope to restore the root scope, got %v, scoped %v and %v", popped.Configuration(), popped.IsScoped(), popped.Breadcrumbs())
+	}
+
+	if _, ok = popped.PopScope(); ok {
+		t.Errorf("Expected PopScope to report no scope once every pushed scope was popped")
+	}
+}
+
+func TestContext_WithValue(t *testing.T) {
+	cfg := replicasConfigTest{}
+	cfg.Replica.Host = "replica.local"
+
+	ctx := NewContext(cfg).PushScope(cfg.Replica)
+	ctx.AppendBreadcrumb("replica")
+
+	valued := ctx.WithValue("request_id", "r-42")
+	if valued.Value("request_id") != "r-42" || ctx.Value("request_id") != nil {
+		t.Errorf("Expected the value only on the returned context, got %v and %v", valued.Value("request_id"), ctx.Value("request_id"))
+	}
+
+	if valued.Configuration() != ctx.Configuration() || !valued.IsScoped() || !reflect.DeepEqual(valued.Breadcrumbs(), []string{"replica"}) {
+		t.Errorf("Expected WithValue to keep the configuration, scope and breadcrumbs, got %v, scoped %v and %v", valued.Configuration(), valued.IsScoped(), valued.Breadcrumbs())
+	}
+
+	cancellable, cancel := valued.WithCancel()
+	cancel()
+	if cancellable.Err() != goctx.Canceled || valued.Err() != nil {
+		t.Errorf("Expected only the returned context to be cancelled, got %v and %v", cancellable.Err(), valued.Err())
+	}
+
+	if cancellable.Value("request_id") != "r-42" || cancellable.Configuration() != ctx.Configuration() {
+		t.Errorf("Expected WithCancel to keep the values and configuration of the context")
+	}
+
+	deadline := time.Now().Add(time.Hour)
+	bounded, cancel := valued.WithDeadline(deadline)
+	defer cancel()
+	if got, ok := bounded.Deadline(); !ok || !got.Equal(deadline) {
+		t.Errorf("Expected deadline %v, got %v, %v", deadline, got, ok)
+	}
+
+	if _, ok := bounded.PopScope(); !ok {
+		t.Errorf("Expected WithDeadline to keep the pushed scopes")
+	}
+}
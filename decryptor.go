@@ -0,0 +1,127 @@
+package di
+
+import (
+	goctx "context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Decryptor decrypts the ${enc:ciphertext} values of configurations, so sensitive values can live
+// encrypted in config files. Plug one with SetDecryptor, e.g. an AESGCMDecryptor or a KMS adapter
+// like diaws.NewKMSDecryptor. Implementations must be safe for concurrent use.
+type Decryptor interface {
+	Decrypt(ctx goctx.Context, ciphertext string) (string, error)
+}
+
+var (
+	decryptorMu sync.RWMutex
+	decryptor   Decryptor
+
+	encryptedReferenceRegex = regexp.MustCompile(`"?\$\{enc:([^}]+)\}"?`)
+)
+
+// SetDecryptor makes ResolveDIReferences, and so every configuration load, decrypt the ${enc:ciphertext}
+// values with d. Without a Decryptor, loading a configuration holding encrypted values fails.
+func SetDecryptor(d Decryptor) {
+	decryptorMu.Lock()
+	defer decryptorMu.Unlock()
+
+	decryptor = d
+}
+
+// resolveEncryptedReferences replaces the ${enc:ciphertext} values found in the strings of a JSON document
+// with their plaintext.
+func resolveEncryptedReferences(ctx goctx.Context, jsonStr string) (string, error) {
+	if !strings.Contains(jsonStr, "${enc:") {
+		return jsonStr, nil
+	}
+
+	decryptorMu.RLock()
+	d := decryptor
+	decryptorMu.RUnlock()
+
+	return replaceStringReferences(jsonStr, encryptedReferenceRegex, func(ciphertext string) (string, error) {
+		if d == nil {
+			return "", fmt.Errorf("no Decryptor set to decrypt encrypted configuration values")
+		}
+
+		plaintext, err := d.Decrypt(ctx, ciphertext)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt encrypted configuration value: %w", err)
+		}
+
+		return plaintext, nil
+	})
+}
+
+// AESGCMDecryptor is a Decryptor of AES-GCM ciphertexts, base64 encoded with their nonce first.
+// Encrypt produces them, e.g. from a release tool holding the same key.
+type AESGCMDecryptor struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMDecryptor returns a decryptor using key, of 16, 24 or 32 bytes for AES-128, AES-192 or AES-256.
+func NewAESGCMDecryptor(key []byte) (*AESGCMDecryptor, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AESGCMDecryptor{aead: aead}, nil
+}
+
+// NewAESGCMDecryptorFromEnv returns a decryptor using the base64 encoded key of the environment variable.
+func NewAESGCMDecryptorFromEnv(variable string) (*AESGCMDecryptor, error) {
+	encoded, ok := os.LookupEnv(variable)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", variable)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("environment variable %s is not a base64 key: %w", variable, err)
+	}
+
+	return NewAESGCMDecryptor(key)
+}
+
+func (d *AESGCMDecryptor) Decrypt(_ goctx.Context, ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < d.aead.NonceSize() {
+		return "", fmt.Errorf("ciphertext is shorter than its nonce")
+	}
+
+	nonce, sealed := sealed[:d.aead.NonceSize()], sealed[d.aead.NonceSize():]
+	plaintext, err := d.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// Encrypt returns the ciphertext of plaintext, to write as ${enc:ciphertext} in a configuration.
+func (d *AESGCMDecryptor) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, d.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(d.aead.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
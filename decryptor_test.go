@@ -0,0 +1,53 @@
+package di
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedValues(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	t.Setenv("CONFIG_KEY", base64.StdEncoding.EncodeToString(key))
+
+	decryptor, err := NewAESGCMDecryptorFromEnv("CONFIG_KEY")
+	require.NoError(t, err)
+
+	ciphertext, err := decryptor.Encrypt(`p"ss`)
+	require.NoError(t, err)
+
+	document := []byte(`{
+		"database": {"password": "${enc:` + ciphertext + `}"},
+		"replica": "${di.database}"
+	}`)
+
+	var cfg struct {
+		Database struct {
+			Password string `json:"password"`
+		} `json:"database"`
+		Replica struct {
+			Password string `json:"password"`
+		} `json:"replica"`
+	}
+
+	err = UnmarshalJSONWithDIResolution(document, &cfg)
+	assert.ErrorContains(t, err, "no Decryptor set")
+
+	SetDecryptor(decryptor)
+	t.Cleanup(func() { SetDecryptor(nil) })
+
+	require.NoError(t, UnmarshalJSONWithDIResolution(document, &cfg))
+	assert.Equal(t, `p"ss`, cfg.Database.Password)
+	assert.Equal(t, `p"ss`, cfg.Replica.Password)
+
+	t.Run("wrong key", func(t *testing.T) {
+		other, err := NewAESGCMDecryptor([]byte("fedcba9876543210fedcba9876543210"))
+		require.NoError(t, err)
+		SetDecryptor(other)
+
+		_, err = ResolveDIReferences(string(document))
+		assert.ErrorContains(t, err, "failed to decrypt encrypted configuration value")
+	})
+}
@@ -0,0 +1,43 @@
+// Command diaccessor writes the typed configuration accessors of the structs of the package in
+// the current directory, see the diaccessor package:
+//
+//	//go:generate go run github.com/pixie-sh/di-go/diaccessor/cmd/diaccessor -type AppConfig
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pixie-sh/di-go/diaccessor"
+)
+
+func main() {
+	types := flag.String("type", "", "comma separated names of the root configuration structs")
+	dir := flag.String("dir", ".", "directory of the package declaring the structs")
+	output := flag.String("output", "config_accessors_gen.go", "file to write the accessors to, relative to dir")
+	flag.Parse()
+
+	if len(*types) == 0 {
+		fmt.Fprintln(os.Stderr, "diaccessor: -type is required")
+		os.Exit(2)
+	}
+
+	source, err := diaccessor.Generate(diaccessor.Options{Dir: *dir, Types: strings.Split(*types, ",")})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "diaccessor:", err)
+		os.Exit(1)
+	}
+
+	path := *output
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(*dir, path)
+	}
+
+	if err = os.WriteFile(path, source, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "diaccessor:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,299 @@
+// Package diaccessor generates typed accessors for the node paths of configuration structs, so
+// providers reference configuration sections through compile-checked methods instead of strings:
+//
+//	//go:generate go run github.com/pixie-sh/di-go/diaccessor/cmd/diaccessor -type AppConfig
+//
+//	cfg := AppConfigPaths()
+//	di.Create[*chargebee.Client](ctx, di.WithConfigNodePath(cfg.PaymentBusinessLayer().Chargebee().Path()))
+//
+// Every struct reachable from the given types gets a node type with a method per exported field,
+// named after the field and following its json tag as ConfigurationNodeLookup does. Struct fields
+// return their node type, the other fields return their path.
+package diaccessor
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Options selects the configuration structs to generate accessors for.
+type Options struct {
+	// Dir is the directory of the package declaring the types, the current one when empty.
+	Dir string
+	// Types are the names of the root configuration structs.
+	Types []string
+}
+
+// Generate returns the formatted Go source of the accessors of the root types of opts, in the
+// package declaring them.
+func Generate(opts Options) ([]byte, error) {
+	if len(opts.Types) == 0 {
+		return nil, fmt.Errorf("no configuration type to generate accessors for")
+	}
+
+	dir := opts.Dir
+	if len(dir) == 0 {
+		dir = "."
+	}
+
+	pkg, structs, err := parseStructs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{structs: structs, generated: map[string]bool{}}
+	for _, name := range opts.Types {
+		if _, ok := structs[name]; !ok {
+			return nil, fmt.Errorf("struct type %s not found in %s", name, dir)
+		}
+
+		g.roots = append(g.roots, name)
+		if err = g.node(name+"Node", structs[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	if err = fileTemplate.Execute(&out, struct {
+		Package string
+		Roots   []string
+		Nodes   []node
+	}{pkg, g.roots, g.nodes}); err != nil {
+		return nil, fmt.Errorf("failed to render accessors: %w", err)
+	}
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format accessors: %w", err)
+	}
+
+	return source, nil
+}
+
+// parseStructs returns the package name and the struct types declared by the non test files of dir.
+func parseStructs(dir string) (string, map[string]*ast.StructType, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	pkg := ""
+	structs := map[string]*ast.StructType{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return "", nil, err
+		}
+
+		parsed, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		pkg = parsed.Name.Name
+		ast.Inspect(parsed, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					structs[spec.Name.Name] = st
+				}
+			}
+			return true
+		})
+	}
+
+	if len(pkg) == 0 {
+		return "", nil, fmt.Errorf("no Go package found in %s", dir)
+	}
+
+	return pkg, structs, nil
+}
+
+type node struct {
+	Name   string
+	Fields []field
+}
+
+type field struct {
+	Method string
+	Key    string
+	Node   string // node type of struct fields, empty for the other fields
+}
+
+type generator struct {
+	structs   map[string]*ast.StructType
+	roots     []string
+	nodes     []node
+	generated map[string]bool
+}
+
+// node generates the node type name for st and the node types of its struct fields.
+func (g *generator) node(name string, st *ast.StructType) error {
+	if g.generated[name] {
+		return nil
+	}
+	g.generated[name] = true
+
+	index := len(g.nodes)
+	g.nodes = append(g.nodes, node{Name: name})
+	fields, err := g.fields(name, st)
+	if err != nil {
+		return err
+	}
+
+	var unique []field
+	for _, f := range fields {
+		if !slices.ContainsFunc(unique, func(other field) bool { return other.Method == f.Method }) {
+			unique = append(unique, f)
+		}
+	}
+
+	g.nodes[index].Fields = unique
+	return nil
+}
+
+// fields returns the accessors of the exported fields of st, promoting the ones of embedded structs.
+func (g *generator) fields(nodeName string, st *ast.StructType) ([]field, error) {
+	var fields []field
+	for _, f := range st.Fields.List {
+		key, skip := jsonKey(f)
+		if skip {
+			continue
+		}
+
+		target, targetName := g.structOf(f.Type)
+		if len(f.Names) == 0 {
+			if target == nil {
+				continue
+			}
+
+			promoted, err := g.fields(nodeName, target)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, promoted...)
+			continue
+		}
+
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+
+			if ident.Name == "Path" {
+				return nil, fmt.Errorf("field Path of %s conflicts with the Path accessor", strings.TrimSuffix(nodeName, "Node"))
+			}
+
+			accessor := field{Method: ident.Name, Key: key}
+			if len(accessor.Key) == 0 {
+				accessor.Key = ident.Name
+			}
+
+			if target != nil {
+				if len(targetName) == 0 {
+					targetName = strings.TrimSuffix(nodeName, "Node") + ident.Name
+				}
+
+				accessor.Node = targetName + "Node"
+				if err := g.node(accessor.Node, target); err != nil {
+					return nil, err
+				}
+			}
+
+			fields = append(fields, accessor)
+		}
+	}
+
+	return fields, nil
+}
+
+// structOf returns the struct type expr refers to, with its name unless it's anonymous.
+// It's nil when expr isn't a struct, or a pointer to one, declared in the package.
+func (g *generator) structOf(expr ast.Expr) (*ast.StructType, string) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return t, ""
+	case *ast.Ident:
+		return g.structs[t.Name], t.Name
+	}
+
+	return nil, ""
+}
+
+// jsonKey returns the key of f in its json tag, reporting whether the tag skips the field.
+func jsonKey(f *ast.Field) (string, bool) {
+	if f.Tag == nil {
+		return "", false
+	}
+
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+	return name, name == "-"
+}
+
+var fileTemplate = template.Must(template.New("accessors").Parse(`// Code generated by diaccessor. DO NOT EDIT.
+
+package {{ .Package }}
+{{ range .Roots }}
+// {{ . }}Paths returns the typed configuration node paths of {{ . }}, rooted at the whole configuration.
+func {{ . }}Paths() {{ . }}Node {
+	return {{ . }}Node{}
+}
+{{ end }}
+{{- range .Nodes }}
+{{ $node := .Name }}
+// {{ $node }} is the path of a configuration node.
+type {{ $node }} struct {
+	path string
+}
+
+// Path returns the node path, as used by di.WithConfigNodePath.
+func (n {{ $node }}) Path() string {
+	return n.path
+}
+
+func (n {{ $node }}) child(key string) string {
+	if len(n.path) == 0 {
+		return key
+	}
+
+	return n.path + "." + key
+}
+{{ range .Fields }}
+{{- if .Node }}
+// {{ .Method }} returns the node at {{ .Key }}.
+func (n {{ $node }}) {{ .Method }}() {{ .Node }} {
+	return {{ .Node }}{path: n.child("{{ .Key }}")}
+}
+{{ else }}
+// {{ .Method }} returns the path of {{ .Key }}.
+func (n {{ $node }}) {{ .Method }}() string {
+	return n.child("{{ .Key }}")
+}
+{{ end }}
+{{- end }}
+{{- end }}
+`))
@@ -0,0 +1,65 @@
+package diaccessor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const configSource = `package config
+
+type AppConfig struct {
+	PaymentBusinessLayer PaymentConfig ` + "`json:\"payment_business_layer\"`" + `
+	Cache                *CacheConfig  ` + "`json:\"cache,omitempty\"`" + `
+	Server               struct {
+		Port int ` + "`json:\"port\"`" + `
+	} ` + "`json:\"server\"`" + `
+	Ignored string ` + "`json:\"-\"`" + `
+	internal string
+}
+
+type PaymentConfig struct {
+	Chargebee ChargebeeConfig ` + "`json:\"chargebee\"`" + `
+}
+
+type ChargebeeConfig struct {
+	Common
+	Site string ` + "`json:\"site\"`" + `
+}
+
+type CacheConfig struct {
+	TTL int
+}
+
+type Common struct {
+	APIKey string ` + "`json:\"api_key\"`" + `
+}
+`
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.go"), []byte(configSource), 0o600))
+
+	source, err := Generate(Options{Dir: dir, Types: []string{"AppConfig"}})
+	require.NoError(t, err)
+
+	generated := string(source)
+	assert.Contains(t, generated, "package config")
+	assert.Contains(t, generated, "func AppConfigPaths() AppConfigNode {")
+	assert.Contains(t, generated, "func (n AppConfigNode) PaymentBusinessLayer() PaymentConfigNode {\n\treturn PaymentConfigNode{path: n.child(\"payment_business_layer\")}")
+	assert.Contains(t, generated, "func (n PaymentConfigNode) Chargebee() ChargebeeConfigNode {")
+	assert.Contains(t, generated, "func (n ChargebeeConfigNode) APIKey() string {\n\treturn n.child(\"api_key\")")
+	assert.Contains(t, generated, "func (n AppConfigNode) Cache() CacheConfigNode {")
+	assert.Contains(t, generated, "func (n CacheConfigNode) TTL() string {\n\treturn n.child(\"TTL\")")
+	assert.Contains(t, generated, "func (n AppConfigNode) Server() AppConfigServerNode {")
+	assert.NotContains(t, generated, "Ignored")
+	assert.NotContains(t, generated, "internal()")
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := Generate(Options{Dir: dir, Types: []string{"Missing"}})
+		assert.ErrorContains(t, err, "struct type Missing not found")
+	})
+}
@@ -0,0 +1,169 @@
+// Package diaws resolves the ${secret.aws:name} configuration references from AWS Secrets Manager
+// and Systems Manager Parameter Store:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	di.RegisterSecretProvider("aws", diaws.New(secretsmanager.NewFromConfig(cfg), ssm.NewFromConfig(cfg)))
+//
+// A name starting with a slash reads the Parameter Store parameter, decrypted, "${secret.aws:/prod/db/password}".
+// Any other name reads the Secrets Manager secret with that id or ARN, "${secret.aws:prod/db}", a
+// "#key" suffix selecting a key of a JSON secret, "${secret.aws:prod/db#password}".
+//
+// Values are cached for the provider TTL, so the secrets rotated in AWS are picked up by the
+// configurations loaded after it expires.
+//
+// KMSDecryptor decrypts the ${enc:ciphertext} configuration values encrypted with AWS KMS.
+package diaws
+
+import (
+	goctx "context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	gojson "github.com/goccy/go-json"
+	di "github.com/pixie-sh/di-go"
+)
+
+// DefaultTTL is the duration values are cached for by default.
+const DefaultTTL = 5 * time.Minute
+
+// SecretsManagerAPI is the part of the Secrets Manager client used by the provider, *secretsmanager.Client.
+type SecretsManagerAPI interface {
+	GetSecretValue(ctx goctx.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// ParameterStoreAPI is the part of the Systems Manager client used by the provider, *ssm.Client.
+type ParameterStoreAPI interface {
+	GetParameter(ctx goctx.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// Provider is a di.SecretProvider reading secrets from AWS, safe for concurrent use.
+type Provider struct {
+	secrets    SecretsManagerAPI
+	parameters ParameterStoreAPI
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+var _ di.SecretProvider = (*Provider)(nil)
+
+type cached struct {
+	value   string
+	expires time.Time
+}
+
+// Option configures a Provider.
+type Option func(p *Provider)
+
+// WithTTL caches the values for ttl instead of DefaultTTL, a zero ttl caches them for good.
+func WithTTL(ttl time.Duration) Option {
+	return func(p *Provider) {
+		p.ttl = ttl
+	}
+}
+
+// New returns a provider reading secrets with the Secrets Manager and Parameter Store clients.
+// Either may be nil when its references aren't used.
+func New(secrets SecretsManagerAPI, parameters ParameterStoreAPI, options ...Option) *Provider {
+	p := &Provider{
+		secrets:    secrets,
+		parameters: parameters,
+		ttl:        DefaultTTL,
+		now:        time.Now,
+		cache:      map[string]cached{},
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+// Secret returns the secret or parameter name, from the cache while it's fresh.
+func (p *Provider) Secret(ctx goctx.Context, name string) (string, error) {
+	p.mu.Lock()
+	entry, ok := p.cache[name]
+	p.mu.Unlock()
+	if ok && (entry.expires.IsZero() || p.now().Before(entry.expires)) {
+		return entry.value, nil
+	}
+
+	var value string
+	var err error
+	if strings.HasPrefix(name, "/") {
+		value, err = p.parameter(ctx, name)
+	} else {
+		value, err = p.secret(ctx, name)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	entry = cached{value: value}
+	if p.ttl > 0 {
+		entry.expires = p.now().Add(p.ttl)
+	}
+
+	p.mu.Lock()
+	p.cache[name] = entry
+	p.mu.Unlock()
+	return value, nil
+}
+
+func (p *Provider) parameter(ctx goctx.Context, name string) (string, error) {
+	if p.parameters == nil {
+		return "", fmt.Errorf("no parameter store client to read parameter %s", name)
+	}
+
+	decrypt := true
+	output, err := p.parameters.GetParameter(ctx, &ssm.GetParameterInput{Name: &name, WithDecryption: &decrypt})
+	if err != nil {
+		return "", fmt.Errorf("failed to read parameter %s: %w", name, err)
+	}
+
+	if output.Parameter == nil || output.Parameter.Value == nil {
+		return "", fmt.Errorf("parameter %s has no value", name)
+	}
+
+	return *output.Parameter.Value, nil
+}
+
+func (p *Provider) secret(ctx goctx.Context, name string) (string, error) {
+	if p.secrets == nil {
+		return "", fmt.Errorf("no secrets manager client to read secret %s", name)
+	}
+
+	id, key, hasKey := strings.Cut(name, "#")
+	output, err := p.secrets.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: &id})
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", id, err)
+	}
+
+	if output.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no string value", id)
+	}
+
+	if !hasKey {
+		return *output.SecretString, nil
+	}
+
+	var values map[string]any
+	if err = gojson.Unmarshal([]byte(*output.SecretString), &values); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", id, err)
+	}
+
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %s", id, key)
+	}
+
+	return fmt.Sprint(value), nil
+}
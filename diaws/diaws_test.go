@@ -0,0 +1,111 @@
+package diaws
+
+import (
+	goctx "context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	di "github.com/pixie-sh/di-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSecretsManager map[string]string
+
+func (f fakeSecretsManager) GetSecretValue(_ goctx.Context, params *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	value, ok := f[*params.SecretId]
+	if !ok {
+		return nil, fmt.Errorf("ResourceNotFoundException")
+	}
+
+	return &secretsmanager.GetSecretValueOutput{SecretString: &value}, nil
+}
+
+type fakeParameterStore struct {
+	values map[string]string
+	reads  int
+}
+
+func (f *fakeParameterStore) GetParameter(_ goctx.Context, params *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	f.reads++
+	value, ok := f.values[*params.Name]
+	if !ok || !*params.WithDecryption {
+		return nil, fmt.Errorf("ParameterNotFound")
+	}
+
+	return &ssm.GetParameterOutput{Parameter: &types.Parameter{Value: &value}}, nil
+}
+
+func TestProvider(t *testing.T) {
+	parameters := &fakeParameterStore{values: map[string]string{"/prod/db/password": "first"}}
+	provider := New(fakeSecretsManager{"prod/db": `{"password": "s3cr3t", "port": 5432}`, "prod/token": "abc"}, parameters, WithTTL(time.Minute))
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	di.RegisterSecretProvider("aws", provider)
+
+	var cfg struct {
+		Password  string `json:"password"`
+		Parameter string `json:"parameter"`
+		Port      string `json:"port"`
+		Token     string `json:"token"`
+	}
+	require.NoError(t, di.UnmarshalJSONWithDIResolution([]byte(`{
+		"password": "${secret.aws:prod/db#password}",
+		"parameter": "${secret.aws:/prod/db/password}",
+		"port": "${secret.aws:prod/db#port}",
+		"token": "${secret.aws:prod/token}"
+	}`), &cfg))
+	assert.Equal(t, "s3cr3t", cfg.Password)
+	assert.Equal(t, "first", cfg.Parameter)
+	assert.Equal(t, "5432", cfg.Port)
+	assert.Equal(t, "abc", cfg.Token)
+
+	ctx := goctx.Background()
+	parameters.values["/prod/db/password"] = "second"
+	value, err := provider.Secret(ctx, "/prod/db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+	assert.Equal(t, 1, parameters.reads)
+
+	now = now.Add(2 * time.Minute)
+	value, err = provider.Secret(ctx, "/prod/db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "second", value)
+
+	_, err = provider.Secret(ctx, "prod/db#user")
+	assert.ErrorContains(t, err, "secret prod/db has no key user")
+	_, err = New(nil, nil).Secret(ctx, "/prod/db/password")
+	assert.ErrorContains(t, err, "no parameter store client")
+}
+
+type fakeKMS map[string]string
+
+func (f fakeKMS) Decrypt(_ goctx.Context, params *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	plaintext, ok := f[string(params.CiphertextBlob)]
+	if !ok {
+		return nil, fmt.Errorf("InvalidCiphertextException")
+	}
+
+	return &kms.DecryptOutput{Plaintext: []byte(plaintext)}, nil
+}
+
+func TestKMSDecryptor(t *testing.T) {
+	di.SetDecryptor(NewKMSDecryptor(fakeKMS{"blob": "s3cr3t"}))
+	t.Cleanup(func() { di.SetDecryptor(nil) })
+
+	var cfg struct {
+		Password string `json:"password"`
+	}
+	require.NoError(t, di.UnmarshalJSONWithDIResolution([]byte(`{"password": "${enc:`+base64.StdEncoding.EncodeToString([]byte("blob"))+`}"}`), &cfg))
+	assert.Equal(t, "s3cr3t", cfg.Password)
+
+	_, err := di.ResolveDIReferences(`{"password": "${enc:` + base64.StdEncoding.EncodeToString([]byte("other")) + `}"}`)
+	assert.ErrorContains(t, err, "InvalidCiphertextException")
+}
@@ -0,0 +1,44 @@
+package diaws
+
+import (
+	goctx "context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	di "github.com/pixie-sh/di-go"
+)
+
+// KMSAPI is the part of the KMS client used by the decryptor, *kms.Client.
+type KMSAPI interface {
+	Decrypt(ctx goctx.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSDecryptor is a di.Decryptor of the ${enc:ciphertext} configuration values encrypted with AWS KMS,
+// the ciphertext being the base64 encoded blob returned by the KMS Encrypt call:
+//
+//	di.SetDecryptor(diaws.NewKMSDecryptor(kms.NewFromConfig(cfg)))
+type KMSDecryptor struct {
+	client KMSAPI
+}
+
+var _ di.Decryptor = (*KMSDecryptor)(nil)
+
+// NewKMSDecryptor returns a decryptor decrypting with the KMS client, the key being read from the ciphertext.
+func NewKMSDecryptor(client KMSAPI) *KMSDecryptor {
+	return &KMSDecryptor{client: client}
+}
+
+func (d *KMSDecryptor) Decrypt(ctx goctx.Context, ciphertext string) (string, error) {
+	blob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("kms ciphertext is not base64: %w", err)
+	}
+
+	output, err := d.client.Decrypt(ctx, &kms.DecryptInput{CiphertextBlob: blob})
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt with kms: %w", err)
+	}
+
+	return string(output.Plaintext), nil
+}
@@ -0,0 +1,37 @@
+// Command digen writes the static wiring of the di.Register and di.RegisterPair calls of the package
+// in the current directory, see the digen package:
+//
+//	//go:generate go run github.com/pixie-sh/di-go/digen/cmd/digen
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pixie-sh/di-go/digen"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package registering the providers")
+	container := flag.String("container", "Container", "name of the generated container type")
+	output := flag.String("output", "di_wiring_gen.go", "file to write the wiring to, relative to dir")
+	flag.Parse()
+
+	source, err := digen.Generate(digen.Options{Dir: *dir, Container: *container})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "digen:", err)
+		os.Exit(1)
+	}
+
+	path := *output
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(*dir, path)
+	}
+
+	if err = os.WriteFile(path, source, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "digen:", err)
+		os.Exit(1)
+	}
+}
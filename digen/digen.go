@@ -0,0 +1,514 @@
+// Package digen generates static wiring code from the di.Register and di.RegisterPair calls of a package:
+//
+//	//go:generate go run github.com/pixie-sh/di-go/digen/cmd/digen
+//
+//	container := NewContainer(ctx, registry)
+//	service, err := container.Service()
+//
+// The generated file checks at compile time that every factory matches the type it's registered for,
+// and declares a container with a typed method per registration, creating it by its registration name
+// with concrete type assertions instead of going through the reflection of di.Create. The creations go
+// through the registry like the runtime ones, so decorators, post processors, fallbacks, Initializable
+// and Disposable apply, and the hot instances are shared with the creations made through the runtime
+// API, like the ones nested in the factories.
+//
+// Only the registrations made with a named factory and, as options, WithRegistry or WithToken with a
+// string literal are wired statically. The others, e.g. conditional ones or factories declared as function
+// literals, are left to the runtime API and listed in the generated file.
+package digen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const diImportPath = "github.com/pixie-sh/di-go"
+
+// Options selects the package to generate the wiring of.
+type Options struct {
+	// Dir is the directory of the package registering the providers, the current one when empty.
+	Dir string
+	// Container is the name of the generated container type, Container when empty.
+	Container string
+}
+
+// Generate returns the formatted Go source of the static wiring of the registrations found in the
+// package of opts.Dir, in that package.
+func Generate(opts Options) ([]byte, error) {
+	dir := opts.Dir
+	if len(dir) == 0 {
+		dir = "."
+	}
+
+	container := opts.Container
+	if len(container) == 0 {
+		container = "Container"
+	}
+
+	g := &generator{imports: map[string]string{}}
+	if err := g.parse(dir); err != nil {
+		return nil, err
+	}
+
+	methods := map[string]string{}
+	for _, r := range g.registrations {
+		if previous, ok := methods[r.Method]; ok {
+			return nil, fmt.Errorf("registrations at %s and %s both generate the method %s", previous, r.Position, r.Method)
+		}
+		methods[r.Method] = r.Position
+	}
+
+	std, others := g.importSpecs()
+	var out bytes.Buffer
+	if err := fileTemplate.Execute(&out, struct {
+		Package       string
+		Container     string
+		StdImports    []string
+		Imports       []string
+		Registrations []registration
+		Dynamic       []dynamic
+	}{g.pkg, container, std, others, g.registrations, g.dynamic}); err != nil {
+		return nil, fmt.Errorf("failed to render wiring: %w", err)
+	}
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format wiring: %w", err)
+	}
+
+	return source, nil
+}
+
+// registration is a Register or RegisterPair call wired statically.
+type registration struct {
+	Position string
+	Method   string
+	Type     string // type registered, as written in the source
+	Name     string // registration name, see di.TypeName and di.PairTypeName
+	Token    string
+	Factory  string
+
+	// set for RegisterPair
+	ConfigType    string
+	ConfigName    string
+	ConfigFactory string
+}
+
+// dynamic is a registration left to the runtime API.
+type dynamic struct {
+	Position string
+	Reason   string
+}
+
+type generator struct {
+	fset          *token.FileSet
+	pkg           string
+	registrations []registration
+	dynamic       []dynamic
+	imports       map[string]string // import path to the name it's imported with, empty for the package name
+}
+
+// parse collects the registrations of the non test files of dir, in file order.
+func (g *generator) parse(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+
+	slices.Sort(files)
+	g.fset = token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		parsed, err := parser.ParseFile(g.fset, file, src, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		if bytes.HasPrefix(src, []byte("// Code generated by digen.")) {
+			continue
+		}
+
+		g.pkg = parsed.Name.Name
+		g.parseFile(parsed)
+	}
+
+	if len(g.pkg) == 0 {
+		return fmt.Errorf("no Go package found in %s", dir)
+	}
+
+	return nil
+}
+
+// parseFile collects the registrations of file, made through its import of di.
+func (g *generator) parseFile(file *ast.File) {
+	imports := map[string]*ast.ImportSpec{}
+	diName := ""
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		imports[name] = spec
+		if importPath == diImportPath {
+			diName = name
+		}
+	}
+
+	if len(diName) == 0 {
+		return
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		var fun ast.Expr
+		var typeArgs []ast.Expr
+		switch index := call.Fun.(type) {
+		case *ast.IndexExpr:
+			fun, typeArgs = index.X, []ast.Expr{index.Index}
+		case *ast.IndexListExpr:
+			fun, typeArgs = index.X, index.Indices
+		default:
+			return true
+		}
+
+		selector, ok := fun.(*ast.SelectorExpr)
+		if !ok || !isIdent(selector.X, diName) {
+			return true
+		}
+
+		switch {
+		case selector.Sel.Name == "Register" && len(typeArgs) == 1 && len(call.Args) >= 1:
+			g.register(call, typeArgs, call.Args[:1], call.Args[1:], diName, imports)
+		case selector.Sel.Name == "RegisterPair" && len(typeArgs) == 2 && len(call.Args) >= 2:
+			g.register(call, typeArgs, call.Args[:2], call.Args[2:], diName, imports)
+		}
+
+		return true
+	})
+}
+
+// register records the registration made by call, statically when possible.
+func (g *generator) register(call *ast.CallExpr, typeArgs, factories, options []ast.Expr, diName string, imports map[string]*ast.ImportSpec) {
+	pos := g.fset.Position(call.Pos())
+	position := fmt.Sprintf("%s:%d", filepath.Base(pos.Filename), pos.Line)
+	leave := func(reason string) {
+		g.dynamic = append(g.dynamic, dynamic{Position: position, Reason: reason})
+	}
+
+	var names []string
+	for _, typeArg := range typeArgs {
+		name, ok := g.typeName(typeArg)
+		if !ok {
+			leave(fmt.Sprintf("type %s is not a named type", types.ExprString(typeArg)))
+			return
+		}
+		names = append(names, name)
+	}
+
+	for _, factory := range factories {
+		if _, isLiteral := factory.(*ast.FuncLit); isLiteral {
+			leave("factory is a function literal")
+			return
+		}
+
+		if !isNamed(factory) {
+			leave(fmt.Sprintf("factory %s is not a named function", types.ExprString(factory)))
+			return
+		}
+	}
+
+	tokenName := ""
+	for _, option := range options {
+		name, args := diCall(option, diName)
+		switch {
+		case name == "WithRegistry":
+		case name == "WithToken" && len(args) == 1:
+			literal, isLiteral := args[0].(*ast.BasicLit)
+			if !isLiteral || literal.Kind != token.STRING {
+				leave(fmt.Sprintf("token %s is not a string literal", types.ExprString(args[0])))
+				return
+			}
+			tokenName, _ = strconv.Unquote(literal.Value)
+		default:
+			leave(fmt.Sprintf("option %s cannot be evaluated statically", types.ExprString(option)))
+			return
+		}
+	}
+
+	for _, expr := range append(slices.Clone(typeArgs), factories...) {
+		g.useImports(expr, imports)
+	}
+
+	r := registration{
+		Position: position,
+		Method:   methodName(typeArgs[0], tokenName),
+		Type:     types.ExprString(typeArgs[0]),
+		Name:     withToken(names[0], tokenName),
+		Token:    tokenName,
+		Factory:  types.ExprString(factories[0]),
+	}
+
+	if len(typeArgs) == 2 {
+		r.Name = r.Name + ";" + withToken(names[1], tokenName)
+		r.ConfigName = withToken(names[1], tokenName) + ";" + withToken(names[0], tokenName)
+		r.ConfigType = types.ExprString(typeArgs[1])
+		r.ConfigFactory = types.ExprString(factories[1])
+	}
+
+	g.registrations = append(g.registrations, r)
+}
+
+// typeName returns the di.TypeName of the named type expr, or its pointer, refers to.
+// Types of other packages are assumed to be imported with their package name.
+func (g *generator) typeName(expr ast.Expr) (string, bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return g.pkg + "." + t.Name, true
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			return pkg.Name + "." + t.Sel.Name, true
+		}
+	}
+
+	return "", false
+}
+
+// useImports records the imports of the package qualifiers used by expr.
+func (g *generator) useImports(expr ast.Expr, imports map[string]*ast.ImportSpec) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		selector, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		if ident, ok := selector.X.(*ast.Ident); ok {
+			if spec, ok := imports[ident.Name]; ok {
+				importPath, _ := strconv.Unquote(spec.Path.Value)
+				if spec.Name != nil {
+					g.imports[importPath] = spec.Name.Name
+				} else if _, seen := g.imports[importPath]; !seen {
+					g.imports[importPath] = ""
+				}
+			}
+		}
+
+		return true
+	})
+}
+
+// importSpecs returns the import specs of the generated file, the standard library ones first.
+func (g *generator) importSpecs() ([]string, []string) {
+	std := []string{`"fmt"`}
+	others := []string{`di "` + diImportPath + `"`}
+	paths := make([]string, 0, len(g.imports))
+	for importPath := range g.imports {
+		if importPath != diImportPath && importPath != "fmt" {
+			paths = append(paths, importPath)
+		}
+	}
+
+	slices.Sort(paths)
+	for _, importPath := range paths {
+		spec := strconv.Quote(importPath)
+		if name := g.imports[importPath]; len(name) > 0 {
+			spec = name + " " + spec
+		}
+
+		if first, _, _ := strings.Cut(importPath, "/"); !strings.Contains(first, ".") {
+			std = append(std, spec)
+		} else {
+			others = append(others, spec)
+		}
+	}
+
+	return std, others
+}
+
+// methodName returns the container method of the type expr registered with token,
+// qualified by the package of types declared elsewhere.
+func methodName(expr ast.Expr, tokenName string) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	name := types.ExprString(expr)
+	if selector, ok := expr.(*ast.SelectorExpr); ok {
+		name = exported(types.ExprString(selector.X)) + selector.Sel.Name
+	}
+
+	return name + exported(tokenName)
+}
+
+// exported turns s into an exported identifier, dropping the characters invalid in identifiers
+// and upper casing the letter following them: "db.primary" is "DbPrimary".
+func exported(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+		}
+
+		b.WriteRune(r)
+		upper = false
+	}
+
+	return b.String()
+}
+
+func withToken(name, tokenName string) string {
+	if len(tokenName) == 0 {
+		return name
+	}
+
+	return tokenName + ":" + name
+}
+
+// diCall returns the name of the di function expr calls, and its arguments, empty when it's another expression.
+func diCall(expr ast.Expr, diName string) (string, []ast.Expr) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return "", nil
+	}
+
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !isIdent(selector.X, diName) {
+		return "", nil
+	}
+
+	return selector.Sel.Name, call.Args
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// isNamed reports whether expr names a function, rather than declaring or computing one.
+func isNamed(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		_, ok := t.X.(*ast.Ident)
+		return ok
+	}
+
+	return false
+}
+
+var fileTemplate = template.Must(template.New("wiring").Parse(`// Code generated by digen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- range .StdImports }}
+	{{ . }}
+{{- end }}
+{{ range .Imports }}
+	{{ . }}
+{{- end }}
+)
+{{ if .Registrations }}
+// the factories are checked against the types they're registered for at compile time
+var (
+{{- range .Registrations }}
+{{- if .ConfigType }}
+	_ di.TypedCreateInstanceHandler[{{ .Type }}, {{ .ConfigType }}] = {{ .Factory }}
+	_ di.TypedCreateInstanceNoConfigHandler[{{ .ConfigType }}] = {{ .ConfigFactory }}
+{{- else }}
+	_ di.TypedCreateInstanceNoConfigHandler[{{ .Type }}] = {{ .Factory }}
+{{- end }}
+{{- end }}
+)
+{{ end }}
+{{- if .Dynamic }}
+// Registrations left to the runtime API:
+{{- range .Dynamic }}
+//   - {{ .Position }}: {{ .Reason }}
+{{- end }}
+{{ end }}
+// {{ .Container }} creates the registrations of the package through its registry by their names,
+// sharing the instances with the runtime API.
+type {{ .Container }} struct {
+	ctx      di.Context
+	registry di.Registry
+}
+
+// New{{ .Container }} returns a {{ .Container }} creating the instances with ctx through registry,
+// the default registry when nil.
+func New{{ .Container }}(ctx di.Context, registry di.Registry) *{{ .Container }} {
+	if registry == nil {
+		registry = di.DefaultRegistry()
+	}
+
+	return &{{ .Container }}{ctx: ctx, registry: registry}
+}
+{{ range .Registrations }}
+// {{ .Method }} returns the {{ .Type }} registered at {{ .Position }}, created by {{ .Factory }}.
+func (c *{{ $.Container }}) {{ .Method }}() ({{ .Type }}, error) {
+	var zero {{ .Type }}
+	opts := &di.RegistryOpts{Registry: c.registry, InjectionToken: {{ printf "%q" .Token }}}
+{{ if .ConfigType }}
+	config, err := c.registry.CreateConfiguration(c.ctx, {{ printf "%q" .ConfigName }}, opts)
+	if err != nil {
+		return zero, err
+	}
+
+	typedConfig, ok := config.({{ .ConfigType }})
+	if !ok {
+		return zero, fmt.Errorf("configuration {{ .ConfigName }} is a %T, not a {{ .ConfigType }}", config)
+	}
+
+	instance, err := c.registry.Create(c.ctx, {{ printf "%q" .Name }}, typedConfig, opts)
+{{- else }}
+	instance, err := c.registry.Create(c.ctx, {{ printf "%q" .Name }}, struct{}{}, opts)
+{{- end }}
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := instance.({{ .Type }})
+	if !ok {
+		return zero, fmt.Errorf("{{ .Name }} is a %T, not a {{ .Type }}", instance)
+	}
+
+	return typed, nil
+}
+{{ end }}`))
@@ -0,0 +1,108 @@
+package digen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const wiringSource = `package app
+
+import (
+	"strings"
+
+	di "github.com/pixie-sh/di-go"
+)
+
+type Service struct {
+	Client *Client
+}
+
+type Client struct {
+	Addr string
+}
+
+type ClientConfig struct {
+	Addr string ` + "`json:\"addr\"`" + `
+}
+
+func (c ClientConfig) LookupNode(path string) (any, error) {
+	return di.ConfigurationNodeLookup(c, path)
+}
+
+func newService(ctx di.Context, opts *di.RegistryOpts) (*Service, error) {
+	client, err := di.CreatePair[*Client, ClientConfig](ctx, di.WithRegistry(opts.Registry))
+	return &Service{Client: client}, err
+}
+
+func newClient(ctx di.Context, opts *di.RegistryOpts, cfg ClientConfig) (*Client, error) {
+	return &Client{Addr: cfg.Addr}, nil
+}
+
+func newClientConfig(ctx di.Context, opts *di.RegistryOpts) (ClientConfig, error) {
+	return ClientConfig{Addr: "localhost"}, nil
+}
+
+func newBuilder(ctx di.Context, opts *di.RegistryOpts) (*strings.Builder, error) {
+	return &strings.Builder{}, nil
+}
+
+func Wire(registry di.Registry) error {
+	return di.Registrations(
+		di.Register[*Service](newService, di.WithRegistry(registry)),
+		di.RegisterPair[*Client, ClientConfig](newClient, newClientConfig, di.WithRegistry(registry)),
+		di.Register[*strings.Builder](newBuilder, di.WithToken("db.primary")),
+		di.Register[*Service](func(ctx di.Context, opts *di.RegistryOpts) (*Service, error) {
+			return &Service{}, nil
+		}, di.WithToken("inline")),
+		di.Register[*Service](newService, di.WithToken("eager"), di.WithEager()),
+	)
+}
+`
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wiring.go"), []byte(wiringSource), 0o600))
+
+	source, err := Generate(Options{Dir: dir})
+	require.NoError(t, err)
+
+	generated := string(source)
+	assert.Contains(t, generated, "package app")
+	assert.Contains(t, generated, "_ di.TypedCreateInstanceNoConfigHandler[*Service]         = newService")
+	assert.Contains(t, generated, "_ di.TypedCreateInstanceHandler[*Client, ClientConfig]    = newClient")
+	assert.Contains(t, generated, "_ di.TypedCreateInstanceNoConfigHandler[ClientConfig]     = newClientConfig")
+	assert.Contains(t, generated, "func NewContainer(ctx di.Context, registry di.Registry) *Container {")
+	assert.Contains(t, generated, "func (c *Container) Service() (*Service, error) {")
+	assert.Contains(t, generated, `config, err := c.registry.CreateConfiguration(c.ctx, "app.ClientConfig;app.Client", opts)`)
+	assert.Contains(t, generated, `instance, err := c.registry.Create(c.ctx, "app.Client;app.ClientConfig", typedConfig, opts)`)
+	assert.Contains(t, generated, "typed, ok := instance.(*Client)")
+	assert.NotContains(t, generated, "SetHotInstance", "creations go through the registry pipeline")
+	assert.Contains(t, generated, "func (c *Container) StringsBuilderDbPrimary() (*strings.Builder, error) {")
+	assert.Contains(t, generated, `opts := &di.RegistryOpts{Registry: c.registry, InjectionToken: "db.primary"}`)
+	assert.Contains(t, generated, `"db.primary:strings.Builder"`)
+	assert.Contains(t, generated, "wiring.go:47: factory is a function literal")
+	assert.Contains(t, generated, "wiring.go:50: option di.WithEager() cannot be evaluated statically")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "di_wiring_gen.go"), source, 0o600))
+	again, err := Generate(Options{Dir: dir, Container: "Wiring"})
+	require.NoError(t, err)
+	assert.Contains(t, string(again), "func NewWiring(ctx di.Context, registry di.Registry) *Wiring {", "generated files are ignored")
+
+	t.Run("conflicting methods", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "more.go"), []byte(`package app
+
+import di "github.com/pixie-sh/di-go"
+
+func WireMore() error {
+	return di.Register[Service](newPlainService)
+}
+`), 0o600))
+
+		_, err := Generate(Options{Dir: dir})
+		assert.ErrorContains(t, err, "registrations at more.go:6 and wiring.go:44 both generate the method Service")
+	})
+}
@@ -0,0 +1,61 @@
+// Package diotel traces the creations of a di registry with OpenTelemetry:
+//
+//	registry := di.NewRegistry(di.WithTracer(diotel.NewTracer(otel.GetTracerProvider())))
+//
+// Every Create and CreateConfiguration call opens a span, parent of the spans of the dependencies
+// its factory creates, so slow startup and lazy first request construction show up in traces.
+package diotel
+
+import (
+	di "github.com/pixie-sh/di-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer acquired from the provider.
+const InstrumentationName = "github.com/pixie-sh/di-go"
+
+// Span attributes set on every creation span.
+const (
+	TypeAttribute          = attribute.Key("di.type")
+	TokenAttribute         = attribute.Key("di.token")
+	BreadcrumbsAttribute   = attribute.Key("di.breadcrumbs")
+	ConfigurationAttribute = attribute.Key("di.configuration")
+	HotInstanceAttribute   = attribute.Key("di.hot_instance")
+	TokenFallbackAttribute = attribute.Key("di.token_fallback")
+)
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a di.Tracer opening spans with a tracer of provider.
+func NewTracer(provider trace.TracerProvider) di.Tracer {
+	return tracer{tracer: provider.Tracer(InstrumentationName)}
+}
+
+func (t tracer) StartCreate(ctx di.Context, event di.CreateEvent) (di.Context, func(event di.CreateEvent)) {
+	name := "di.Create"
+	if event.Configuration {
+		name = "di.CreateConfiguration"
+	}
+
+	spanCtx, span := t.tracer.Start(ctx.Inner(), name, trace.WithAttributes(
+		TypeAttribute.String(event.TypeName),
+		TokenAttribute.String(event.InjectionToken.String()),
+		BreadcrumbsAttribute.StringSlice(event.Breadcrumbs),
+		ConfigurationAttribute.Bool(event.Configuration),
+		TokenFallbackAttribute.Bool(event.TokenFallback),
+	))
+
+	return ctx.WithInner(spanCtx), func(event di.CreateEvent) {
+		span.SetAttributes(HotInstanceAttribute.Bool(event.HotInstance))
+		if event.Err != nil {
+			span.RecordError(event.Err)
+			span.SetStatus(codes.Error, event.Err.Error())
+		}
+
+		span.End()
+	}
+}
@@ -0,0 +1,56 @@
+package diotel
+
+import (
+	"testing"
+
+	di "github.com/pixie-sh/di-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type database struct{}
+
+type repository struct {
+	db *database
+}
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	registry := di.NewRegistry(di.WithTracer(NewTracer(provider)))
+
+	require.NoError(t, di.Register[*database](func(ctx di.Context, opts *di.RegistryOpts) (*database, error) {
+		return &database{}, nil
+	}, di.WithRegistry(registry)))
+	require.NoError(t, di.Register[*repository](func(ctx di.Context, opts *di.RegistryOpts) (*repository, error) {
+		db, err := di.Create[*database](ctx, di.WithRegistry(registry))
+		return &repository{db: db}, err
+	}, di.WithRegistry(registry), di.WithToken("users")))
+
+	_, err := di.Create[*repository](di.NewContext(), di.WithRegistry(registry), di.WithToken("users"))
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	db, repo := spans[0], spans[1]
+	assert.Equal(t, "di.Create", repo.Name())
+	assert.Equal(t, repo.SpanContext().SpanID(), db.Parent().SpanID(), "nested creations are child spans")
+	assert.Contains(t, repo.Attributes(), TypeAttribute.String(di.TypeName[*repository]("users")))
+	assert.Contains(t, repo.Attributes(), TokenAttribute.String("users"))
+	assert.Contains(t, repo.Attributes(), BreadcrumbsAttribute.StringSlice([]string{"users"}))
+	assert.Contains(t, db.Attributes(), HotInstanceAttribute.Bool(false))
+
+	_, err = di.Create[*database](di.NewContext(), di.WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Contains(t, recorder.Ended()[2].Attributes(), HotInstanceAttribute.Bool(true))
+
+	_, err = di.CreateConfiguration[di.Configuration](di.NewContext(), di.WithRegistry(registry))
+	require.Error(t, err)
+	failed := recorder.Ended()[3]
+	assert.Equal(t, "di.CreateConfiguration", failed.Name())
+	assert.Equal(t, codes.Error, failed.Status().Code)
+}
@@ -0,0 +1,105 @@
+// Package diprom exports the creation metrics of a di registry to Prometheus:
+//
+//	collector := diprom.NewCollector("myapp")
+//	prometheus.MustRegister(collector)
+//	registry := di.NewRegistry(di.WithMetrics(collector))
+//
+// Every registered type gets its creations, hot instance hits and misses, factory durations and
+// failures, so the dependencies rebuilt repeatedly stand out.
+package diprom
+
+import (
+	"strconv"
+
+	di "github.com/pixie-sh/di-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Labels set on every metric.
+const (
+	TypeLabel          = "type"
+	ConfigurationLabel = "configuration"
+	ResultLabel        = "result"
+)
+
+// Collector is a di.MetricsCollector and a prometheus.Collector.
+type Collector struct {
+	creations       *prometheus.CounterVec
+	hotInstances    *prometheus.CounterVec
+	factoryDuration *prometheus.HistogramVec
+	failures        *prometheus.CounterVec
+}
+
+var _ di.MetricsCollector = (*Collector)(nil)
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector returns a collector naming its metrics under namespace, which may be empty.
+func NewCollector(namespace string) *Collector {
+	labels := []string{TypeLabel, ConfigurationLabel}
+	return &Collector{
+		creations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "di",
+			Name:      "creations_total",
+			Help:      "Number of Create and CreateConfiguration calls per registered type.",
+		}, labels),
+		hotInstances: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "di",
+			Name:      "hot_instance_total",
+			Help:      "Number of creations served by a hot instance (result=hit) or by running the factory (result=miss).",
+		}, append(labels, ResultLabel)),
+		factoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "di",
+			Name:      "factory_duration_seconds",
+			Help:      "Duration of the creations running the factory.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "di",
+			Name:      "creation_failures_total",
+			Help:      "Number of creations returning an error.",
+		}, labels),
+	}
+}
+
+// ObserveCreate implements di.MetricsCollector.
+func (c *Collector) ObserveCreate(event di.CreateEvent) {
+	labels := prometheus.Labels{
+		TypeLabel:          event.TypeName,
+		ConfigurationLabel: strconv.FormatBool(event.Configuration),
+	}
+
+	c.creations.With(labels).Inc()
+	if event.Err != nil {
+		c.failures.With(labels).Inc()
+	}
+	if !event.HotInstance {
+		c.factoryDuration.With(labels).Observe(event.Duration.Seconds())
+	}
+
+	result := "miss"
+	if event.HotInstance {
+		result = "hit"
+	}
+	labels[ResultLabel] = result
+	c.hotInstances.With(labels).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.creations.Describe(ch)
+	c.hotInstances.Describe(ch)
+	c.factoryDuration.Describe(ch)
+	c.failures.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.creations.Collect(ch)
+	c.hotInstances.Collect(ch)
+	c.factoryDuration.Collect(ch)
+	c.failures.Collect(ch)
+}
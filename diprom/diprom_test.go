@@ -0,0 +1,39 @@
+package diprom
+
+import (
+	"testing"
+
+	di "github.com/pixie-sh/di-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type database struct{}
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector("test")
+	prometheusRegistry := prometheus.NewPedanticRegistry()
+	require.NoError(t, prometheusRegistry.Register(collector))
+	registry := di.NewRegistry(di.WithMetrics(collector))
+
+	require.NoError(t, di.Register[*database](func(ctx di.Context, opts *di.RegistryOpts) (*database, error) {
+		return &database{}, nil
+	}, di.WithRegistry(registry)))
+
+	for range 3 {
+		_, err := di.Create[*database](di.NewContext(), di.WithRegistry(registry))
+		require.NoError(t, err)
+	}
+
+	typeName := di.TypeName[*database]()
+	assert.Equal(t, 3.0, testutil.ToFloat64(collector.creations.WithLabelValues(typeName, "false")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(collector.hotInstances.WithLabelValues(typeName, "false", "hit")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(collector.hotInstances.WithLabelValues(typeName, "false", "miss")))
+	assert.Equal(t, 1, testutil.CollectAndCount(collector.factoryDuration))
+	assert.Equal(t, 0, testutil.CollectAndCount(collector.failures))
+
+	_, err := prometheusRegistry.Gather()
+	require.NoError(t, err)
+}
@@ -0,0 +1,165 @@
+// Package diremote loads di configurations from the key-value store of Consul or etcd, holding the
+// JSON document of the configuration under a key:
+//
+//	source := diremote.NewConsulSource("http://consul.internal:8500", "apps/payments/config",
+//		diremote.WithHeader("X-Consul-Token", token))
+//	cfg, err := di.NewReloadableConfiguration[AppConfig](ctx, source)
+//	cfg.OnChange(func(change di.ConfigChange) { registry.ConfigurationChanged(change.Paths...) })
+//	registry.Go(ctx, "config-watcher", func(ctx di.Context) error { return cfg.Watch(ctx, 10*time.Second) })
+//
+// The document gets the ${di.path} resolution of any di configuration, and Watch reloads it once
+// its key is modified, so fleets are reconfigured without redeploys.
+package diremote
+
+import (
+	goctx "context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	gojson "github.com/goccy/go-json"
+	di "github.com/pixie-sh/di-go"
+)
+
+// Option configures a source.
+type Option func(c *client)
+
+// WithHTTPClient sends the requests with httpClient instead of http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.http = httpClient
+	}
+}
+
+// WithHeader sets the header name on every request, e.g. X-Consul-Token or the etcd Authorization token.
+func WithHeader(name string, value string) Option {
+	return func(c *client) {
+		c.headers.Set(name, value)
+	}
+}
+
+type client struct {
+	address string
+	http    *http.Client
+	headers http.Header
+}
+
+func newClient(address string, options []Option) client {
+	c := client{address: strings.TrimSuffix(address, "/"), http: http.DefaultClient, headers: http.Header{}}
+	for _, option := range options {
+		option(&c)
+	}
+
+	return c
+}
+
+func (c client) do(ctx goctx.Context, method string, url string, body io.Reader) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range c.headers {
+		request.Header[name] = values
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("%s %s responded %s", method, url, response.Status)
+	}
+
+	return response, nil
+}
+
+// ConsulSource is a di.ConfigurationSource reading a key of the Consul KV store, versioned by its modify index.
+type ConsulSource struct {
+	client
+	key string
+}
+
+var _ di.ConfigurationSource = (*ConsulSource)(nil)
+
+// NewConsulSource returns the source of the key of the Consul agent at address.
+func NewConsulSource(address string, key string, options ...Option) *ConsulSource {
+	return &ConsulSource{client: newClient(address, options), key: strings.TrimPrefix(key, "/")}
+}
+
+func (s *ConsulSource) String() string {
+	return "consul:" + s.key
+}
+
+func (s *ConsulSource) Load(ctx goctx.Context) ([]byte, string, error) {
+	response, err := s.do(ctx, http.MethodGet, s.address+"/v1/kv/"+s.key+"?raw", nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, response.Header.Get("X-Consul-Index"), nil
+}
+
+// EtcdSource is a di.ConfigurationSource reading a key of etcd through its v3 JSON gateway,
+// versioned by its modification revision.
+type EtcdSource struct {
+	client
+	key string
+}
+
+var _ di.ConfigurationSource = (*EtcdSource)(nil)
+
+// NewEtcdSource returns the source of the key of the etcd server at address.
+func NewEtcdSource(address string, key string, options ...Option) *EtcdSource {
+	return &EtcdSource{client: newClient(address, options), key: key}
+}
+
+func (s *EtcdSource) String() string {
+	return "etcd:" + s.key
+}
+
+type etcdRangeResponse struct {
+	Kvs []struct {
+		Value       string `json:"value"`
+		ModRevision string `json:"mod_revision"`
+	} `json:"kvs"`
+}
+
+func (s *EtcdSource) Load(ctx goctx.Context) ([]byte, string, error) {
+	body, err := gojson.Marshal(map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(s.key))})
+	if err != nil {
+		return nil, "", err
+	}
+
+	response, err := s.do(ctx, http.MethodPost, s.address+"/v3/kv/range", strings.NewReader(string(body)))
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	var decoded etcdRangeResponse
+	if err = gojson.NewDecoder(response.Body).Decode(&decoded); err != nil {
+		return nil, "", err
+	}
+
+	if len(decoded.Kvs) == 0 {
+		return nil, "", fmt.Errorf("etcd key %s not found", s.key)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(decoded.Kvs[0].Value)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, decoded.Kvs[0].ModRevision, nil
+}
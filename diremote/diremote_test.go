@@ -0,0 +1,72 @@
+package diremote
+
+import (
+	goctx "context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	di "github.com/pixie-sh/di-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type remoteConfigTest struct {
+	Database struct {
+		Host string `json:"host"`
+	} `json:"database"`
+	Replica struct {
+		Host string `json:"host"`
+	} `json:"replica"`
+}
+
+func TestConsulSource(t *testing.T) {
+	var document atomic.Value
+	document.Store(`{"database": {"host": "db-1"}, "replica": ${di.database}}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/kv/apps/payments", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+		w.Header().Set("X-Consul-Index", "7")
+		_, _ = w.Write([]byte(document.Load().(string)))
+	}))
+	defer server.Close()
+
+	cfg, err := di.NewReloadableConfiguration[remoteConfigTest](goctx.Background(),
+		NewConsulSource(server.URL, "/apps/payments", WithHeader("X-Consul-Token", "secret")))
+	require.NoError(t, err)
+	assert.Equal(t, "db-1", cfg.Current().Replica.Host)
+
+	var changes []di.ConfigChange
+	cfg.OnChange(func(change di.ConfigChange) { changes = append(changes, change) })
+
+	document.Store(`{"database": {"host": "db-2"}, "replica": ${di.database}}`)
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, "db-2", cfg.Current().Replica.Host)
+	require.Len(t, changes, 1)
+	assert.Equal(t, []string{"database.host", "replica.host"}, changes[0].Paths)
+}
+
+func TestEtcdSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v3/kv/range", r.URL.Path)
+		value := base64.StdEncoding.EncodeToString([]byte(`{"database": {"host": "db-1"}}`))
+		_, _ = w.Write([]byte(`{"kvs": [{"key": "YXBw", "value": "` + value + `", "mod_revision": "42"}]}`))
+	}))
+	defer server.Close()
+
+	data, version, err := NewEtcdSource(server.URL, "/apps/payments").Load(goctx.Background())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"database": {"host": "db-1"}}`, string(data))
+	assert.Equal(t, "42", version)
+
+	missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"header": {}}`))
+	}))
+	defer missing.Close()
+
+	_, _, err = NewEtcdSource(missing.URL, "/apps/payments").Load(goctx.Background())
+	assert.ErrorContains(t, err, "etcd key /apps/payments not found")
+}
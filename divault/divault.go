@@ -0,0 +1,399 @@
+// Package divault resolves the ${secret.name} configuration references from HashiCorp Vault:
+//
+//	provider := divault.New("https://vault.internal:8200", os.Getenv("VAULT_TOKEN"))
+//	di.SetSecretProvider(provider)
+//	provider.OnRotate(divault.InvalidateOnRotate(registry))
+//	registry.Go(ctx, "vault-secrets", func(ctx di.Context) error { return provider.Watch(ctx, time.Minute) })
+//
+// A name reads the key of a KV v2 secret, "app/db#password" reading the password key of the
+// secret app/db of the kv mount. A name starting with a slash reads any Vault path instead,
+// "/database/creds/app#password" reading dynamic database credentials; their lease is renewed
+// while renewable and the credentials read again once it expires. A path is read once for all
+// its keys, so "/database/creds/app#username" and "/database/creds/app#password" come from the
+// same lease and match.
+//
+// Watch refreshes the secrets read so far, notifying the OnRotate subscribers of the ones whose
+// value changed. Providers building clients from a secret tag their instance with Tag(name),
+// so InvalidateOnRotate rebuilds them with the rotated value on their next use:
+//
+//	opts.Tag(divault.Tag("/database/creds/app#password"))
+package divault
+
+import (
+	"bytes"
+	goctx "context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	gojson "github.com/goccy/go-json"
+	di "github.com/pixie-sh/di-go"
+)
+
+// DefaultMount is the mount of the KV v2 secrets engine read by default.
+const DefaultMount = "secret"
+
+// Provider is a di.SecretProvider reading secrets from Vault, safe for concurrent use.
+type Provider struct {
+	address string
+	token   string
+	mount   string
+	client  *http.Client
+	now     func() time.Time
+
+	reading     sync.Mutex // serializes the reads of Vault, so a path isn't read, and leased, twice at once
+	mu          sync.Mutex
+	secrets     map[string]*secret // by path
+	subscribers map[int]func(name string)
+	nextID      int
+}
+
+var _ di.SecretProvider = (*Provider)(nil)
+
+// secret is a Vault path read by the provider, with its lease when it has one.
+type secret struct {
+	data      map[string]string
+	keys      []string // keys returned by Secret so far, checked for rotations
+	leaseID   string
+	renewable bool
+	expires   time.Time
+	lease     time.Duration
+}
+
+// expired reports whether the lease of s, if any, expired at now.
+func (s *secret) expired(now time.Time) bool {
+	return len(s.leaseID) > 0 && !now.Before(s.expires)
+}
+
+// Option configures a Provider.
+type Option func(p *Provider)
+
+// WithMount reads the KV v2 secrets from mount instead of DefaultMount.
+func WithMount(mount string) Option {
+	return func(p *Provider) {
+		p.mount = strings.Trim(mount, "/")
+	}
+}
+
+// WithHTTPClient sends the Vault requests with client instead of http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// New returns a provider reading secrets from the Vault server at address, authenticated by token.
+func New(address string, token string, options ...Option) *Provider {
+	p := &Provider{
+		address:     strings.TrimSuffix(address, "/"),
+		token:       token,
+		mount:       DefaultMount,
+		client:      http.DefaultClient,
+		now:         time.Now,
+		secrets:     map[string]*secret{},
+		subscribers: map[int]func(name string){},
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+// Tag returns the tag of the hot instances built from the secret name, see InvalidateOnRotate.
+func Tag(name string) string {
+	return "secret:" + name
+}
+
+// InvalidateOnRotate returns an OnRotate subscriber dropping the hot instances of registry tagged
+// with the Tag of the rotated secret, so they're rebuilt with its new value on their next use.
+// Registries not implementing di.HotInstanceEvictor keep their instances.
+func InvalidateOnRotate(registry di.Registry) func(name string) {
+	return func(name string) {
+		if evictor, ok := registry.(di.HotInstanceEvictor); ok {
+			evictor.EvictByTag(Tag(name))
+		}
+	}
+}
+
+// Secret returns the secret name, reading its path from Vault the first time and once its lease expired.
+func (p *Provider) Secret(ctx goctx.Context, name string) (string, error) {
+	path, key, ok := strings.Cut(name, "#")
+	if !ok || len(key) == 0 {
+		return "", fmt.Errorf("vault secret %s has no key, expected path#key", name)
+	}
+
+	value, found, ok := p.cached(path, key, p.now())
+	if !ok {
+		p.reading.Lock()
+		var rotated []string
+		var err error
+		if value, found, ok = p.cached(path, key, p.now()); !ok {
+			if rotated, err = p.load(ctx, path); err == nil {
+				value, found, _ = p.cached(path, key, time.Time{})
+			}
+		}
+		p.reading.Unlock()
+
+		if err != nil {
+			return "", err
+		}
+
+		p.notify(rotated)
+	}
+
+	if !found {
+		return "", fmt.Errorf("vault secret %s has no key %s", path, key)
+	}
+
+	return value, nil
+}
+
+// cached returns the key of the path read, recording it for the rotations, ok being false when
+// the path wasn't read yet or its lease expired at now, the zero time never expiring it.
+func (p *Provider) cached(path string, key string, now time.Time) (value string, found bool, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cached, ok := p.secrets[path]
+	if !ok || cached.expired(now) {
+		return "", false, false
+	}
+
+	value, found = cached.data[key]
+	if found && !slices.Contains(cached.keys, key) {
+		cached.keys = append(cached.keys, key)
+	}
+
+	return value, found, true
+}
+
+// OnRotate subscribes fn to the rotations of the secrets read, it returns the function unsubscribing it.
+func (p *Provider) OnRotate(fn func(name string)) (unsubscribe func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := p.nextID
+	p.nextID++
+	p.subscribers[id] = fn
+
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		delete(p.subscribers, id)
+	}
+}
+
+// Watch refreshes the secrets read every interval until ctx is done, see Refresh.
+func (p *Provider) Watch(ctx goctx.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if err := p.Refresh(ctx); err != nil {
+			di.Logger.With("address", p.address).Warn("divault failed to refresh secrets: %s", err)
+		}
+	}
+}
+
+// Refresh renews the leases of the paths read past half their duration, reading them again
+// when they can't be renewed anymore, and reads the paths without lease again, notifying the
+// OnRotate subscribers of the secrets whose value changed.
+func (p *Provider) Refresh(ctx goctx.Context) error {
+	p.mu.Lock()
+	paths := make([]string, 0, len(p.secrets))
+	for path := range p.secrets {
+		paths = append(paths, path)
+	}
+	p.mu.Unlock()
+	slices.Sort(paths)
+
+	var errs []error
+	var rotated []string
+	for _, path := range paths {
+		changed, err := p.refresh(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		rotated = append(rotated, changed...)
+	}
+
+	p.notify(rotated)
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to refresh %d secrets: %w", len(errs), errs[0])
+	}
+
+	return nil
+}
+
+// refresh renews or reads path again, returning the names of its secrets whose value changed.
+func (p *Provider) refresh(ctx goctx.Context, path string) ([]string, error) {
+	p.reading.Lock()
+	defer p.reading.Unlock()
+
+	p.mu.Lock()
+	current := *p.secrets[path]
+	p.mu.Unlock()
+
+	if len(current.leaseID) > 0 {
+		if p.now().Before(current.expires.Add(-current.lease / 2)) {
+			return nil, nil
+		}
+
+		if current.renewable {
+			if renewed, err := p.renew(ctx, current); err == nil {
+				p.mu.Lock()
+				leased := p.secrets[path]
+				leased.renewable, leased.lease, leased.expires = renewed.renewable, renewed.lease, renewed.expires
+				p.mu.Unlock()
+				return nil, nil
+			}
+		}
+	}
+
+	return p.load(ctx, path)
+}
+
+// load reads path from Vault, replacing the secret read before, and returns the names of the
+// secrets returned so far whose value changed. The caller holds p.reading.
+func (p *Provider) load(ctx goctx.Context, path string) ([]string, error) {
+	read, err := p.read(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var rotated []string
+	if current, ok := p.secrets[path]; ok {
+		read.keys = current.keys
+		for _, key := range current.keys {
+			if read.data[key] != current.data[key] {
+				rotated = append(rotated, path+"#"+key)
+			}
+		}
+	}
+
+	p.secrets[path] = read
+	return rotated, nil
+}
+
+// notify calls the OnRotate subscribers for each of the rotated secrets.
+func (p *Provider) notify(rotated []string) {
+	if len(rotated) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	ids := make([]int, 0, len(p.subscribers))
+	for id := range p.subscribers {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	subscribers := make([]func(name string), 0, len(ids))
+	for _, id := range ids {
+		subscribers = append(subscribers, p.subscribers[id])
+	}
+	p.mu.Unlock()
+
+	for _, name := range rotated {
+		for _, subscriber := range subscribers {
+			subscriber(name)
+		}
+	}
+}
+
+// vaultResponse is the part of the Vault responses read by the provider.
+type vaultResponse struct {
+	LeaseID       string         `json:"lease_id"`
+	LeaseDuration int            `json:"lease_duration"`
+	Renewable     bool           `json:"renewable"`
+	Data          map[string]any `json:"data"`
+}
+
+// read reads the secret at path from Vault, with all its keys.
+func (p *Provider) read(ctx goctx.Context, path string) (*secret, error) {
+	kv := !strings.HasPrefix(path, "/")
+	url := p.address + "/v1/" + strings.TrimPrefix(path, "/")
+	if kv {
+		url = p.address + "/v1/" + p.mount + "/data/" + path
+	}
+
+	var response vaultResponse
+	if err := p.do(ctx, http.MethodGet, url, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", path, err)
+	}
+
+	data := response.Data
+	if kv {
+		data, _ = response.Data["data"].(map[string]any)
+	}
+
+	read := &secret{data: make(map[string]string, len(data)), leaseID: response.LeaseID, renewable: response.Renewable}
+	for key, value := range data {
+		read.data[key] = fmt.Sprint(value)
+	}
+
+	if len(response.LeaseID) > 0 {
+		read.lease = time.Duration(response.LeaseDuration) * time.Second
+		read.expires = p.now().Add(read.lease)
+	}
+
+	return read, nil
+}
+
+// renew returns s with its lease extended by its duration.
+func (p *Provider) renew(ctx goctx.Context, s secret) (*secret, error) {
+	body, err := gojson.Marshal(map[string]any{"lease_id": s.leaseID, "increment": int(s.lease.Seconds())})
+	if err != nil {
+		return nil, err
+	}
+
+	var response vaultResponse
+	if err = p.do(ctx, http.MethodPut, p.address+"/v1/sys/leases/renew", body, &response); err != nil {
+		return nil, err
+	}
+
+	s.renewable = response.Renewable
+	s.lease = time.Duration(response.LeaseDuration) * time.Second
+	s.expires = p.now().Add(s.lease)
+	return &s, nil
+}
+
+func (p *Provider) do(ctx goctx.Context, method string, url string, body []byte, response any) error {
+	request, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("X-Vault-Token", p.token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault responded %s", resp.Status)
+	}
+
+	return gojson.NewDecoder(resp.Body).Decode(response)
+}
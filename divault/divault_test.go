@@ -0,0 +1,106 @@
+package divault
+
+import (
+	goctx "context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	di "github.com/pixie-sh/di-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dbClient struct {
+	password string
+}
+
+func TestProvider(t *testing.T) {
+	var password atomic.Value
+	password.Store("first")
+	var renewals, credentialReads atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("X-Vault-Token"))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/secret/data/app/db":
+			_, _ = w.Write([]byte(`{"data": {"data": {"password": "` + password.Load().(string) + `"}, "metadata": {"version": 1}}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/database/creds/app":
+			credentialReads.Add(1)
+			_, _ = w.Write([]byte(`{"lease_id": "database/creds/app/1", "lease_duration": 60, "renewable": true, "data": {"username": "v-app", "password": "p-` + fmt.Sprint(credentialReads.Load()) + `"}}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/sys/leases/renew":
+			renewals.Add(1)
+			_, _ = w.Write([]byte(`{"lease_id": "database/creds/app/1", "lease_duration": 60, "renewable": true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	provider := New(server.URL, "token")
+	provider.now = func() time.Time { return now }
+
+	ctx := goctx.Background()
+	secret, err := provider.Secret(ctx, "app/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "first", secret)
+
+	username, err := provider.Secret(ctx, "/database/creds/app#username")
+	require.NoError(t, err)
+	assert.Equal(t, "v-app", username)
+	credentials, err := provider.Secret(ctx, "/database/creds/app#password")
+	require.NoError(t, err)
+	assert.Equal(t, "p-1", credentials)
+	assert.Equal(t, int32(1), credentialReads.Load(), "the keys of a path come from a single lease")
+	_, err = provider.Secret(ctx, "/database/creds/app#token")
+	assert.ErrorContains(t, err, "has no key token")
+
+	_, err = provider.Secret(ctx, "app/db")
+	assert.ErrorContains(t, err, "expected path#key")
+	_, err = provider.Secret(ctx, "app/missing#password")
+	assert.ErrorContains(t, err, "404")
+
+	registry := di.NewRegistry()
+	builds := 0
+	require.NoError(t, di.Register[*dbClient](func(ctx di.Context, opts *di.RegistryOpts) (*dbClient, error) {
+		builds++
+		secret, err := provider.Secret(ctx, "app/db#password")
+		opts.Tag(Tag("app/db#password"))
+		return &dbClient{password: secret}, err
+	}, di.WithRegistry(registry)))
+
+	var rotated []string
+	provider.OnRotate(func(name string) { rotated = append(rotated, name) })
+	provider.OnRotate(InvalidateOnRotate(registry))
+
+	client, err := di.Create[*dbClient](di.NewContext(), di.WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "first", client.password)
+
+	require.NoError(t, provider.Refresh(ctx))
+	assert.Empty(t, rotated)
+	assert.Equal(t, int32(0), renewals.Load())
+
+	password.Store("second")
+	now = now.Add(45 * time.Second)
+	require.NoError(t, provider.Refresh(ctx))
+	assert.Equal(t, []string{"app/db#password"}, rotated)
+	assert.Equal(t, int32(1), renewals.Load())
+	assert.Equal(t, int32(1), credentialReads.Load())
+
+	client, err = di.Create[*dbClient](di.NewContext(), di.WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "second", client.password)
+	assert.Equal(t, 2, builds)
+
+	now = now.Add(2 * time.Minute)
+	credentials, err = provider.Secret(ctx, "/database/creds/app#password")
+	require.NoError(t, err)
+	assert.Equal(t, "p-2", credentials, "expired leases are read again without Watch")
+	assert.Contains(t, rotated, "/database/creds/app#password")
+	assert.NotContains(t, rotated, "/database/creds/app#username", "unchanged keys don't rotate")
+}
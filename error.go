@@ -0,0 +1,61 @@
+package di
+
+import (
+	goerrors "errors"
+	"slices"
+)
+
+// Error is the error returned by Create, CreateConfiguration and CreatePair when a creation fails,
+// carrying what failed as fields so callers can build alerts and metrics without parsing messages:
+//
+//	var diErr *di.Error
+//	if errors.As(err, &diErr) {
+//		metrics.CreationFailures.WithLabelValues(diErr.TypeName, string(diErr.Token)).Inc()
+//	}
+//
+// It wraps the pixie error describing the failure, its message and error code are the ones of Cause,
+// so errors.Has keeps working on it. Creations nested in a failed factory return their own Error,
+// ErrorChain lists them from the outermost creation to the innermost.
+type Error struct {
+	TypeName    string         // Name of the type whose creation failed, see TypeName
+	Token       InjectionToken // Injection token of the creation, empty when untokened
+	Breadcrumbs []string       // Breadcrumbs of the injection context of the creation
+	ConfigPath  string         // Configuration node path of the creation, where ConfigurationLookup looks it up
+	Cause       error          // Error the creation failed with
+}
+
+func (e *Error) Error() string {
+	return e.Cause.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// ErrorChain returns the Errors found in the chain of err, from the outermost creation to the innermost,
+// the last one being the creation that failed first.
+func ErrorChain(err error) []*Error {
+	var chain []*Error
+	for err != nil {
+		var diErr *Error
+		if !goerrors.As(err, &diErr) {
+			break
+		}
+
+		chain = append(chain, diErr)
+		err = diErr.Cause
+	}
+
+	return chain
+}
+
+// newCreateError returns the Error of the failed creation of typeName in ctx.
+func newCreateError(ctx Context, opts *RegistryOpts, typeName string, configPath string, cause error) error {
+	return &Error{
+		TypeName:    typeName,
+		Token:       opts.InjectionToken,
+		Breadcrumbs: slices.Clone(ctx.Breadcrumbs()),
+		ConfigPath:  configPath,
+		Cause:       cause,
+	}
+}
@@ -9,4 +9,14 @@ var (
 	DependencyMissingErrorCode       = errors.NewErrorCode("DependencyMissingErrorCode", DIErrorCodeBase+503)
 	DependencyTypeMismatchErrorCode  = errors.NewErrorCode("DependencyTypeMismatchErrorCode", DIErrorCodeBase+503)
 	StructMapTypeMismatchErrorCode   = errors.NewErrorCode("StructMapTypeMismatchErrorCode", DIErrorCodeBase+503)
+	AmbiguousDependencyErrorCode     = errors.NewErrorCode("AmbiguousDependencyErrorCode", DIErrorCodeBase+409)
+	RegistrationConflictErrorCode    = errors.NewErrorCode("RegistrationConflictErrorCode", DIErrorCodeBase+409)
+	DryRunErrorCode                  = errors.NewErrorCode("DryRunErrorCode", DIErrorCodeBase+405)
+	RequiredConfigurationErrorCode   = errors.NewErrorCode("RequiredConfigurationErrorCode", DIErrorCodeBase+422)
+	ResolutionDepthErrorCode         = errors.NewErrorCode("ResolutionDepthErrorCode", DIErrorCodeBase+508)
+	ResolutionCancelledErrorCode     = errors.NewErrorCode("ResolutionCancelledErrorCode", DIErrorCodeBase+408)
+	CreateTimeoutErrorCode           = errors.NewErrorCode("CreateTimeoutErrorCode", DIErrorCodeBase+504)
+	DisposeTimeoutErrorCode          = errors.NewErrorCode("DisposeTimeoutErrorCode", DIErrorCodeBase+504)
+	DefaultRegistryFrozenErrorCode   = errors.NewErrorCode("DefaultRegistryFrozenErrorCode", DIErrorCodeBase+423)
+	ReadOnlyRegistryErrorCode        = errors.NewErrorCode("ReadOnlyRegistryErrorCode", DIErrorCodeBase+403)
 )
@@ -0,0 +1,57 @@
+package di
+
+import (
+	goerrors "errors"
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errorServiceTest struct{}
+
+type errorDependencyTest struct{}
+
+func TestError(t *testing.T) {
+	registry := NewTestRegistry(t)
+	token := InjectionToken("errors.primary")
+	require.NoError(t, Register[errorDependencyTest](func(ctx Context, opts *RegistryOpts) (errorDependencyTest, error) {
+		return errorDependencyTest{}, errors.New("connection refused", ErrorCreatingDependencyErrorCode)
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[errorServiceTest](func(ctx Context, opts *RegistryOpts) (errorServiceTest, error) {
+		_, err := Create[errorDependencyTest](ctx, WithRegistry(opts.Registry))
+		return errorServiceTest{}, err
+	}, WithRegistry(registry), WithToken(token)))
+
+	_, err := Create[errorServiceTest](NewContext(), WithRegistry(registry), WithToken(token), WithConfigNodePath("services.primary"))
+	require.Error(t, err)
+
+	var diErr *Error
+	require.True(t, goerrors.As(err, &diErr))
+	assert.Equal(t, TypeName[errorServiceTest](token), diErr.TypeName)
+	assert.Equal(t, token, diErr.Token)
+	assert.Equal(t, []string{"errors.primary"}, diErr.Breadcrumbs)
+	assert.Equal(t, "services.primary", diErr.ConfigPath)
+	assert.Equal(t, diErr.Cause.Error(), err.Error())
+
+	_, creating := errors.Has(err, ErrorCreatingDependencyErrorCode)
+	assert.True(t, creating, "error codes are kept")
+
+	chain := ErrorChain(err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, TypeName[errorDependencyTest](), chain[1].TypeName)
+	assert.Equal(t, []string{"errors.primary"}, chain[1].Breadcrumbs)
+	assert.ErrorContains(t, chain[1].Cause, "connection refused")
+
+	t.Run("configuration", func(t *testing.T) {
+		_, err := CreateConfiguration[SimpleConfig](NewContext(), WithRegistry(registry), WithConfigNodePath("missing"))
+
+		var diErr *Error
+		require.True(t, goerrors.As(err, &diErr))
+		assert.Equal(t, TypeName[SimpleConfig](), diErr.TypeName)
+		assert.Equal(t, "missing", diErr.ConfigPath)
+		_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+		assert.True(t, isMissing)
+	})
+}
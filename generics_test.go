@@ -0,0 +1,95 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type genericUserTest struct {
+	Name string
+}
+
+type genericOrderTest struct {
+	ID int
+}
+
+type genericRepoTest[T any] struct {
+	Items []T
+	DSN   string
+}
+
+type genericRepoConfigTest[T any] struct {
+	DSN string
+}
+
+func (g genericRepoConfigTest[T]) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(g, lookupPath)
+}
+
+func TestTypeName_Generics(t *testing.T) {
+	assert.Equal(t, "di.genericRepoTest[github.com/pixie-sh/di-go.genericUserTest]", TypeName[genericRepoTest[genericUserTest]]())
+	assert.Equal(t, TypeName[genericRepoTest[genericUserTest]](), TypeName[*genericRepoTest[genericUserTest]]())
+	assert.Equal(t, "di.genericRepoTest[*github.com/pixie-sh/di-go.genericUserTest]", TypeName[genericRepoTest[*genericUserTest]]())
+	assert.Equal(t, "ro:di.genericRepoTest[github.com/pixie-sh/di-go.genericOrderTest]", TypeName[genericRepoTest[genericOrderTest]]("ro"))
+	assert.Equal(t,
+		"di.genericRepoTest[github.com/pixie-sh/di-go.genericRepoTest[github.com/pixie-sh/di-go.genericUserTest]]",
+		TypeName[genericRepoTest[genericRepoTest[genericUserTest]]](),
+	)
+
+	token, typeName, tokened := splitTokenedName(TypeName[genericRepoTest[struct {
+		A int `json:"a"`
+	}]]())
+	assert.False(t, tokened)
+	assert.Empty(t, token)
+	assert.Contains(t, typeName, `json:\"a\"`)
+}
+
+func TestRegister_Generics(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, Register[*genericRepoTest[genericUserTest]](func(ctx Context, opts *RegistryOpts) (*genericRepoTest[genericUserTest], error) {
+		return &genericRepoTest[genericUserTest]{Items: []genericUserTest{{Name: "ana"}}}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*genericRepoTest[genericOrderTest]](func(ctx Context, opts *RegistryOpts) (*genericRepoTest[genericOrderTest], error) {
+		return &genericRepoTest[genericOrderTest]{Items: []genericOrderTest{{ID: 1}, {ID: 2}}}, nil
+	}, WithRegistry(registry)))
+
+	users, err := Create[*genericRepoTest[genericUserTest]](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "ana", users.Items[0].Name)
+
+	orders, err := Create[*genericRepoTest[genericOrderTest]](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Len(t, orders.Items, 2)
+}
+
+func TestRegisterPair_Generics(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, RegisterPair[*genericRepoTest[genericUserTest], genericRepoConfigTest[genericUserTest]](
+		func(ctx Context, opts *RegistryOpts, cfg genericRepoConfigTest[genericUserTest]) (*genericRepoTest[genericUserTest], error) {
+			return &genericRepoTest[genericUserTest]{DSN: cfg.DSN}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (genericRepoConfigTest[genericUserTest], error) {
+			return genericRepoConfigTest[genericUserTest]{DSN: "users"}, nil
+		},
+		WithRegistry(registry),
+	))
+	require.NoError(t, RegisterPair[*genericRepoTest[genericUserTest], genericRepoConfigTest[genericOrderTest]](
+		func(ctx Context, opts *RegistryOpts, cfg genericRepoConfigTest[genericOrderTest]) (*genericRepoTest[genericUserTest], error) {
+			return &genericRepoTest[genericUserTest]{DSN: cfg.DSN}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (genericRepoConfigTest[genericOrderTest], error) {
+			return genericRepoConfigTest[genericOrderTest]{DSN: "orders"}, nil
+		},
+		WithRegistry(registry),
+	))
+
+	users, err := CreatePair[*genericRepoTest[genericUserTest], genericRepoConfigTest[genericUserTest]](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "users", users.DSN)
+
+	other, err := CreatePair[*genericRepoTest[genericUserTest], genericRepoConfigTest[genericOrderTest]](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "orders", other.DSN)
+}
@@ -139,3 +139,73 @@ func TestRegisterInjectionToken(t *testing.T) {
 		}
 	})
 }
+
+func TestInjectionToken_ChildAndParent(t *testing.T) {
+	db := InjectionToken("db")
+
+	replica := db.Child("replica")
+	if replica != "db.replica" {
+		t.Errorf("Child() = %v, want db.replica", replica)
+	}
+
+	if replica.Child("read") != "db.replica.read" {
+		t.Errorf("Child() = %v, want db.replica.read", replica.Child("read"))
+	}
+
+	if replica.Parent() != db {
+		t.Errorf("Parent() = %v, want db", replica.Parent())
+	}
+
+	if db.Parent() != "" {
+		t.Errorf("Parent() = %v, want empty token", db.Parent())
+	}
+
+	if InjectionToken("").Child("db") != db {
+		t.Errorf("Child() of empty token = %v, want db", InjectionToken("").Child("db"))
+	}
+
+	if _, exists := injectionTokenMap[replica]; exists {
+		t.Errorf("Child() should not register the token")
+	}
+
+	for _, invalid := range []string{"", ".replica", "replica.", "re..plica"} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Child(%q) did not panic", invalid)
+				}
+			}()
+
+			db.Child(invalid)
+		}()
+	}
+}
+
+func TestRegisterInjectionTokens(t *testing.T) {
+	injectionTokenMap = map[InjectionToken]struct{}{}
+
+	tokens := RegisterInjectionTokens("db.primary", "db.replica")
+	if len(tokens) != 2 || tokens[0] != "db.primary" || tokens[1] != "db.replica" {
+		t.Errorf("RegisterInjectionTokens() = %v, want [db.primary db.replica]", tokens)
+	}
+
+	for _, batch := range [][]string{
+		{"cache.local", "db.primary"},
+		{"cache.local", "cache..remote"},
+		{"cache.local", "cache.local"},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("RegisterInjectionTokens(%q) did not panic", batch)
+				}
+			}()
+
+			RegisterInjectionTokens(batch...)
+		}()
+
+		if _, exists := injectionTokenMap["cache.local"]; exists {
+			t.Errorf("RegisterInjectionTokens(%q) registered part of the batch", batch)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package di
+
+import (
+	"io"
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closerTest struct {
+	closed bool
+}
+
+func (c *closerTest) Close() error {
+	c.closed = true
+	return nil
+}
+
+type otherCloserTest struct{}
+
+func (o otherCloserTest) Close() error {
+	return nil
+}
+
+func Test_InterfaceUpcast(t *testing.T) {
+	t.Run("resolves unique implementation", func(t *testing.T) {
+		registry := NewRegistry()
+		require.NoError(t, Register[*closerTest](func(ctx Context, opts *RegistryOpts) (*closerTest, error) {
+			return &closerTest{}, nil
+		}, WithRegistry(registry)))
+
+		closer, err := Create[io.Closer](NewContext(), WithRegistry(registry), WithInterfaceUpcast())
+		require.NoError(t, err)
+		require.NoError(t, closer.Close())
+
+		concrete, err := Create[*closerTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.True(t, concrete.closed)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		registry := NewRegistry()
+		require.NoError(t, Register[*closerTest](func(ctx Context, opts *RegistryOpts) (*closerTest, error) {
+			return &closerTest{}, nil
+		}, WithRegistry(registry)))
+
+		_, err := Create[io.Closer](NewContext(), WithRegistry(registry))
+		require.Error(t, err)
+	})
+
+	t.Run("resolves orphan hot instance", func(t *testing.T) {
+		registry := NewRegistry()
+		require.NoError(t, registry.SetHotInstance(NewContext(), nil, "external.closer", otherCloserTest{}))
+
+		closer, err := Create[io.Closer](NewContext(), WithRegistry(registry), WithInterfaceUpcast())
+		require.NoError(t, err)
+		assert.Equal(t, otherCloserTest{}, closer)
+	})
+
+	t.Run("fails on ambiguity", func(t *testing.T) {
+		registry := NewRegistry()
+		require.NoError(t, Register[*closerTest](func(ctx Context, opts *RegistryOpts) (*closerTest, error) {
+			return &closerTest{}, nil
+		}, WithRegistry(registry)))
+		require.NoError(t, Register[otherCloserTest](func(ctx Context, opts *RegistryOpts) (otherCloserTest, error) {
+			return otherCloserTest{}, nil
+		}, WithRegistry(registry)))
+
+		_, err := Create[io.Closer](NewContext(), WithRegistry(registry), WithInterfaceUpcast())
+		require.Error(t, err)
+		_, isAmbiguous := errors.Has(err, AmbiguousDependencyErrorCode)
+		assert.True(t, isAmbiguous)
+		assert.Contains(t, err.Error(), "di.closerTest")
+		assert.Contains(t, err.Error(), "di.otherCloserTest")
+	})
+
+	t.Run("prefers token qualified implementation", func(t *testing.T) {
+		registry := NewRegistry()
+		require.NoError(t, Register[*closerTest](func(ctx Context, opts *RegistryOpts) (*closerTest, error) {
+			return &closerTest{}, nil
+		}, WithRegistry(registry), WithToken("files")))
+		require.NoError(t, Register[otherCloserTest](func(ctx Context, opts *RegistryOpts) (otherCloserTest, error) {
+			return otherCloserTest{}, nil
+		}, WithRegistry(registry)))
+
+		closer, err := Create[io.Closer](NewContext(), WithRegistry(registry), WithToken("files"), WithInterfaceUpcast())
+		require.NoError(t, err)
+		assert.IsType(t, &closerTest{}, closer)
+
+		closer, err = Create[io.Closer](NewContext(), WithRegistry(registry), WithInterfaceUpcast())
+		require.NoError(t, err)
+		assert.IsType(t, otherCloserTest{}, closer)
+	})
+
+	t.Run("ambiguity hints the token to use", func(t *testing.T) {
+		registry := NewRegistry()
+		require.NoError(t, Register[*closerTest](func(ctx Context, opts *RegistryOpts) (*closerTest, error) {
+			return &closerTest{}, nil
+		}, WithRegistry(registry), WithToken("primary")))
+		require.NoError(t, Register[otherCloserTest](func(ctx Context, opts *RegistryOpts) (otherCloserTest, error) {
+			return otherCloserTest{}, nil
+		}, WithRegistry(registry), WithToken("primary")))
+
+		_, err := Create[io.Closer](NewContext(), WithRegistry(registry), WithToken("primary"), WithInterfaceUpcast())
+		require.Error(t, err)
+		_, isAmbiguous := errors.Has(err, AmbiguousDependencyErrorCode)
+		assert.True(t, isAmbiguous)
+		assert.Contains(t, err.Error(), `WithToken("primary")`)
+	})
+}
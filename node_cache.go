@@ -0,0 +1,133 @@
+package di
+
+import (
+	"reflect"
+	"sync"
+)
+
+// NodeCache caches the nodes looked up by ConfigurationLookup, keyed by the Configuration they're
+// looked up in and their path. Plug one into a registry with WithNodeCache to bound its size,
+// instrument it, or share it between the contexts built from the same Configuration value.
+// Without one, the nodes are cached for the contexts derived from the same NewContext call.
+// Implementations must be safe for concurrent use.
+type NodeCache interface {
+	Get(config Configuration, path string) (any, bool)
+	Set(config Configuration, path string, node any)
+}
+
+// WithNodeCache makes the registry look configuration nodes up through cache.
+func WithNodeCache(cache NodeCache) RegistryOption {
+	return func(r *diRegistry) {
+		r.nodeCache = cache
+	}
+}
+
+// nodeCacheProvider is implemented by registries holding a NodeCache.
+type nodeCacheProvider interface {
+	nodeCacheOf() NodeCache
+}
+
+func (dif *diRegistry) nodeCacheOf() NodeCache {
+	return dif.nodeCache
+}
+
+type nodeCacheKey struct {
+	config Configuration
+	path   string
+}
+
+// MapNodeCache is an unbounded NodeCache. Configurations whose type isn't comparable,
+// like maps, aren't cached.
+type MapNodeCache struct {
+	nodes sync.Map
+}
+
+// NewMapNodeCache returns an empty MapNodeCache.
+func NewMapNodeCache() *MapNodeCache {
+	return &MapNodeCache{}
+}
+
+func (c *MapNodeCache) Get(config Configuration, path string) (any, bool) {
+	if !isComparable(config) {
+		return nil, false
+	}
+
+	return c.nodes.Load(nodeCacheKey{config, path})
+}
+
+func (c *MapNodeCache) Set(config Configuration, path string, node any) {
+	if isComparable(config) {
+		c.nodes.Store(nodeCacheKey{config, path}, node)
+	}
+}
+
+func isComparable(v any) bool {
+	return v != nil && reflect.ValueOf(v).Comparable()
+}
+
+// configurationNodes caches the nodes looked up in a configuration by the contexts holding it, when their
+// registry has no NodeCache. It's dropped for a new one once the configuration is reloaded, the nodes
+// being cached against the snapshot they're looked up in.
+type configurationNodes struct {
+	config Configuration
+	nodes  sync.Map
+}
+
+func (n *configurationNodes) Get(_ Configuration, path string) (any, bool) {
+	return n.nodes.Load(path)
+}
+
+func (n *configurationNodes) Set(_ Configuration, path string, node any) {
+	n.nodes.Store(path, node)
+}
+
+// nodesOf returns the nodes cached for cfg, the current snapshot of the configuration, or nil when
+// cfg cannot be cached.
+func (l *lazyRawConfiguration) nodesOf(cfg Configuration) *configurationNodes {
+	if !isComparable(cfg) {
+		return nil
+	}
+
+	if current := l.nodes.Load(); current != nil && current.config == cfg {
+		return current
+	}
+
+	fresh := &configurationNodes{config: cfg}
+	l.nodes.Store(fresh)
+	return fresh
+}
+
+// lookupNode looks path up in the context configuration, through the node cache of the registry when it has one,
+// otherwise through the nodes cached for the configuration of the context.
+func lookupNode(ctx Context, opts *RegistryOpts, path string) (any, error) {
+	cfg := ctx.Configuration()
+	if snapshotter, ok := cfg.(configurationSnapshotter); ok {
+		cfg = snapshotter.snapshot()
+	}
+
+	var cache NodeCache
+	if provider, ok := opts.Registry.(nodeCacheProvider); ok {
+		cache = provider.nodeCacheOf()
+	}
+
+	if diCtx, ok := ctx.(*context); ok && cache == nil && diCtx.lazyRawCfg != nil {
+		if nodes := diCtx.lazyRawCfg.nodesOf(cfg); nodes != nil {
+			cache = nodes
+		}
+	}
+
+	if cache == nil {
+		return cfg.LookupNode(path)
+	}
+
+	if node, ok := cache.Get(cfg, path); ok {
+		return node, nil
+	}
+
+	node, err := cfg.LookupNode(path)
+	if err == nil {
+		cache.Set(cfg, path, node)
+	}
+
+	return node, err
+}
@@ -0,0 +1,100 @@
+package di
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingConfigTest struct {
+	lookups *int
+	Port    int `json:"port"`
+}
+
+func (c countingConfigTest) LookupNode(lookupPath string) (any, error) {
+	*c.lookups++
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+type recordingNodeCacheTest struct {
+	*MapNodeCache
+	hits int
+}
+
+func (c *recordingNodeCacheTest) Get(config Configuration, path string) (any, bool) {
+	node, ok := c.MapNodeCache.Get(config, path)
+	if ok {
+		c.hits++
+	}
+
+	return node, ok
+}
+
+func TestNodeCache(t *testing.T) {
+	lookups := 0
+	cfg := countingConfigTest{lookups: &lookups, Port: 8080}
+	cache := &recordingNodeCacheTest{MapNodeCache: NewMapNodeCache()}
+	registry := NewRegistry(WithNodeCache(cache))
+
+	for range 3 {
+		port, err := ConfigurationLookup[int](NewContext(cfg), &RegistryOpts{Registry: registry, ConfigNodePath: "port"})
+		require.NoError(t, err)
+		assert.Equal(t, 8080, port)
+	}
+
+	assert.Equal(t, 1, lookups)
+	assert.Equal(t, 2, cache.hits)
+
+	other := countingConfigTest{lookups: &lookups, Port: 9090}
+	port, err := ConfigurationLookup[int](NewContext(other), &RegistryOpts{Registry: registry, ConfigNodePath: "port"})
+	require.NoError(t, err)
+	assert.Equal(t, 9090, port, "nodes are cached per configuration")
+
+	_, err = ConfigurationLookup[int](NewContext(cfg), &RegistryOpts{Registry: NewRegistry(), ConfigNodePath: "port"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, lookups, "registries without cache look nodes up every time")
+}
+
+func TestMapNodeCache_Uncomparable(t *testing.T) {
+	cache := NewMapNodeCache()
+	cfg := SimpleConfig{"port": 8080}
+
+	cache.Set(cfg, "port", 8080)
+	_, ok := cache.Get(cfg, "port")
+	assert.False(t, ok)
+}
+
+func TestContextNodeCache(t *testing.T) {
+	lookups := 0
+	ctx := NewContext(countingConfigTest{lookups: &lookups, Port: 8080})
+	registry := NewRegistry()
+
+	for _, lookupCtx := range []Context{ctx, ctx, NewContext(ctx), ctx.Clone()} {
+		port, err := ConfigurationLookup[int](lookupCtx, &RegistryOpts{Registry: registry, ConfigNodePath: "port"})
+		require.NoError(t, err)
+		assert.Equal(t, 8080, port)
+	}
+	assert.Equal(t, 1, lookups, "contexts sharing a configuration share its looked up nodes")
+
+	t.Run("reload", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"cache": {"ttl": 10}}`), 0o600))
+		cfg, err := NewFileConfiguration[fileConfigTest](path)
+		require.NoError(t, err)
+
+		ctx := NewContext(cfg)
+		ttl := func() int {
+			value, err := ConfigurationLookup[int](ctx, &RegistryOpts{Registry: registry, ConfigNodePath: "cache.ttl"})
+			require.NoError(t, err)
+			return value
+		}
+		assert.Equal(t, 10, ttl())
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"cache": {"ttl": 20}}`), 0o600))
+		require.NoError(t, cfg.Reload())
+		assert.Equal(t, 20, ttl(), "a reload drops the cached nodes")
+	})
+}
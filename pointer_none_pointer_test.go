@@ -15,11 +15,11 @@ type ValueType struct {
 // Test demonstrating the pointer vs non-pointer issue
 func Test_PointerVsNonPointerCasting(t *testing.T) {
 	// Reset registry before test
-	Instance = NewRegistry()
+	useDefaultRegistryTest(t, NewRegistry())
 
 	t.Run("Register pointer type but return non-pointer", func(t *testing.T) {
 		// Reset registry
-		Instance = NewRegistry()
+		useDefaultRegistryTest(t, NewRegistry())
 
 		// Register a pointer type (*ValueType) but the creator returns a non-pointer (ValueType)
 		err := Register[*ValueType](func(ctx Context, opts *RegistryOpts) (*ValueType, error) {
@@ -45,10 +45,10 @@ func Test_PointerVsNonPointerCasting(t *testing.T) {
 
 	t.Run("Register pointer type but return wrong type completely", func(t *testing.T) {
 		// Reset registry
-		Instance = NewRegistry()
+		useDefaultRegistryTest(t, NewRegistry())
 
 		// Register using the original implementation that doesn't check types
-		Instance.Register(TypeName[*ValueType](), func(ctx Context, opts *RegistryOpts, _ any) (any, error) {
+		DefaultRegistry().Register(TypeName[*ValueType](), func(ctx Context, opts *RegistryOpts, _ any) (any, error) {
 			// Return something completely different
 			return "not a ValueType", nil
 		}, &RegistryOpts{})
@@ -70,7 +70,7 @@ func Test_PointerVsNonPointerCasting(t *testing.T) {
 		// This test would need the fix implemented to pass
 
 		// Reset registry
-		Instance = NewRegistry()
+		useDefaultRegistryTest(t, NewRegistry())
 
 		// Implement the fix for this test
 		// We need to patch the createSingleWithToken function to handle the type conversion
@@ -0,0 +1,105 @@
+package di
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// ProfilesEnv is the environment variable read by SetActiveProfilesFromEnv when no other is given.
+const ProfilesEnv = "DI_PROFILES"
+
+var (
+	activeProfilesMu sync.RWMutex
+	activeProfiles   = map[string]struct{}{}
+)
+
+// SetActiveProfiles replaces the active profiles, usually once at startup.
+// Registrations made WithProfiles are only considered while one of their profiles is active.
+func SetActiveProfiles(profiles ...string) {
+	active := make(map[string]struct{}, len(profiles))
+	for _, profile := range profiles {
+		profile = strings.TrimSpace(profile)
+		if len(profile) > 0 {
+			active[profile] = struct{}{}
+		}
+	}
+
+	activeProfilesMu.Lock()
+	defer activeProfilesMu.Unlock()
+
+	activeProfiles = active
+}
+
+// SetActiveProfilesFromEnv sets the active profiles from a comma separated environment variable,
+// ProfilesEnv by default. An unset variable clears the active profiles.
+func SetActiveProfilesFromEnv(envKey ...string) {
+	key := ProfilesEnv
+	if len(envKey) > 0 && len(envKey[0]) > 0 {
+		key = envKey[0]
+	}
+
+	SetActiveProfiles(strings.Split(os.Getenv(key), ",")...)
+}
+
+// SetActiveProfilesFromConfig sets the active profiles from the configuration node at path,
+// either a comma separated string or a list of strings.
+func SetActiveProfilesFromConfig(ctx Context, path string) error {
+	node, err := ctx.Configuration().LookupNode(path)
+	if err != nil {
+		return err
+	}
+
+	var profiles []string
+	switch typed := node.(type) {
+	case string:
+		profiles = strings.Split(typed, ",")
+	case []string:
+		profiles = typed
+	case []any:
+		for _, item := range typed {
+			profile, ok := item.(string)
+			if !ok {
+				return errors.New("profiles node '%s' must be a string or a list of strings, got %T", path, node, ConfigurationLookupErrorCode)
+			}
+
+			profiles = append(profiles, profile)
+		}
+	default:
+		return errors.New("profiles node '%s' must be a string or a list of strings, got %T", path, node, ConfigurationLookupErrorCode)
+	}
+
+	SetActiveProfiles(profiles...)
+	return nil
+}
+
+// ActiveProfiles returns the active profiles, sorted.
+func ActiveProfiles() []string {
+	activeProfilesMu.RLock()
+	defer activeProfilesMu.RUnlock()
+
+	profiles := make([]string, 0, len(activeProfiles))
+	for profile := range activeProfiles {
+		profiles = append(profiles, profile)
+	}
+
+	sort.Strings(profiles)
+	return profiles
+}
+
+// anyProfileActive reports whether one of profiles is active.
+func anyProfileActive(profiles []string) bool {
+	activeProfilesMu.RLock()
+	defer activeProfilesMu.RUnlock()
+
+	for _, profile := range profiles {
+		if _, ok := activeProfiles[profile]; ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type transportTest interface {
+	Kind() string
+}
+
+type kindTransportTest string
+
+func (k kindTransportTest) Kind() string {
+	return string(k)
+}
+
+type profilesConfigTest struct {
+	Profiles any `json:"profiles"`
+}
+
+func (p profilesConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(p, lookupPath)
+}
+
+func restoreActiveProfiles(t *testing.T) {
+	previous := ActiveProfiles()
+	t.Cleanup(func() { SetActiveProfiles(previous...) })
+}
+
+func TestWithProfiles(t *testing.T) {
+	restoreActiveProfiles(t)
+
+	registerTransports := func(registry Registry) {
+		require.NoError(t, Register[transportTest](func(ctx Context, opts *RegistryOpts) (transportTest, error) {
+			return kindTransportTest("mock"), nil
+		}, WithRegistry(registry), WithProfiles("dev", "test")))
+		require.NoError(t, Register[transportTest](func(ctx Context, opts *RegistryOpts) (transportTest, error) {
+			return kindTransportTest("real"), nil
+		}, WithRegistry(registry)))
+	}
+
+	ctx := NewContext(nil)
+
+	t.Run("inactive profile", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		registerTransports(registry)
+
+		SetActiveProfiles("prod")
+		transport, err := Create[transportTest](ctx, WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "real", transport.Kind())
+	})
+
+	t.Run("active profile", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		registerTransports(registry)
+
+		SetActiveProfiles("test")
+		transport, err := Create[transportTest](ctx, WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "mock", transport.Kind())
+	})
+
+	t.Run("combined with condition", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, Register[transportTest](func(ctx Context, opts *RegistryOpts) (transportTest, error) {
+			return kindTransportTest("mock"), nil
+		}, WithRegistry(registry), WithProfiles("test"), WithCondition(func(Context) bool { return false })))
+
+		_, err := Create[transportTest](ctx, WithRegistry(registry))
+		assert.Error(t, err)
+	})
+}
+
+func TestSetActiveProfiles(t *testing.T) {
+	restoreActiveProfiles(t)
+
+	SetActiveProfiles("prod", " dev ", "")
+	assert.Equal(t, []string{"dev", "prod"}, ActiveProfiles())
+
+	t.Setenv(ProfilesEnv, "dev,test")
+	SetActiveProfilesFromEnv()
+	assert.Equal(t, []string{"dev", "test"}, ActiveProfiles())
+
+	t.Setenv("APP_PROFILES", "")
+	SetActiveProfilesFromEnv("APP_PROFILES")
+	assert.Empty(t, ActiveProfiles())
+
+	require.NoError(t, SetActiveProfilesFromConfig(NewContext(profilesConfigTest{Profiles: "staging"}), "profiles"))
+	assert.Equal(t, []string{"staging"}, ActiveProfiles())
+
+	require.NoError(t, SetActiveProfilesFromConfig(NewContext(profilesConfigTest{Profiles: []any{"dev", "test"}}), "profiles"))
+	assert.Equal(t, []string{"dev", "test"}, ActiveProfiles())
+
+	assert.Error(t, SetActiveProfilesFromConfig(NewContext(profilesConfigTest{Profiles: 42}), "profiles"))
+}
@@ -1,11 +1,24 @@
 package di
 
 import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/pixie-sh/errors-go"
 	"github.com/pixie-sh/logger-go/logger"
 )
 
 var Logger logger.Interface
+
+// Instance is the default registry, see DefaultRegistry.
+//
+// Deprecated: read it with DefaultRegistry and replace it with SetDefaultRegistry,
+// assigning it directly races with the resolutions reading it and ignores FreezeDefaultRegistry.
 var Instance Registry
 
 func init() {
@@ -33,17 +46,115 @@ type Registry interface {
 
 	Register(typeNameOf string, createFn func(ctx Context, opts *RegistryOpts, c any) (any, error), opts *RegistryOpts) error
 	RegisterConfiguration(typeNameOf string, createCfgFn func(ctx Context, opts *RegistryOpts) (any, error), opts *RegistryOpts) error
+}
+
+// The capabilities below are optional, so a Registry implementation only has to provide the ones it
+// supports. The registries returned by NewRegistry implement all of them, callers holding a Registry
+// assert the one they need:
+//
+//	if evictor, ok := registry.(di.HotInstanceEvictor); ok {
+//		evictor.EvictByTag("uses-db-primary")
+//	}
+
+// HotInstanceEvictor is implemented by registries able to drop their hot instances, so they're created
+// again on their next use.
+type HotInstanceEvictor interface {
+	InvalidateHotInstance(opts *RegistryOpts, name string) bool
+	ClearHotInstances()
+	EvictByTag(tag string) int
+	ConfigurationChanged(paths ...string) []string
+}
 
+// RegistryInspector is implemented by registries able to describe their registrations and wiring.
+type RegistryInspector interface {
+	IsRegistered(typeNameOf string) bool
+	IsConfigurationRegistered(typeNameOf string) bool
+	ConfigOf(instance any) (any, bool)
+	List() []RegistrationInfo
+	Validate(ctx Context) error
+	Graph() Graph
+	Metrics() []TypeMetrics
+	WarmUpPlan() []WarmUpStep
 }
 
+// RegistryComposer is implemented by registries able to remove registrations and take over the
+// registrations of another registry.
+type RegistryComposer interface {
+	Unregister(typeNameOf string, opts *RegistryOpts) error
+	Merge(other Registry, policy MergeConflictPolicy) error
+}
+
+// RegistryInterceptor is implemented by registries able to observe and alter every creation.
+type RegistryInterceptor interface {
+	AddHook(hook Hook)
+	AddPostProcessor(fn PostProcessor)
+	SetResolutionLogLevel(level logger.LogLevelEnum)
+}
+
+// RegistryLifecycle is implemented by registries able to start their eager registrations and
+// background workers, and to dispose them on shutdown.
+type RegistryLifecycle interface {
+	WarmUp(ctx Context) error
+	Go(ctx Context, name string, fn func(ctx Context) error) error
+	Ready() error
+	Shutdown(ctx Context) error
+	Workers() []WorkerStatus
+}
+
+// MergeConflictPolicy selects what RegistryComposer.Merge does when both registries hold a registration under the same name.
+type MergeConflictPolicy int
+
+const (
+	// MergeConflictError fails the whole merge, leaving the registry untouched, when any registration conflicts
+	MergeConflictError MergeConflictPolicy = iota
+	// MergeConflictOverride replaces the existing registration with the merged one, dropping its hot instances
+	MergeConflictOverride
+	// MergeConflictSkip keeps the existing registration
+	MergeConflictSkip
+)
+
+// registration holds the creator registered under a name without condition, if any,
+// and the conditional ones, evaluated in registration order before falling back to it.
 type registration struct {
-	creator CreateInstanceHandler
-	opts    *RegistryOpts
+	creator      CreateInstanceHandler
+	opts         *RegistryOpts
+	conditionals []registration
+
+	// warm creates the registered type when it's marked eager, eagerSeq keeps the registration order
+	warm          func(ctx Context) error
+	eagerSeq      uint64
+	eagerPriority int
+}
+
+// selectFor returns the first conditional registration whose condition holds for ctx,
+// falling back to the unconditional one. It's false when none applies.
+func (r registration) selectFor(ctx Context) (registration, bool) {
+	for _, conditional := range r.conditionals {
+		if conditional.opts.conditionHolds(ctx) {
+			return conditional, true
+		}
+	}
+
+	return r, r.creator != nil
 }
 
+// configurationRegistration is the configuration counterpart of registration.
 type configurationRegistration struct {
-	creator CreateConfigurationHandler
-	opts    *RegistryOpts
+	creator      CreateConfigurationHandler
+	opts         *RegistryOpts
+	conditionals []configurationRegistration
+}
+
+// selectFor returns the first conditional registration whose condition holds for ctx,
+// falling back to the unconditional one. It's false when none applies.
+func (r configurationRegistration) selectFor(ctx Context) (configurationRegistration, bool) {
+	for _, conditional := range r.conditionals {
+		if conditional.opts.conditionHolds(ctx) {
+			return conditional, true
+		}
+	}
+
+	return r, r.creator != nil
 }
 
 // diRegistry implements the Registry interface and serves as a dependency injection container.
@@ -55,63 +166,697 @@ type configurationRegistration struct {
 // and their configurations, maintaining them in separate maps for clear separation
 // of concerns and easier management.
 type diRegistry struct {
+	mu sync.RWMutex
+
 	registrations              map[string]registration
 	configurationRegistrations map[string]configurationRegistration
 	hotInstances               map[string]any
+	hotExpirations             map[string]time.Time
+	hotTags                    map[string][]string
+	configLinks                map[string]configLink
+	hotLocksMu                 sync.Mutex
+	hotLocks                   map[string]*hotLock
+	pinnedConfigs              map[string]any
+	registeredTypes            map[string]reflect.Type
+	dependencies               map[GraphEdge]GraphNodeKind
+	decorators                 map[string][]decorator
+	hooks                      []Hook
+	middlewares                []Middleware
+	createChain                CreateFunc
+	postProcessors             []PostProcessor
+	disposables                []disposable
+	retiring                   sync.WaitGroup
+	nodeCache                  NodeCache
+	tracer                     Tracer
+	metrics                    *registryMetrics
+	eagerSeq                   uint64
+	workers                    workerGroup
+	pools                      sync.Map
+
+	diagnoseGlobalInstance bool
+	strictTokens           bool
+	dryRun                 bool
+	shortNames             sync.Map
+	namer                  TypeNamer
+	namedTypes             sync.Map
+	maxResolutionDepth     int
+	disposeTimeout         time.Duration
+	log                    logger.Interface
+	logLevel               atomic.Pointer[logger.LogLevelEnum]
+}
+
+// RegistryOption configures a registry when it's created by NewRegistry,
+// unlike RegistryOpts which configure each registration and creation.
+type RegistryOption func(r *diRegistry)
+
+// WithGlobalInstanceDiagnostics enables a diagnostic mode warning whenever a resolution
+// started in this registry falls through to the global Instance, which happens when a factory
+// creates its dependencies without WithRegistry. The warning carries the type and breadcrumbs.
+func WithGlobalInstanceDiagnostics() RegistryOption {
+	return func(r *diRegistry) {
+		r.diagnoseGlobalInstance = true
+	}
+}
+
+// WithDryRun makes a registry recording the wiring without ever constructing anything: Register*
+// calls are recorded as usual while Create and CreateConfiguration fail with DryRunErrorCode.
+// It lets CI load the whole wiring of a binary and run List, Graph or Validate, which resolves
+// configurations only, without executing the side effects of the providers.
+func WithDryRun() RegistryOption {
+	return func(r *diRegistry) {
+		r.dryRun = true
+	}
+}
+
+// WithStrictTokens disables the untokened fallback of every tokened creation made with the registry,
+// as WithStrictToken does for a single creation.
+func WithStrictTokens() RegistryOption {
+	return func(r *diRegistry) {
+		r.strictTokens = true
+	}
+}
+
+// typeRecorder is implemented by registries able to remember the concrete type
+// behind a registration name. it's used to resolve interfaces by upcasting.
+type typeRecorder interface {
+	recordType(typeNameOf string, t reflect.Type)
+}
+
+// configPinner is implemented by registries able to remember the configuration
+// a hot instance was built with, exposed through RegistryInspector.ConfigOf.
+type configPinner interface {
+	pinConfig(opts *RegistryOpts, typeName string, config any)
+}
+
+// eagerRecorder is implemented by registries able to create the registrations
+// marked eager ahead of their first use, see RegistryLifecycle.WarmUp.
+type eagerRecorder interface {
+	recordEager(typeNameOf string, priority int, warm func(ctx Context) error)
+}
+
+// interfaceResolver is implemented by registries able to list the registrations
+// and hot instances assignable to a given interface type.
+type interfaceResolver interface {
+	implementationsOf(iface reflect.Type, token InjectionToken) []string
+}
+
+// hotExpirer is implemented by registries able to expire hot instances, see WithTTL.
+type hotExpirer interface {
+	expireHotInstance(ctx Context, opts *RegistryOpts, typeName string, ttl time.Duration)
+}
+
+// hotTagger is implemented by registries able to evict hot instances by tag, see RegistryOpts.Tag.
+type hotTagger interface {
+	tagHotInstance(opts *RegistryOpts, typeName string, tags []string)
+}
+
+// hotLocker is implemented by registries able to serialize the creations of a hot instance, see lockHotInstance.
+type hotLocker interface {
+	lockHotInstance(key string) (unlock func())
+}
+
+// strictTokener is implemented by registries able to disable the untokened fallback, see WithStrictTokens.
+type strictTokener interface {
+	strictToken() bool
+}
+
+func NewRegistry(options ...RegistryOption) *diRegistry {
+	r := &diRegistry{
+		registrations:              map[string]registration{},
+		configurationRegistrations: map[string]configurationRegistration{},
+		hotInstances:               map[string]any{},
+		hotExpirations:             map[string]time.Time{},
+		hotTags:                    map[string][]string{},
+		configLinks:                map[string]configLink{},
+		hotLocks:                   map[string]*hotLock{},
+		pinnedConfigs:              map[string]any{},
+		registeredTypes:            map[string]reflect.Type{},
+		dependencies:               map[GraphEdge]GraphNodeKind{},
+		decorators:                 map[string][]decorator{},
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(r)
+		}
+	}
+
+	r.createChain = chainMiddlewares(r.middlewares, r.dispatchCreate)
+	return r
+}
+
+func (dif *diRegistry) Register(typeNameOf string, createFn func(ctx Context, opts *RegistryOpts, config any) (any, error), opts *RegistryOpts) error {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	reg := dif.registrations[typeNameOf]
+	if opts.isConditional() {
+		reg.conditionals = append(reg.conditionals, registration{creator: createFn, opts: opts})
+	} else {
+		if reg.creator != nil {
+			dif.warnOverriddenLocked(typeNameOf, reg.opts, opts)
+		}
+		reg.creator, reg.opts, reg.warm = createFn, opts, nil
+	}
+
+	dif.registrations[typeNameOf] = reg
+	return nil
 }
 
-func NewRegistry() diRegistry {
-	return diRegistry{registrations: map[string]registration{}, configurationRegistrations: map[string]configurationRegistration{}, hotInstances: map[string]any{}}
+func (dif *diRegistry) RegisterConfiguration(typeNameOf string, createCfgFn func(ctx Context, opts *RegistryOpts) (any, error), opts *RegistryOpts) error {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	reg := dif.configurationRegistrations[typeNameOf]
+	if opts.isConditional() {
+		reg.conditionals = append(reg.conditionals, configurationRegistration{creator: createCfgFn, opts: opts})
+	} else {
+		if reg.creator != nil {
+			dif.warnOverriddenLocked(typeNameOf, reg.opts, opts)
+		}
+		reg.creator, reg.opts = createCfgFn, opts
+	}
+
+	dif.configurationRegistrations[typeNameOf] = reg
+	return nil
 }
 
-func (dif diRegistry) Register(typeNameOf string, createFn func(ctx Context, opts *RegistryOpts, config any) (any, error), opts *RegistryOpts) error {
-	dif.registrations[typeNameOf] = registration{creator: createFn, opts: opts}
+// Unregister removes both the instance and configuration registrations stored under typeNameOf
+// and drops every hot instance created from them, so the next Create runs a factory again.
+func (dif *diRegistry) Unregister(typeNameOf string, _ *RegistryOpts) error {
+	typeNameOf = dif.registryName(typeNameOf)
+
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	_, isRegistered := dif.registrations[typeNameOf]
+	_, isCfgRegistered := dif.configurationRegistrations[typeNameOf]
+	if !isRegistered && !isCfgRegistered {
+		return errors.New("dependency not registered: %s", typeNameOf, DependencyMissingErrorCode)
+	}
+
+	delete(dif.registrations, typeNameOf)
+	delete(dif.configurationRegistrations, typeNameOf)
+	delete(dif.registeredTypes, typeNameOf)
+	dif.dropHotInstancesLocked(typeNameOf)
+
 	return nil
 }
 
-func (dif diRegistry) RegisterConfiguration(typeNameOf string, createCfgFn func(ctx Context, opts *RegistryOpts) (any, error), opts *RegistryOpts) error {
-	dif.configurationRegistrations[typeNameOf] = configurationRegistration{creator: createCfgFn, opts: opts}
+// IsRegistered reports whether typeNameOf has a registration, the conditional ones counting whether
+// their condition holds or not; registeredFor tells whether a creation would select one.
+func (dif *diRegistry) IsRegistered(typeNameOf string) bool {
+	typeNameOf = dif.registryName(typeNameOf)
+
+	dif.mu.RLock()
+	defer dif.mu.RUnlock()
+
+	_, ok := dif.registrations[typeNameOf]
+	return ok
+}
+
+// IsConfigurationRegistered is the configuration counterpart of IsRegistered.
+func (dif *diRegistry) IsConfigurationRegistered(typeNameOf string) bool {
+	typeNameOf = dif.registryName(typeNameOf)
+
+	dif.mu.RLock()
+	defer dif.mu.RUnlock()
+
+	_, ok := dif.configurationRegistrations[typeNameOf]
+	return ok
+}
+
+func (dif *diRegistry) selectsRegistration(ctx Context, typeNameOf string) bool {
+	typeNameOf = dif.registryName(typeNameOf)
+
+	dif.mu.RLock()
+	reg, ok := dif.registrations[typeNameOf]
+	dif.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	_, ok = reg.selectFor(ctx)
+	return ok
+}
+
+func (dif *diRegistry) selectsConfigurationRegistration(ctx Context, typeNameOf string) bool {
+	typeNameOf = dif.registryName(typeNameOf)
+
+	dif.mu.RLock()
+	reg, ok := dif.configurationRegistrations[typeNameOf]
+	dif.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	_, ok = reg.selectFor(ctx)
+	return ok
+}
+
+// Merge copies the registrations of other into the registry, resolving name conflicts with policy.
+// Hot instances are not merged, they're created again by the merged registry on first use.
+// other must be a registry created by NewRegistry.
+func (dif *diRegistry) Merge(other Registry, policy MergeConflictPolicy) error {
+	source, ok := other.(*diRegistry)
+	if !ok {
+		return errors.New("cannot merge registry of type %T", other, RegistrationConflictErrorCode)
+	}
+
+	if source == dif {
+		return nil
+	}
+
+	source.mu.RLock()
+	defer source.mu.RUnlock()
+
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	var conflicts []string
+	for name, reg := range source.registrations {
+		if existing, exists := dif.registrations[name]; exists {
+			conflicts = append(conflicts, name+conflictingCallSites(existing.opts, reg.opts))
+		}
+	}
+
+	for name, reg := range source.configurationRegistrations {
+		if existing, exists := dif.configurationRegistrations[name]; exists {
+			conflicts = append(conflicts, name+conflictingCallSites(existing.opts, reg.opts))
+		}
+	}
+
+	if len(conflicts) > 0 && policy == MergeConflictError {
+		slices.Sort(conflicts)
+		return errors.New("cannot merge registries, conflicting registrations: %s", strings.Join(conflicts, ", "), RegistrationConflictErrorCode)
+	}
+
+	for name, reg := range source.registrations {
+		if _, exists := dif.registrations[name]; exists {
+			if policy == MergeConflictSkip {
+				continue
+			}
+
+			dif.dropHotInstancesLocked(name)
+		}
+
+		dif.registrations[name] = reg
+		if t, recorded := source.registeredTypes[name]; recorded {
+			dif.registeredTypes[name] = t
+		} else {
+			delete(dif.registeredTypes, name)
+		}
+	}
+
+	for name, reg := range source.configurationRegistrations {
+		if _, exists := dif.configurationRegistrations[name]; exists {
+			if policy == MergeConflictSkip {
+				continue
+			}
+
+			dif.dropHotInstancesLocked(name)
+		}
+
+		dif.configurationRegistrations[name] = reg
+	}
+
 	return nil
 }
 
-func (dif diRegistry) Create(ctx Context, typeNameOf string, config any, opts *RegistryOpts) (any, error) {
+// warnOverriddenLocked warns that the registration name made at previous is replaced by the one
+// made at next, the usual sign of two packages registering the same key.
+// The caller must hold the write lock.
+func (dif *diRegistry) warnOverriddenLocked(name string, previous, next *RegistryOpts) {
+	dif.logger().
+		With("type", name).
+		Warn("di registration '%s'%s is overridden by the one%s", name, registeredAt(previous), registeredAt(next))
+}
+
+// conflictingCallSites describes where the two registrations of a merge conflict were made.
+func conflictingCallSites(existing, merged *RegistryOpts) string {
+	if len(registeredAt(existing)) == 0 && len(registeredAt(merged)) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (%s and %s)", strings.TrimSpace(registeredAt(existing)), strings.TrimSpace(registeredAt(merged)))
+}
+
+// registeredAt returns " registered at file:line" for a registration made with opts, empty when unknown.
+func registeredAt(opts *RegistryOpts) string {
+	if opts == nil || len(opts.callSite) == 0 {
+		return ""
+	}
+
+	return " registered at " + opts.callSite
+}
+
+// wrapRegisteredAt adds the call site of the registration name to err, returned by its factory.
+func wrapRegisteredAt(err error, name string, opts *RegistryOpts) error {
+	at := registeredAt(opts)
+	if len(at) == 0 {
+		return err
+	}
+
+	return errors.Wrap(err, "%s%s failed", name, at, ErrorCreatingDependencyErrorCode)
+}
+
+// dropHotInstancesLocked removes the hot instances created from the registration name.
+// The caller must hold the write lock.
+func (dif *diRegistry) dropHotInstancesLocked(name string) {
+	for key := range dif.hotInstances {
+		if key == name || strings.HasSuffix(key, ":"+name) {
+			dif.dropHotKeyLocked(key)
+		}
+	}
+}
+
+// dropHotKeyLocked removes the hot instance stored under key along with what's recorded about it.
+// The caller must hold the write lock.
+func (dif *diRegistry) dropHotKeyLocked(key string) {
+	dif.retireDisposableLocked(key)
+	delete(dif.hotInstances, key)
+	delete(dif.hotExpirations, key)
+	delete(dif.hotTags, key)
+	delete(dif.configLinks, key)
+	delete(dif.pinnedConfigs, key)
+}
+
+// createInstance is the CreateFunc of the instance creations, wrapped by the registry middlewares.
+func (dif *diRegistry) createInstance(ctx Context, typeNameOf string, config any, opts *RegistryOpts) (_ any, err error) {
+	dif.mu.RLock()
 	reg, ok := dif.registrations[typeNameOf]
+	dif.mu.RUnlock()
+
+	ctx, done := dif.observeCreate(ctx, createEvent(typeNameOf, opts, false, ok), opts)
+	defer func() { done(err) }()
+
 	if !ok {
 		return nil, errors.New("dependency not registered: %s", typeNameOf, DependencyMissingErrorCode)
 	}
 
-	return reg.creator(ctx, opts, config)
+	if dif.dryRun {
+		return nil, errors.New("dry-run registry does not create %s", typeNameOf, DryRunErrorCode)
+	}
+
+	if err = dif.checkResolutionDepth(ctx, typeNameOf); err != nil {
+		return nil, err
+	}
+
+	if err = checkResolutionCancelled(ctx, typeNameOf); err != nil {
+		return nil, err
+	}
+
+	reg, ok = reg.selectFor(ctx)
+	if !ok {
+		return nil, errors.New("dependency not registered: %s, no registration condition holds", typeNameOf, DependencyMissingErrorCode)
+	}
+
+	memo, memoKey := resolutionMemoOf(ctx, opts), ""
+	if memo != nil {
+		memoKey = hotInstanceKey(opts, typeNameOf)
+		if instance, memoized := memo.get(memoKey); memoized {
+			return instance, nil
+		}
+	}
+
+	defer enterFactory(ctx, typeNameOf)()
+	instance, err := reg.creator(ctx, opts, config)
+	if err != nil {
+		return nil, wrapRegisteredAt(err, typeNameOf, reg.opts)
+	}
+
+	if memo != nil {
+		memo.set(memoKey, instance)
+	}
+
+	return instance, nil
 }
 
-func (dif diRegistry) CreateConfiguration(ctx Context, typeNameOf string, opts *RegistryOpts) (any, error) {
+// createConfiguration is the CreateFunc of the configuration creations, wrapped by the registry middlewares.
+func (dif *diRegistry) createConfiguration(ctx Context, typeNameOf string, opts *RegistryOpts) (_ any, err error) {
+	dif.mu.RLock()
 	reg, ok := dif.configurationRegistrations[typeNameOf]
+	dif.mu.RUnlock()
+
+	ctx, done := dif.observeCreate(ctx, createEvent(typeNameOf, opts, true, ok), opts)
+	defer func() { done(err) }()
+
 	if !ok {
 		return nil, errors.New("configuration dependency not registered: %s", typeNameOf, DependencyMissingErrorCode)
 	}
 
-	return reg.creator(ctx, opts)
-}
+	if dif.dryRun {
+		return nil, errors.New("dry-run registry does not create configuration %s", typeNameOf, DryRunErrorCode)
+	}
 
-func (dif diRegistry) GetHotInstance(ctx Context, opts *RegistryOpts, typeName string) (any, error) {
-	key := typeName
-	if opts != nil && opts.InjectionToken != "" {
-		key = opts.InjectionToken.String() + ":" + typeName
+	if err = dif.checkResolutionDepth(ctx, typeNameOf); err != nil {
+		return nil, err
+	}
+
+	if err = checkResolutionCancelled(ctx, typeNameOf); err != nil {
+		return nil, err
+	}
+
+	reg, ok = reg.selectFor(ctx)
+	if !ok {
+		return nil, errors.New("configuration dependency not registered: %s, no registration condition holds", typeNameOf, DependencyMissingErrorCode)
+	}
+
+	defer enterFactory(ctx, typeNameOf)()
+	config, err := reg.creator(ctx, opts)
+	if err != nil {
+		return nil, wrapRegisteredAt(err, typeNameOf, reg.opts)
 	}
 
+	return config, nil
+}
+
+func (dif *diRegistry) GetHotInstance(ctx Context, opts *RegistryOpts, typeName string) (any, error) {
+	key := hotInstanceKey(opts, dif.registryName(typeName))
+
+	dif.mu.RLock()
 	instance, ok := dif.hotInstances[key]
+	expiresAt, expiring := dif.hotExpirations[key]
+	dif.mu.RUnlock()
 	if !ok {
 		return nil, errors.New("no hot instance found for: %s", key, DependencyMissingErrorCode)
 	}
 
+	if expiring && !dif.now().Before(expiresAt) {
+		return nil, errors.New("hot instance expired for: %s", key, DependencyMissingErrorCode)
+	}
+
 	return instance, nil
 }
 
-func (dif diRegistry) SetHotInstance(ctx Context, opts *RegistryOpts, typeName string, instance any) error {
+func (dif *diRegistry) SetHotInstance(ctx Context, opts *RegistryOpts, typeName string, instance any) error {
+	key := hotInstanceKey(opts, dif.registryName(typeName))
+
+	dif.mu.Lock()
+	if _, replaced := dif.hotInstances[key]; replaced {
+		dif.retireDisposableLocked(key)
+	}
+	dif.hotInstances[key] = instance
+	delete(dif.hotExpirations, key)
+	delete(dif.hotTags, key)
+	delete(dif.configLinks, key)
+	dif.mu.Unlock()
+	return nil
+}
+
+// expireHotInstance makes the hot instance typeName, just set, expire after ttl.
+func (dif *diRegistry) expireHotInstance(ctx Context, opts *RegistryOpts, typeName string, ttl time.Duration) {
+	expiresAt := dif.now().Add(ttl)
+
+	dif.mu.Lock()
+	dif.hotExpirations[hotInstanceKey(opts, typeName)] = expiresAt
+	dif.mu.Unlock()
+}
+
+// hotLock serializes the creations of a hot instance key, refs counts its holders and waiters.
+type hotLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lockHotInstance locks the hot instance key, the lock is dropped once its last holder unlocks.
+func (dif *diRegistry) lockHotInstance(key string) func() {
+	dif.hotLocksMu.Lock()
+	lock, ok := dif.hotLocks[key]
+	if !ok {
+		lock = &hotLock{}
+		dif.hotLocks[key] = lock
+	}
+	lock.refs++
+	dif.hotLocksMu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		dif.hotLocksMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(dif.hotLocks, key)
+		}
+		dif.hotLocksMu.Unlock()
+	}
+}
+
+// now returns the time of the Clock registered in the registry. The Clock is read from its hot
+// instance, or created out of the creation in progress the first time, so the time based features
+// don't show up in hooks, tracers, metrics or the Graph of the types they apply to.
+func (dif *diRegistry) now() time.Time {
+	dif.mu.RLock()
+	instance := dif.hotInstances[TypeNameIn[Clock](dif)]
+	dif.mu.RUnlock()
+	if clock, ok := instance.(Clock); ok && clock != nil {
+		return clock.Now()
+	}
+
+	return ClockFor(NewContext(), WithRegistry(dif)).Now()
+}
+
+// ConfigOf returns the configuration the hot instance was built with, as it was fed to its
+// RegisterPair factory. It's false when instance is not a hot instance built from a configuration.
+func (dif *diRegistry) ConfigOf(instance any) (any, bool) {
+	dif.mu.RLock()
+	defer dif.mu.RUnlock()
+
+	for key, config := range dif.pinnedConfigs {
+		if sameInstance(dif.hotInstances[key], instance) {
+			return config, true
+		}
+	}
+
+	return nil, false
+}
+
+// ConfigChanged reports whether config differs from the configuration instance was pinned with
+// in the registry, meaning it should be rebuilt. Instances without pinned configuration are
+// always reported as changed, as are the instances of registries not implementing RegistryInspector.
+func ConfigChanged(r Registry, instance any, config any) bool {
+	inspector, ok := r.(RegistryInspector)
+	if !ok {
+		return true
+	}
+
+	pinned, ok := inspector.ConfigOf(instance)
+	if !ok {
+		return true
+	}
+
+	return !reflect.DeepEqual(pinned, config)
+}
+
+func (dif *diRegistry) pinConfig(opts *RegistryOpts, typeName string, config any) {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	dif.pinnedConfigs[hotInstanceKey(opts, typeName)] = config
+}
+
+// hotInstanceKey returns the key a hot instance of typeName is stored under,
+// prefixed by the tenant it's created for, if any.
+func hotInstanceKey(opts *RegistryOpts, typeName string) string {
 	key := typeName
 	if opts != nil && opts.InjectionToken != "" {
-		key = opts.InjectionToken.String() + ":" + typeName
+		key = opts.InjectionToken.String() + ":" + key
 	}
 
-	dif.hotInstances[key] = instance
-	return nil
-}
\ No newline at end of file
+	if opts != nil && opts.key != "" {
+		key = "#" + opts.key + ":" + key
+	}
+
+	if opts != nil && opts.Tenant != "" {
+		key = "@" + opts.Tenant.String() + ":" + key
+	}
+
+	return key
+}
+
+// sameInstance reports whether a and b are the same instance, pointers are compared by
+// address and values by equality. Values that cannot be compared are never the same.
+func sameInstance(a, b any) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+
+	return a != nil && a == b
+}
+
+func (dif *diRegistry) strictToken() bool {
+	return dif.strictTokens
+}
+
+func (dif *diRegistry) recordType(typeNameOf string, t reflect.Type) {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	dif.registeredTypes[typeNameOf] = t
+}
+
+// implementationsOf returns the registration names (and orphan hot instance keys) whose type
+// implements iface. token qualified names are returned when a token is given, falling back
+// to the untokened ones otherwise, mirroring createSingleWithToken.
+func (dif *diRegistry) implementationsOf(iface reflect.Type, token InjectionToken) []string {
+	dif.mu.RLock()
+	defer dif.mu.RUnlock()
+
+	var tokened, untokened []string
+	prefix := token.String() + ":"
+
+	for name, t := range dif.registeredTypes {
+		if _, registered := dif.registrations[name]; !registered || !t.Implements(iface) {
+			continue
+		}
+
+		base := namesIn(dif, t).name
+		if len(token) > 0 && name == prefix+base {
+			tokened = append(tokened, name)
+		} else if name == base {
+			untokened = append(untokened, name)
+		}
+	}
+
+	for name, instance := range dif.hotInstances {
+		t := reflect.TypeOf(instance)
+		if t == nil || !t.Implements(iface) || dif.isRegisteredHotKey(name) {
+			continue
+		}
+
+		if len(token) > 0 && strings.HasPrefix(name, prefix) {
+			tokened = append(tokened, name)
+		} else if _, _, isTokened := splitTokenedName(name); !isTokened {
+			untokened = append(untokened, name)
+		}
+	}
+
+	if len(tokened) > 0 {
+		slices.Sort(tokened)
+		return tokened
+	}
+
+	slices.Sort(untokened)
+	return untokened
+}
+
+// isRegisteredHotKey reports whether a hot instance key belongs to a known registration,
+// hot keys may carry the creation token in front of the registration name.
+func (dif *diRegistry) isRegisteredHotKey(key string) bool {
+	for name := range dif.registrations {
+		if key == name || strings.HasSuffix(key, ":"+name) {
+			return true
+		}
+	}
+
+	for name := range dif.configurationRegistrations {
+		if key == name || strings.HasSuffix(key, ":"+name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,55 @@
+package di
+
+import (
+	"maps"
+)
+
+// WithArgs returns a function that passes the runtime argument key, valued value, to the factory
+// of a creation, e.g. a user ID or a shard name, read with Arg:
+//
+//	di.Register[*ShardClient](func(ctx di.Context, opts *di.RegistryOpts) (*ShardClient, error) {
+//		shard, _ := di.Arg[string](opts, "shard")
+//		return newShardClient(shard)
+//	})
+//
+//	client, err := di.Create[*ShardClient](ctx, di.WithArgs("shard", "eu-1"), di.WithFreshInstance())
+//
+// The arguments don't tell hot instances apart, a creation served from a hot instance ignores them,
+// so they're paired with WithFreshInstance or CreateKeyed. The dependencies the factory creates
+// don't inherit them.
+func WithArgs(key string, value any) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		args := maps.Clone(opts.Args)
+		if args == nil {
+			args = map[string]any{}
+		}
+
+		args[key] = value
+		opts.Args = args
+	}
+}
+
+// Arg returns the runtime argument key given by WithArgs to the creation made with opts, false when
+// it's missing or not a V.
+func Arg[V any](opts *RegistryOpts, key string) (V, bool) {
+	var result V
+	if opts == nil {
+		return result, false
+	}
+
+	value, ok := opts.Args[key]
+	if !ok {
+		return result, false
+	}
+
+	return typeAssertFor[V](value, opts)
+}
+
+// ArgOr returns the runtime argument key like Arg, fallback when it's missing or not a V.
+func ArgOr[V any](opts *RegistryOpts, key string, fallback V) V {
+	if value, ok := Arg[V](opts, key); ok {
+		return value
+	}
+
+	return fallback
+}
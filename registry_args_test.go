@@ -0,0 +1,47 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type shardClientTest struct {
+	Shard  string
+	UserID int
+}
+
+func TestWithArgs(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, Register[*shardClientTest](func(ctx Context, opts *RegistryOpts) (*shardClientTest, error) {
+		shard, ok := Arg[string](opts, "shard")
+		if !ok {
+			shard = "default"
+		}
+
+		return &shardClientTest{Shard: shard, UserID: ArgOr(opts, "user_id", -1)}, nil
+	}, WithRegistry(registry)))
+
+	shared := WithArgs("shard", "eu-1")
+	client, err := Create[*shardClientTest](NewContext(), WithRegistry(registry), shared, WithArgs("user_id", 42), WithFreshInstance())
+	require.NoError(t, err)
+	assert.Equal(t, &shardClientTest{Shard: "eu-1", UserID: 42}, client)
+
+	client, err = Create[*shardClientTest](NewContext(), WithRegistry(registry), shared, WithFreshInstance())
+	require.NoError(t, err)
+	assert.Equal(t, &shardClientTest{Shard: "eu-1", UserID: -1}, client, "options reused don't carry the arguments of other creations")
+
+	client, err = Create[*shardClientTest](NewContext(), WithRegistry(registry), WithArgs("shard", 7), WithFreshInstance())
+	require.NoError(t, err)
+	assert.Equal(t, "default", client.Shard, "arguments of another type are missing")
+
+	client, err = CreateKeyed[*shardClientTest](NewContext(), "us-1", WithRegistry(registry), WithArgs("shard", "us-1"))
+	require.NoError(t, err)
+	again, err := CreateKeyed[*shardClientTest](NewContext(), "us-1", WithRegistry(registry), WithArgs("shard", "ignored"))
+	require.NoError(t, err)
+	assert.Same(t, client, again, "hot instances are served regardless of the arguments")
+
+	_, ok := Arg[string](nil, "shard")
+	assert.False(t, ok)
+}
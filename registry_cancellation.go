@@ -0,0 +1,21 @@
+package di
+
+import (
+	"github.com/pixie-sh/errors-go"
+)
+
+// checkResolutionCancelled fails the creation of typeName once the inner context of ctx is done,
+// so the resolution of a cancelled request stops at the next dependency instead of building the rest
+// of its tree. The context error is wrapped, errors.Is(err, context.Canceled) holds on the result.
+func checkResolutionCancelled(ctx Context, typeName string) error {
+	inner := ctx.Inner()
+	if inner == nil {
+		return nil
+	}
+
+	if err := inner.Err(); err != nil {
+		return errors.Wrap(err, "resolution of %s aborted, context done", typeName, ResolutionCancelledErrorCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package di
+
+import (
+	goctx "context"
+	goerrors "errors"
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cancelRootTest struct{}
+
+type cancelMiddleTest struct{}
+
+type cancelLeafTest struct{}
+
+func TestResolutionCancellation(t *testing.T) {
+	registry := NewTestRegistry(t)
+	inner, cancel := goctx.WithCancel(goctx.Background())
+	leaves := 0
+
+	require.NoError(t, Register[cancelLeafTest](func(ctx Context, opts *RegistryOpts) (cancelLeafTest, error) {
+		leaves++
+		return cancelLeafTest{}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[cancelMiddleTest](func(ctx Context, opts *RegistryOpts) (cancelMiddleTest, error) {
+		cancel()
+		_, err := Create[cancelLeafTest](ctx, WithRegistry(opts.Registry))
+		return cancelMiddleTest{}, err
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[cancelRootTest](func(ctx Context, opts *RegistryOpts) (cancelRootTest, error) {
+		_, err := Create[cancelMiddleTest](ctx, WithRegistry(opts.Registry))
+		return cancelRootTest{}, err
+	}, WithRegistry(registry)))
+
+	_, err := Create[cancelRootTest](NewContext(inner), WithRegistry(registry))
+	require.Error(t, err)
+	assert.True(t, goerrors.Is(err, goctx.Canceled))
+	_, cancelled := errors.Has(err, ResolutionCancelledErrorCode)
+	assert.True(t, cancelled)
+	assert.ErrorContains(t, err, "resolution of di.cancelLeafTest aborted, context done")
+	assert.Equal(t, 0, leaves, "the dependencies after the cancellation aren't built")
+
+	_, err = CreateConfiguration[SimpleConfig](NewContext(inner), WithRegistry(registry))
+	_, cancelled = errors.Has(err, ResolutionCancelledErrorCode)
+	assert.False(t, cancelled, "missing registrations are reported as such")
+}
@@ -0,0 +1,97 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type storageConfigTest struct {
+	Storage struct {
+		Driver string `json:"driver"`
+	} `json:"storage"`
+}
+
+func (s storageConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(s, lookupPath)
+}
+
+type storageBackendTest interface {
+	Name() string
+}
+
+type namedStorageTest string
+
+func (n namedStorageTest) Name() string {
+	return string(n)
+}
+
+func storageDriverIs(driver string) func(ctx Context) bool {
+	return func(ctx Context) bool {
+		node, err := ctx.Configuration().LookupNode("storage.driver")
+		return err == nil && node == driver
+	}
+}
+
+func storageContext(driver string) Context {
+	cfg := storageConfigTest{}
+	cfg.Storage.Driver = driver
+	return NewContext(cfg)
+}
+
+func TestWithCondition(t *testing.T) {
+	registerBackends := func(registry Registry) {
+		require.NoError(t, Register[storageBackendTest](func(ctx Context, opts *RegistryOpts) (storageBackendTest, error) {
+			return namedStorageTest("s3"), nil
+		}, WithRegistry(registry), WithCondition(storageDriverIs("s3"))))
+		require.NoError(t, Register[storageBackendTest](func(ctx Context, opts *RegistryOpts) (storageBackendTest, error) {
+			return namedStorageTest("gcs"), nil
+		}, WithRegistry(registry), WithCondition(storageDriverIs("gcs"))))
+	}
+
+	t.Run("first matching condition", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		registerBackends(registry)
+
+		storage, err := Create[storageBackendTest](storageContext("gcs"), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "gcs", storage.Name())
+	})
+
+	t.Run("falls back to unconditional registration", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, Register[storageBackendTest](func(ctx Context, opts *RegistryOpts) (storageBackendTest, error) {
+			return namedStorageTest("local"), nil
+		}, WithRegistry(registry)))
+		registerBackends(registry)
+
+		storage, err := Create[storageBackendTest](storageContext("disk"), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "local", storage.Name())
+	})
+
+	t.Run("falls through to untokened registration", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, Register[storageBackendTest](func(ctx Context, opts *RegistryOpts) (storageBackendTest, error) {
+			return namedStorageTest("s3"), nil
+		}, WithRegistry(registry), WithCondition(storageDriverIs("s3")), WithToken("archive")))
+		require.NoError(t, Register[storageBackendTest](func(ctx Context, opts *RegistryOpts) (storageBackendTest, error) {
+			return namedStorageTest("local"), nil
+		}, WithRegistry(registry)))
+
+		storage, err := Create[storageBackendTest](storageContext("disk"), WithRegistry(registry), WithToken("archive"))
+		require.NoError(t, err)
+		assert.Equal(t, "local", storage.Name())
+	})
+
+	t.Run("missing when no condition holds", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		registerBackends(registry)
+
+		_, err := Create[storageBackendTest](storageContext("disk"), WithRegistry(registry))
+		_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+		assert.True(t, isMissing)
+	})
+}
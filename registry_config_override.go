@@ -0,0 +1,29 @@
+package di
+
+import (
+	"github.com/pixie-sh/errors-go"
+)
+
+// WithConfigOverride returns a function that makes a CreatePair call feed value straight into the
+// pair factory as its configuration, bypassing CreateConfiguration and the registered lookup path,
+// e.g. so tests and CLIs construct a component with an inline configuration:
+//
+//	client, err := di.CreatePair[*Client, ClientConfig](ctx, di.WithConfigOverride(ClientConfig{Addr: "localhost:8080"}))
+//
+// value must be a CT, or convertible to it with WithConvertibleTypes. The instance created is neither
+// served from nor kept as the hot instance of the pair, so every call creates a new one.
+func WithConfigOverride(value any) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.configOverride = value
+	}
+}
+
+// overriddenConfig returns the configuration override of opts as CT, see WithConfigOverride.
+func overriddenConfig[CT any](opts *RegistryOpts, typeName string) (CT, error) {
+	config, ok := typeAssertFor[CT](opts.configOverride, opts)
+	if !ok {
+		return config, errors.New("configuration override of %s is a %T, not a %s", typeName, opts.configOverride, TypeNameIn[CT](opts.Registry), DependencyTypeMismatchErrorCode)
+	}
+
+	return config, nil
+}
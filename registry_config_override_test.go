@@ -0,0 +1,55 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type overrideClientTest struct {
+	Addr string
+}
+
+type overrideClientConfigTest struct {
+	Addr string `json:"addr"`
+}
+
+func (c overrideClientConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+func TestWithConfigOverride(t *testing.T) {
+	registry := NewTestRegistry(t)
+	lookups := 0
+
+	require.NoError(t, RegisterPair[*overrideClientTest, overrideClientConfigTest](
+		func(ctx Context, opts *RegistryOpts, config overrideClientConfigTest) (*overrideClientTest, error) {
+			return &overrideClientTest{Addr: config.Addr}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (overrideClientConfigTest, error) {
+			lookups++
+			return overrideClientConfigTest{Addr: "registered:8080"}, nil
+		},
+		WithRegistry(registry),
+	))
+
+	registered, err := CreatePair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "registered:8080", registered.Addr)
+	assert.Equal(t, 1, lookups)
+
+	inline, err := CreatePair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithConfigOverride(overrideClientConfigTest{Addr: "inline:9090"}))
+	require.NoError(t, err)
+	assert.Equal(t, "inline:9090", inline.Addr)
+	assert.Equal(t, 1, lookups, "the override bypasses CreateConfiguration")
+
+	again, err := CreatePair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, registered, again, "the overridden instance is not kept as hot instance")
+
+	_, err = CreatePair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithConfigOverride("inline:9090"))
+	_, isMismatch := errors.Has(err, DependencyTypeMismatchErrorCode)
+	assert.True(t, isMismatch)
+}
@@ -1,10 +1,11 @@
 package di
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/pixie-sh/errors-go"
-	"github.com/pixie-sh/logger-go/logger"
 )
 
 // Create creates a new instance of type T using the provided context and options.
@@ -12,7 +13,7 @@ import (
 // The options parameter allows customization of the registry options during creation.
 func Create[T any](ctx Context, options ...func(opts *RegistryOpts)) (T, error) {
 	registryOpts := RegistryOpts{
-		Registry:       Instance,
+		Registry:       DefaultRegistry(),
 		InjectionToken: "",
 	}
 
@@ -22,15 +23,67 @@ func Create[T any](ctx Context, options ...func(opts *RegistryOpts)) (T, error)
 		}
 	}
 
-	injectionCtx := ctx.Clone()
+	if instance, overridden := overrideOf[T](ctx, &registryOpts); overridden {
+		return instance, nil
+	}
+
+	injectionCtx := creationContext(ctx, &registryOpts)
+	trackRootRegistry(injectionCtx, &registryOpts, TypeNameIn[T](registryOpts.Registry))
+	trackDependency(injectionCtx, &registryOpts, GraphNode{TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), GraphInstance})
+
+	if log := loggerFor(&registryOpts); debugEnabled(log) {
+		log = log.
+			With("type", TypeNameIn[T](registryOpts.Registry)).
+			With("token", registryOpts.InjectionToken)
+
+		if !IsNilOrEmpty(registryOpts.ConfigNode) {
+			log.Debug("di using config node not injection ctx '%+v'", injectionCtx)
+		} else {
+			log.Debug("di using config node from injection ctx")
+		}
+
+		log.With("breadcrumbs", injectionCtx.Breadcrumbs()).Debug("di appending breadcrumb")
+	}
+
+	var (
+		instance T
+		err      error
+	)
+	if timeoutApplies(injectionCtx, &registryOpts) {
+		instance, err = createWithTimeout(injectionCtx, &registryOpts, TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), createSingleWithToken[T])
+	} else {
+		instance, err = createSingleWithToken[T](injectionCtx, &registryOpts)
+	}
+	if err != nil {
+		configPath, _ := assembleConfigurationLookupPath(injectionCtx, &registryOpts)
+		return instance, newCreateError(injectionCtx, &registryOpts, TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), configPath, err)
+	}
 
-	log := logger.Clone().
-		With("type", TypeName[T]()).
-		With("token", registryOpts.InjectionToken)
+	return instance, nil
+}
+
+// creationContext returns the context a Create call with opts resolves with, see newInjectionContext,
+// carrying the tenant of the creation, see resolveTenant.
+func creationContext(ctx Context, opts *RegistryOpts) Context {
+	return resolveTenant(newInjectionContext(ctx, opts), opts)
+}
+
+// pairCreationContext is the CreatePair counterpart of creationContext, the config node of opts
+// being left to the configuration of the pair.
+func pairCreationContext(ctx Context, opts *RegistryOpts) Context {
+	injectionCtx := resolveTenant(ctx.Clone(), opts)
+	injectionCtx.AppendBreadcrumb(opts.InjectionToken)
+	return injectionCtx
+}
+
+// newInjectionContext clones ctx for a Create call, scoping it to opts.ConfigNode when set
+// and appending the injection token to its breadcrumbs.
+func newInjectionContext(ctx Context, opts *RegistryOpts) Context {
+	injectionCtx := ctx.Clone()
 
 	//if config node is provided, we use it instead of the one from ctx
-	if !IsNilOrEmpty(registryOpts.ConfigNode) {
-		injectionCtx.ScopedConfiguration(registryOpts.ConfigNode)
+	if !IsNilOrEmpty(opts.ConfigNode) {
+		injectionCtx.ScopedConfiguration(opts.ConfigNode)
 
 		//if original ctx is already scoped we need to reset the breadcrumbs
 		//so the new base for finding node is reset as well
@@ -38,15 +91,71 @@ func Create[T any](ctx Context, options ...func(opts *RegistryOpts)) (T, error)
 			injectionCtx.ClearBreadcrumbs()
 			injectionCtx.ClearScoped()
 		}
+	}
 
-		log.Debug("di using config node not injection ctx '%+v'", injectionCtx)
-	} else {
-		log.Debug("di using config node from injection ctx")
+	injectionCtx.AppendBreadcrumb(opts.InjectionToken)
+	return injectionCtx
+}
+
+// trackRootRegistry records the registry a resolution tree starts with in the injection context.
+// Deeper in the tree, it warns when the root registry has global instance diagnostics enabled
+// and the creation resolves through the global Instance instead.
+func trackRootRegistry(ctx Context, opts *RegistryOpts, typeName string) {
+	diCtx, ok := ctx.(*context)
+	if !ok {
+		return
+	}
+
+	if diCtx.rootRegistry == nil {
+		diCtx.rootRegistry = opts.Registry
+		return
 	}
 
-	injectionCtx.AppendBreadcrumb(registryOpts.InjectionToken)
-	log.With("breadcrumbs", injectionCtx.Breadcrumbs()).Debug("di appending breadcrumb")
-	return createSingleWithToken[T](injectionCtx, &registryOpts)
+	root, ok := diCtx.rootRegistry.(*diRegistry)
+	global := DefaultRegistry()
+	if !ok || !root.diagnoseGlobalInstance || opts.Registry != global || root == global {
+		return
+	}
+
+	root.logger().
+		With("type", typeName).
+		With("token", opts.InjectionToken).
+		With("breadcrumbs", ctx.Breadcrumbs()).
+		Warn("di resolution rooted in a custom registry fell through to the global Instance creating '%s' with breadcrumbs '%s'", typeName, ctx.Breadcrumbs())
+}
+
+// trackDependency records in the registry resolving node that the type created by the enclosing
+// factory depends on it, then marks node as the type being created by the injection context,
+// one level deeper than its parent, see CreateInfo.
+func trackDependency(ctx Context, opts *RegistryOpts, node GraphNode) {
+	diCtx, ok := ctx.(*context)
+	if !ok {
+		return
+	}
+
+	if len(diCtx.resolving) > 0 {
+		if recorder, ok := opts.Registry.(dependencyRecorder); ok {
+			recorder.recordDependency(diCtx.resolving, node)
+		}
+
+		diCtx.parent = diCtx.resolving
+		diCtx.depth++
+	}
+
+	diCtx.resolving = node.Name
+	diCtx.link = chainLink{name: node.Name, prev: diCtx.chain}
+	diCtx.chain = &diCtx.link
+}
+
+// isDependencyMissing reports whether err is a DependencyMissingErrorCode error, without
+// the allocation errors.Has makes on the nil error of a successful creation.
+func isDependencyMissing(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+	return isMissing
 }
 
 // CreateConfiguration creates a new configuration instance of type T.
@@ -54,7 +163,7 @@ func Create[T any](ctx Context, options ...func(opts *RegistryOpts)) (T, error)
 // Returns the created configuration instance and any error that occurred during creation.
 func CreateConfiguration[T any](ctx Context, options ...func(opts *RegistryOpts)) (T, error) {
 	registryOpts := RegistryOpts{
-		Registry:       Instance,
+		Registry:       DefaultRegistry(),
 		InjectionToken: "",
 	}
 
@@ -64,8 +173,24 @@ func CreateConfiguration[T any](ctx Context, options ...func(opts *RegistryOpts)
 		}
 	}
 
-	injectionCtx := ctx.Clone()
-	return createSingleConfigurationWithToken[T](injectionCtx, &registryOpts)
+	injectionCtx := resolveTenant(ctx.Clone(), &registryOpts)
+	trackRootRegistry(injectionCtx, &registryOpts, TypeNameIn[T](registryOpts.Registry))
+	trackDependency(injectionCtx, &registryOpts, GraphNode{TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), GraphConfiguration})
+	var (
+		config T
+		err    error
+	)
+	if timeoutApplies(injectionCtx, &registryOpts) {
+		config, err = createWithTimeout(injectionCtx, &registryOpts, TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), createSingleConfigurationWithToken[T])
+	} else {
+		config, err = createSingleConfigurationWithToken[T](injectionCtx, &registryOpts)
+	}
+	if err != nil {
+		configPath, _ := assembleConfigurationLookupPath(injectionCtx, &registryOpts)
+		return config, newCreateError(injectionCtx, &registryOpts, TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), configPath, err)
+	}
+
+	return config, nil
 }
 
 // CreatePair creates a pair of instances where T is the main type and CT is the configuration type.
@@ -73,7 +198,7 @@ func CreateConfiguration[T any](ctx Context, options ...func(opts *RegistryOpts)
 // Returns an instance of type T and any error that occurred during creation.
 func CreatePair[T any, CT any](ctx Context, options ...func(opts *RegistryOpts)) (T, error) {
 	registryOpts := RegistryOpts{
-		Registry:       Instance,
+		Registry:       DefaultRegistry(),
 		InjectionToken: "",
 	}
 
@@ -83,9 +208,28 @@ func CreatePair[T any, CT any](ctx Context, options ...func(opts *RegistryOpts))
 		}
 	}
 
-	injectionCtx := ctx.Clone()
-	injectionCtx.AppendBreadcrumb(registryOpts.InjectionToken)
-	return createPairWithToken[T, CT](injectionCtx, &registryOpts)
+	if instance, overridden := overrideOf[T](ctx, &registryOpts); overridden {
+		return instance, nil
+	}
+
+	injectionCtx := pairCreationContext(ctx, &registryOpts)
+	trackRootRegistry(injectionCtx, &registryOpts, TypeNameIn[T](registryOpts.Registry))
+	trackDependency(injectionCtx, &registryOpts, GraphNode{TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), GraphInstance})
+	var (
+		instance T
+		err      error
+	)
+	if timeoutApplies(injectionCtx, &registryOpts) {
+		instance, err = createWithTimeout(injectionCtx, &registryOpts, TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), createPairWithToken[T, CT])
+	} else {
+		instance, err = createPairWithToken[T, CT](injectionCtx, &registryOpts)
+	}
+	if err != nil {
+		configPath, _ := assembleConfigurationLookupPath(injectionCtx, &registryOpts)
+		return instance, newCreateError(injectionCtx, &registryOpts, TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), configPath, err)
+	}
+
+	return instance, nil
 }
 
 // createPairWithToken is an internal function that creates a pair of instances using a specific token.
@@ -94,7 +238,7 @@ func CreatePair[T any, CT any](ctx Context, options ...func(opts *RegistryOpts))
 // Returns the created instance of type T and any error that occurred.
 func createPairWithToken[T any, CT any | NoConfig](ctx Context, opts *RegistryOpts) (T, error) {
 	var (
-		f               = Instance
+		f               = DefaultRegistry()
 		typedInstance   T
 		ct              CT
 		unknownInstance any
@@ -108,13 +252,18 @@ func createPairWithToken[T any, CT any | NoConfig](ctx Context, opts *RegistryOp
 		f = opts.Registry
 	}
 
-	ctType := TypeName[CT](token)
-	tType := TypeName[T](token)
+	ctType := TypeNameIn[CT](f, token)
+	tType := TypeNameIn[T](f, token)
 
 	inputCTType := reflect.TypeOf(ct)
 	noConfigType := reflect.TypeOf(NoConfig{})
 	noConfigTypePtr := reflect.TypeOf(&NoConfig{})
-	if inputCTType != noConfigType && inputCTType != noConfigTypePtr {
+	if opts.configOverride != nil {
+		ct, err = overriddenConfig[CT](opts, PairTypeName(tType, ctType))
+		if err != nil {
+			return typedInstance, err
+		}
+	} else if inputCTType != noConfigType && inputCTType != noConfigTypePtr {
 		typeName := PairTypeName(ctType, tType)
 		unknownConfig, err = f.CreateConfiguration(ctx, typeName, opts)
 		if err != nil {
@@ -145,7 +294,7 @@ func createPairWithToken[T any, CT any | NoConfig](ctx Context, opts *RegistryOp
 // Returns the created instance and any error that occurred during creation.
 func createSingleWithToken[T any](ctx Context, opts *RegistryOpts) (T, error) {
 	var (
-		f               = Instance
+		f               = DefaultRegistry()
 		typedInstance   T
 		noopCfg         = struct{}{}
 		unknownInstance any
@@ -158,10 +307,10 @@ func createSingleWithToken[T any](ctx Context, opts *RegistryOpts) (T, error) {
 		f = opts.Registry
 	}
 
-	tType := TypeName[T](token)
+	tType := TypeNameIn[T](f, token)
 
 	unknownInstance, err = f.Create(ctx, tType, noopCfg, opts)
-	_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+	isMissing := isDependencyMissing(err)
 	if err != nil && !isMissing {
 		return typedInstance, errors.Wrap(
 			err,
@@ -173,10 +322,26 @@ func createSingleWithToken[T any](ctx Context, opts *RegistryOpts) (T, error) {
 		)
 	}
 
+	if isMissing && tokenFallbackDisabled(f, opts) {
+		return typedInstance, errors.Wrap(
+			err,
+			"dependency of type '%s' not registered with token '%s' and untokened fallback disabled, with breadcrumbs '%s'",
+			tType,
+			token,
+			ctx.Breadcrumbs(),
+			DependencyMissingErrorCode,
+		)
+	}
+
 	if isMissing {
 		var secErr error
-		tType = TypeName[T]()
+		tType = TypeNameIn[T](f)
 		unknownInstance, secErr = f.Create(ctx, tType, noopCfg, opts)
+		_, isMissing = errors.Has(secErr, DependencyMissingErrorCode)
+		if isMissing && opts.InterfaceUpcast {
+			unknownInstance, tType, secErr = createByUpcast[T](ctx, f, opts)
+		}
+
 		if secErr != nil {
 			return typedInstance, errors.Wrap(
 				secErr,
@@ -186,10 +351,12 @@ func createSingleWithToken[T any](ctx Context, opts *RegistryOpts) (T, error) {
 				ErrorCreatingDependencyErrorCode,
 			).WithNestedError(err)
 		}
+
+		logTokenFallback(ctx, opts, tType)
 	}
 
 	// Try direct type assertion first
-	typedInstance, ok = SafeTypeAssert[T](unknownInstance)
+	typedInstance, ok = typeAssertFor[T](unknownInstance, opts)
 	if !ok {
 		panic(errors.New("failed to cast dependency to expected type '%s'", tType, DependencyTypeMismatchErrorCode))
 	}
@@ -197,12 +364,109 @@ func createSingleWithToken[T any](ctx Context, opts *RegistryOpts) (T, error) {
 	return typedInstance, nil
 }
 
+// tokenFallbackDisabled reports whether a tokened creation missing its registration fails instead
+// of retrying without the token, either through WithStrictToken or the registry WithStrictTokens.
+func tokenFallbackDisabled(f Registry, opts *RegistryOpts) bool {
+	if len(opts.InjectionToken) == 0 {
+		return false
+	}
+
+	if opts.StrictToken {
+		return true
+	}
+
+	strict, ok := f.(strictTokener)
+	return ok && strict.strictToken()
+}
+
+// logTokenFallback records that a creation asked with a token was served by the untokened typeName.
+func logTokenFallback(ctx Context, opts *RegistryOpts, typeName string) {
+	if len(opts.InjectionToken) == 0 {
+		return
+	}
+
+	loggerFor(opts).
+		With("type", typeName).
+		With("token", opts.InjectionToken).
+		With("breadcrumbs", ctx.Breadcrumbs()).
+		Debug("di token '%s' has no registration for '%s', fell back to the untokened one", opts.InjectionToken, typeName)
+}
+
+// createByUpcast is an internal function that resolves an interface type T through the unique
+// registration or hot instance implementing it. It returns the created instance, the name
+// it was resolved from and any error that occurred, including ambiguity between candidates.
+func createByUpcast[T any](ctx Context, f Registry, opts *RegistryOpts) (any, string, error) {
+	candidates, err := upcastCandidates[T](f, opts)
+	if err != nil {
+		return nil, TypeNameIn[T](f), err
+	}
+
+	loggerFor(opts).With("type", TypeNameIn[T](f)).With("candidate", candidates[0]).Debug("di upcasting interface to registered implementation")
+
+	// orphan hot instances have no registration to create them from
+	instance, err := f.GetHotInstance(ctx, nil, candidates[0])
+	if err != nil {
+		instance, err = f.Create(ctx, candidates[0], struct{}{}, opts)
+	}
+
+	return instance, candidates[0], err
+}
+
+// upcastCandidates returns the registrations implementing the interface type T. It fails when
+// T is not an interface, when there is no candidate or when more than one could be selected,
+// in which case the candidates are returned alongside the error.
+func upcastCandidates[T any](f Registry, opts *RegistryOpts) ([]string, error) {
+	tType := TypeNameIn[T](f)
+	iface := reflect.TypeOf((*T)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		return nil, errors.New("cannot upcast to non interface type '%s'", tType, DependencyMissingErrorCode)
+	}
+
+	resolver, ok := f.(interfaceResolver)
+	if !ok {
+		return nil, errors.New("registry does not support interface upcasting for '%s'", tType, DependencyMissingErrorCode)
+	}
+
+	candidates := resolver.implementationsOf(iface, opts.InjectionToken)
+	switch len(candidates) {
+	case 0:
+		return nil, errors.New("no registration implements '%s'", tType, DependencyMissingErrorCode)
+	case 1:
+		return candidates, nil
+	default:
+		return candidates, newAmbiguousDependencyError(tType, candidates)
+	}
+}
+
+// newAmbiguousDependencyError builds the error returned when more than one registration could
+// satisfy typeName. Every candidate is listed together with a hint on how to select it.
+func newAmbiguousDependencyError(typeName string, candidates []string) error {
+	hints := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		token, _, tokened := splitTokenedName(strings.Split(candidate, ";")[0])
+		if tokened {
+			hints = append(hints, fmt.Sprintf("'%s' (use WithToken(%q))", candidate, token))
+			continue
+		}
+
+		hints = append(hints, fmt.Sprintf("'%s' (register '%s' explicitly or create '%s' directly)", candidate, typeName, candidate))
+	}
+
+	return errors.New(
+		"ambiguous dependency '%s', %d candidates: %s",
+		typeName,
+		len(candidates),
+		strings.Join(hints, ", "),
+		AmbiguousDependencyErrorCode,
+	)
+}
+
 // createSingleConfigurationWithToken is an internal function that creates a configuration instance.
 // It creates a single configuration of type CT using the provided context and registry options.
 // Returns the created configuration instance and any error that occurred.
 func createSingleConfigurationWithToken[CT any](ctx Context, opts *RegistryOpts) (CT, error) {
 	var (
-		f               = Instance
+		f               = DefaultRegistry()
 		typedInstance   CT
 		unknownInstance any
 		err             error
@@ -215,34 +479,40 @@ func createSingleConfigurationWithToken[CT any](ctx Context, opts *RegistryOpts)
 	}
 
 	if !IsNilOrEmpty(opts.ConfigNode) {
-		log := Logger.With("opts.config_node", opts)
+		log := loggerFor(opts).With("opts.config_node", opts)
 		log.Debug("checking opts.ConfigNode for return type")
-		typedInstance, ok = SafeTypeAssert[CT](opts.ConfigNode)
+		typedInstance, ok = typeAssertFor[CT](opts.ConfigNode, opts)
 		if ok {
 			log.Debug("returning opts.ConfigNode as CT")
 			return typedInstance, nil
 		}
 
-		log.Debug("di opts.ConfigNode is not of type CT '%s'. proceeding to create configuration from ctx", TypeName[CT]())
+		log.Debug("di opts.ConfigNode is not of type CT '%s'. proceeding to create configuration from ctx", TypeNameIn[CT](opts.Registry))
 	}
 
-	tType := TypeName[CT](token)
+	tType := TypeNameIn[CT](f, token)
 	unknownInstance, err = f.CreateConfiguration(ctx, tType, opts)
-	_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+	isMissing := isDependencyMissing(err)
 	if err != nil && (!isMissing || len(token) == 0) {
 		return typedInstance, errors.Wrap(err, "failed to create dependency of type '%s' with breadcrumbs '%s'", tType, ctx.Breadcrumbs(), ErrorCreatingDependencyErrorCode)
 	}
 
+	if isMissing && tokenFallbackDisabled(f, opts) {
+		return typedInstance, errors.Wrap(err, "configuration of type '%s' not registered with token '%s' and untokened fallback disabled, with breadcrumbs '%s'", tType, token, ctx.Breadcrumbs(), DependencyMissingErrorCode)
+	}
+
 	if isMissing {
 		var secErr error
-		tType = TypeName[CT]() //trying creation without token
+		tType = TypeNameIn[CT](f) //trying creation without token
 		unknownInstance, secErr = f.CreateConfiguration(ctx, tType, opts)
 		if secErr != nil {
 			return typedInstance, errors.Wrap(secErr, "failed to create dependency '%s' without token with breadcrumbs '%s", tType, ctx.Breadcrumbs(), ErrorCreatingDependencyErrorCode).WithNestedError(err)
 		}
+
+		logTokenFallback(ctx, opts, tType)
 	}
 
-	typedInstance, ok = SafeTypeAssert[CT](unknownInstance)
+	typedInstance, ok = typeAssertFor[CT](unknownInstance, opts)
 	if !ok {
 		panic(errors.New("failed to cast dependency to expected type '%s'", tType, DependencyTypeMismatchErrorCode))
 	}
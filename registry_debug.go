@@ -0,0 +1,142 @@
+package di
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// DebugHotInstance describes a hot instance in a DebugState.
+type DebugHotInstance struct {
+	Key  string `json:"key"`
+	Type string `json:"type"`
+}
+
+// DebugStats summarizes a DebugState.
+type DebugStats struct {
+	Registrations              int `json:"registrations"`
+	ConfigurationRegistrations int `json:"configuration_registrations"`
+	HotInstances               int `json:"hot_instances"`
+	ResolvedDependencies       int `json:"resolved_dependencies"`
+	Workers                    int `json:"workers"`
+}
+
+// DebugState is the registry state served by DebugHandler.
+type DebugState struct {
+	Registrations []RegistrationInfo `json:"registrations"`
+	HotInstances  []DebugHotInstance `json:"hot_instances"`
+	Workers       []WorkerStatus     `json:"workers"`
+	Stats         DebugStats         `json:"stats"`
+}
+
+// debugDescriber is implemented by registries able to describe their state to DebugHandler.
+type debugDescriber interface {
+	debugState() DebugState
+}
+
+// DebugHandler returns a handler serving the state of registry: its registrations with their tokens
+// and config node paths, hot instances, workers and resolution stats. It serves JSON, or HTML when
+// requested with ?format=html or an Accept header preferring text/html. Mount it on an internal
+// listener only, e.g. under /debug/di, since it discloses the application wiring.
+// A POST request with a log_level parameter (ERROR, WARN, LOG, DEBUG or SILENT) changes the level
+// of the registry messages, see RegistryInterceptor.SetResolutionLogLevel.
+func DebugHandler(registry Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			level, ok := parseLogLevel(r.FormValue("log_level"))
+			if !ok {
+				http.Error(w, fmt.Sprintf("invalid log_level '%s'", r.FormValue("log_level")), http.StatusBadRequest)
+				return
+			}
+
+			interceptor, ok := registry.(RegistryInterceptor)
+			if !ok {
+				http.Error(w, fmt.Sprintf("registry of type %T cannot change its log level", registry), http.StatusNotImplemented)
+				return
+			}
+
+			interceptor.SetResolutionLogLevel(level)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		describer, ok := registry.(debugDescriber)
+		if !ok {
+			http.Error(w, fmt.Sprintf("registry of type %T cannot be inspected", registry), http.StatusNotImplemented)
+			return
+		}
+
+		state := describer.debugState()
+		if r.URL.Query().Get("format") == "html" || strings.HasPrefix(r.Header.Get("Accept"), "text/html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = debugTemplate.Execute(w, state)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := gojson.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(state)
+	})
+}
+
+func (dif *diRegistry) debugState() DebugState {
+	workers := dif.Workers()
+	registrations := dif.List()
+
+	dif.mu.RLock()
+	defer dif.mu.RUnlock()
+
+	state := DebugState{
+		Registrations: registrations,
+		HotInstances:  make([]DebugHotInstance, 0, len(dif.hotInstances)),
+		Workers:       workers,
+		Stats: DebugStats{
+			Registrations:              len(dif.registrations),
+			ConfigurationRegistrations: len(dif.configurationRegistrations),
+			HotInstances:               len(dif.hotInstances),
+			ResolvedDependencies:       len(dif.dependencies),
+			Workers:                    len(workers),
+		},
+	}
+
+	for key, instance := range dif.hotInstances {
+		state.HotInstances = append(state.HotInstances, DebugHotInstance{Key: key, Type: fmt.Sprintf("%T", instance)})
+	}
+
+	sort.Slice(state.HotInstances, func(i, j int) bool {
+		return state.HotInstances[i].Key < state.HotInstances[j].Key
+	})
+
+	return state
+}
+
+var debugTemplate = template.Must(template.New("di").Parse(`<!DOCTYPE html>
+<html>
+<head><title>di registry</title></head>
+<body>
+<h1>di registry</h1>
+<p>{{.Stats.Registrations}} registrations, {{.Stats.ConfigurationRegistrations}} configuration registrations,
+{{.Stats.HotInstances}} hot instances, {{.Stats.ResolvedDependencies}} resolved dependencies, {{.Stats.Workers}} workers</p>
+<h2>Registrations</h2>
+<table>
+<tr><th>Name</th><th>Token</th><th>Configuration</th><th>Config node path</th><th>Lifetime</th><th>Conditionals</th><th>Eager</th><th>Registered at</th></tr>
+{{range .Registrations}}<tr><td>{{.Name}}</td><td>{{.InjectionToken}}</td><td>{{.Configuration}}</td><td>{{.ConfigNodePath}}</td><td>{{.Lifetime}}</td><td>{{.Conditionals}}</td><td>{{.Eager}}</td><td>{{.CallSite}}</td></tr>
+{{end}}</table>
+<h2>Hot instances</h2>
+<table>
+<tr><th>Key</th><th>Type</th></tr>
+{{range .HotInstances}}<tr><td>{{.Key}}</td><td>{{.Type}}</td></tr>
+{{end}}</table>
+<h2>Workers</h2>
+<table>
+<tr><th>Name</th><th>State</th><th>Error</th></tr>
+{{range .Workers}}<tr><td>{{.Name}}</td><td>{{.State}}</td><td>{{if .Err}}{{.Err}}{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,58 @@
+package di
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gojson "github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugHandler(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, RegisterPair[*databaseTest, databaseConfigTest](
+		func(ctx Context, opts *RegistryOpts, cfg databaseConfigTest) (*databaseTest, error) {
+			return &databaseTest{}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (databaseConfigTest, error) {
+			return databaseConfigTest{}, nil
+		},
+		WithRegistry(registry), WithToken("replica"), WithConfigNodePath("databases.replica"), WithEager()))
+	require.NoError(t, registry.WarmUp(NewContext()))
+
+	handler := DebugHandler(registry)
+
+	t.Run("json", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/di", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		var state DebugState
+		require.NoError(t, gojson.Unmarshal(recorder.Body.Bytes(), &state))
+		assert.Equal(t, DebugStats{Registrations: 1, ConfigurationRegistrations: 1, HotInstances: 2}, state.Stats)
+
+		instanceName := PairTypeName(TypeName[*databaseTest]("replica"), TypeName[databaseConfigTest]("replica"))
+		require.Len(t, state.Registrations, 2)
+		assert.Equal(t, instanceName, state.Registrations[1].Name)
+		assert.Equal(t, InjectionToken("replica"), state.Registrations[1].InjectionToken)
+		assert.Equal(t, "databases.replica", state.Registrations[1].ConfigNodePath)
+		assert.True(t, state.Registrations[1].Eager)
+		assert.Contains(t, state.HotInstances, DebugHotInstance{Key: "replica:" + instanceName, Type: "*di.databaseTest"})
+	})
+
+	t.Run("html", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/di?format=html", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "databases.replica")
+	})
+
+	t.Run("uninspectable registry", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		DebugHandler(&TestFactory{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/di", nil))
+		assert.Equal(t, http.StatusNotImplemented, recorder.Code)
+	})
+}
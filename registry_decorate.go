@@ -0,0 +1,133 @@
+package di
+
+import (
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// decorator wraps an instance created by a registration, see Decorate.
+type decorator struct {
+	fn       func(ctx Context, instance any) (any, error)
+	callSite string // file:line of the Decorate call
+}
+
+// decoratorRecorder is implemented by registries able to decorate the instances
+// created by their registrations, see Decorate.
+type decoratorRecorder interface {
+	recordDecorator(typeNameOf string, fn decorator)
+	decoratorsOf(typeNameOf string) []decorator
+}
+
+// Decorate wraps the instances of the registration of type T with fn, e.g. to add metrics, caching
+// or retries around a service without touching its factory:
+//
+//	di.Decorate[Repository](func(ctx di.Context, inner Repository) (Repository, error) {
+//		return &cachedRepository{inner: inner}, nil
+//	})
+//
+// Decorators chain in the order they're added, the first one receiving the instance created by the
+// factory, and apply to Register and RegisterPair registrations of T with the token of the options,
+// made before or after the call. The hot instances of T are decorated once, the ones created before
+// the call are dropped so the next creation is decorated.
+func Decorate[T any](fn func(ctx Context, inner T) (T, error), options ...func(*RegistryOpts)) error {
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	if err := rejectView(&registryOpts); err != nil {
+		return err
+	}
+
+	f := registryOpts.Registry
+	if f == nil {
+		f = DefaultRegistry()
+	}
+
+	tType := TypeNameIn[T](f, registryOpts.InjectionToken)
+	recorder, ok := f.(decoratorRecorder)
+	if !ok {
+		return errors.New("registry does not support decorating '%s'", tType, ErrorCreatingDependencyErrorCode)
+	}
+
+	recorder.recordDecorator(tType, decorator{
+		fn: func(ctx Context, instance any) (any, error) {
+			inner, _ := instance.(T)
+			return fn(ctx, inner)
+		},
+		callSite: callSite(1),
+	})
+
+	return nil
+}
+
+// decoratedHandler wraps a creation function so its instances go through the decorators of typeName.
+func decoratedHandler[T any](f Registry, fn TypedCreateInstanceNoConfigHandler[T], typeName string) TypedCreateInstanceNoConfigHandler[T] {
+	return func(ctx Context, opts *RegistryOpts) (T, error) {
+		instance, err := fn(ctx, opts)
+		if err != nil {
+			return instance, err
+		}
+
+		return decorateInstance(ctx, f, typeName, instance)
+	}
+}
+
+// decoratedPairHandler is the RegisterPair counterpart of decoratedHandler.
+func decoratedPairHandler[T any, CT any](f Registry, fn TypedCreateInstanceHandler[T, CT], typeName string) TypedCreateInstanceHandler[T, CT] {
+	return func(ctx Context, opts *RegistryOpts, config CT) (T, error) {
+		instance, err := fn(ctx, opts, config)
+		if err != nil {
+			return instance, err
+		}
+
+		return decorateInstance(ctx, f, typeName, instance)
+	}
+}
+
+// decorateInstance runs instance through the decorators recorded in f for typeName, in order.
+func decorateInstance[T any](ctx Context, f Registry, typeName string, instance T) (T, error) {
+	recorder, ok := f.(decoratorRecorder)
+	if !ok {
+		return instance, nil
+	}
+
+	for i, decorate := range recorder.decoratorsOf(typeName) {
+		decorated, err := decorate.fn(ctx, instance)
+		if err != nil {
+			return instance, errors.Wrap(err, "decorator %d of %s failed", i, typeName, ErrorCreatingDependencyErrorCode)
+		}
+
+		instance, _ = decorated.(T)
+	}
+
+	return instance, nil
+}
+
+// recordDecorator appends fn to the decorators of typeNameOf, dropping the hot instances
+// created without it by the registrations of typeNameOf, pairs included.
+func (dif *diRegistry) recordDecorator(typeNameOf string, fn decorator) {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	dif.decorators[typeNameOf] = append(dif.decorators[typeNameOf], fn)
+	for name := range dif.registrations {
+		if name == typeNameOf || strings.HasPrefix(name, typeNameOf+";") {
+			dif.dropHotInstancesLocked(name)
+		}
+	}
+}
+
+func (dif *diRegistry) decoratorsOf(typeNameOf string) []decorator {
+	dif.mu.RLock()
+	defer dif.mu.RUnlock()
+
+	return dif.decorators[typeNameOf]
+}
@@ -0,0 +1,80 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decoratedGreeterTest interface {
+	Greet() string
+}
+
+type plainGreeterTest struct{}
+
+func (plainGreeterTest) Greet() string { return "hello" }
+
+type wrappingGreeterTest struct {
+	inner decoratedGreeterTest
+	tag   string
+}
+
+func (g wrappingGreeterTest) Greet() string { return g.tag + "(" + g.inner.Greet() + ")" }
+
+func wrapGreeter(tag string) func(ctx Context, inner decoratedGreeterTest) (decoratedGreeterTest, error) {
+	return func(ctx Context, inner decoratedGreeterTest) (decoratedGreeterTest, error) {
+		return wrappingGreeterTest{inner: inner, tag: tag}, nil
+	}
+}
+
+func TestDecorate(t *testing.T) {
+	registry := NewTestRegistry(t)
+	created := 0
+	require.NoError(t, Register[decoratedGreeterTest](func(ctx Context, opts *RegistryOpts) (decoratedGreeterTest, error) {
+		created++
+		return plainGreeterTest{}, nil
+	}, WithRegistry(registry)))
+
+	greeter, err := Create[decoratedGreeterTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", greeter.Greet())
+
+	require.NoError(t, Decorate[decoratedGreeterTest](wrapGreeter("metrics"), WithRegistry(registry)))
+	require.NoError(t, Decorate[decoratedGreeterTest](wrapGreeter("retry"), WithRegistry(registry)))
+
+	for range 2 {
+		greeter, err = Create[decoratedGreeterTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "retry(metrics(hello))", greeter.Greet(), "decorators chain in registration order")
+	}
+	assert.Equal(t, 2, created, "the hot instance is dropped once decorated, then decorated once")
+
+	t.Run("pair", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, Decorate[decoratedGreeterTest](wrapGreeter("cache"), WithRegistry(registry), WithToken("pair")))
+		require.NoError(t, RegisterPair[decoratedGreeterTest, SimpleConfig](func(ctx Context, opts *RegistryOpts, cfg SimpleConfig) (decoratedGreeterTest, error) {
+			return plainGreeterTest{}, nil
+		}, func(ctx Context, opts *RegistryOpts) (SimpleConfig, error) {
+			return SimpleConfig{}, nil
+		}, WithRegistry(registry), WithToken("pair")))
+
+		greeter, err := CreatePair[decoratedGreeterTest, SimpleConfig](NewContext(), WithRegistry(registry), WithToken("pair"))
+		require.NoError(t, err)
+		assert.Equal(t, "cache(hello)", greeter.Greet())
+	})
+
+	t.Run("failing decorator", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, Register[decoratedGreeterTest](func(ctx Context, opts *RegistryOpts) (decoratedGreeterTest, error) {
+			return plainGreeterTest{}, nil
+		}, WithRegistry(registry)))
+		require.NoError(t, Decorate[decoratedGreeterTest](func(ctx Context, inner decoratedGreeterTest) (decoratedGreeterTest, error) {
+			return nil, errors.New("connection refused")
+		}, WithRegistry(registry)))
+
+		_, err := Create[decoratedGreeterTest](NewContext(), WithRegistry(registry))
+		assert.ErrorContains(t, err, "decorator 0 of di.decoratedGreeterTest failed")
+	})
+}
@@ -0,0 +1,51 @@
+package di
+
+import (
+	"sync"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+var (
+	defaultRegistryMu     sync.RWMutex
+	defaultRegistryFrozen bool
+)
+
+// DefaultRegistry returns the registry used by the functions given no WithRegistry option,
+// NewRegistry() unless replaced with SetDefaultRegistry. It's safe for concurrent use.
+func DefaultRegistry() Registry {
+	defaultRegistryMu.RLock()
+	defer defaultRegistryMu.RUnlock()
+
+	return Instance
+}
+
+// SetDefaultRegistry replaces the default registry with registry, returning the previous one so
+// tests can restore it. It fails once FreezeDefaultRegistry was called, or when registry is nil.
+// Resolutions already started keep the registry they started with.
+func SetDefaultRegistry(registry Registry) (Registry, error) {
+	defaultRegistryMu.Lock()
+	defer defaultRegistryMu.Unlock()
+
+	if registry == nil {
+		return Instance, errors.New("default registry cannot be nil", ErrorCreatingDependencyErrorCode)
+	}
+
+	if defaultRegistryFrozen {
+		return Instance, errors.New("default registry is frozen", DefaultRegistryFrozenErrorCode)
+	}
+
+	previous := Instance
+	Instance = registry
+	return previous, nil
+}
+
+// FreezeDefaultRegistry makes every later SetDefaultRegistry fail, usually called once the application
+// is wired at startup, so no library or test swaps the registry its resolutions run on.
+// The default registry itself stays usable, registrations included.
+func FreezeDefaultRegistry() {
+	defaultRegistryMu.Lock()
+	defer defaultRegistryMu.Unlock()
+
+	defaultRegistryFrozen = true
+}
@@ -0,0 +1,63 @@
+package di
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDefaultRegistry(t *testing.T) {
+	original := DefaultRegistry()
+	t.Cleanup(func() {
+		defaultRegistryMu.Lock()
+		defaultRegistryFrozen = false
+		defaultRegistryMu.Unlock()
+
+		_, err := SetDefaultRegistry(original)
+		require.NoError(t, err)
+	})
+
+	registry := NewTestRegistry(t)
+	previous, err := SetDefaultRegistry(registry)
+	require.NoError(t, err)
+	assert.Same(t, original, previous)
+	assert.Same(t, registry, DefaultRegistry())
+
+	require.NoError(t, Register[*tenantPoolTest](func(ctx Context, opts *RegistryOpts) (*tenantPoolTest, error) {
+		return &tenantPoolTest{DSN: "default"}, nil
+	}))
+	assert.True(t, registry.IsRegistered(TypeName[*tenantPoolTest]()), "functions without WithRegistry use the default registry")
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = SetDefaultRegistry(registry)
+			_, _ = Create[*tenantPoolTest](NewContext())
+		}()
+	}
+	wg.Wait()
+
+	_, err = SetDefaultRegistry(nil)
+	assert.Error(t, err)
+
+	FreezeDefaultRegistry()
+	_, err = SetDefaultRegistry(NewRegistry())
+	_, isFrozen := errors.Has(err, DefaultRegistryFrozenErrorCode)
+	assert.True(t, isFrozen)
+	assert.Same(t, registry, DefaultRegistry())
+}
+
+// useDefaultRegistryTest makes registry the default registry for the duration of the test.
+func useDefaultRegistryTest(t *testing.T, registry Registry) {
+	previous, err := SetDefaultRegistry(registry)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := SetDefaultRegistry(previous)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,47 @@
+package di
+
+import (
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// DefaultMaxResolutionDepth is the deepest a resolution tree may nest creations by default,
+// see WithMaxResolutionDepth.
+const DefaultMaxResolutionDepth = 64
+
+// WithMaxResolutionDepth bounds how deep the resolution trees of the registry may nest creations,
+// DefaultMaxResolutionDepth by default. A creation deeper than depth fails with ResolutionDepthErrorCode,
+// listing the chain of types being created, instead of exhausting the stack on an accidental recursion,
+// e.g. a factory creating its own type again without a hot instance to stop it.
+func WithMaxResolutionDepth(depth int) RegistryOption {
+	return func(r *diRegistry) {
+		r.maxResolutionDepth = depth
+	}
+}
+
+// checkResolutionDepth fails when the creation of typeName in ctx is nested deeper than the registry allows.
+func (dif *diRegistry) checkResolutionDepth(ctx Context, typeName string) error {
+	diCtx, ok := ctx.(*context)
+	if !ok {
+		return nil
+	}
+
+	maxDepth := dif.maxResolutionDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxResolutionDepth
+	}
+
+	if diCtx.depth <= maxDepth {
+		return nil
+	}
+
+	return errors.New(
+		"resolution of %s exceeds the maximum depth %d, chain: %s, breadcrumbs: '%s'",
+		typeName,
+		maxDepth,
+		strings.Join(diCtx.chain.names(), " -> "),
+		strings.Join(diCtx.Breadcrumbs(), "."),
+		ResolutionDepthErrorCode,
+	)
+}
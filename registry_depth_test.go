@@ -0,0 +1,46 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recursiveDepthTest struct {
+	child *recursiveDepthTest
+}
+
+type chainDepthTest struct{}
+
+func TestMaxResolutionDepth(t *testing.T) {
+	registry := NewRegistry(WithMaxResolutionDepth(5))
+	calls := 0
+	require.NoError(t, Register[*recursiveDepthTest](func(ctx Context, opts *RegistryOpts) (*recursiveDepthTest, error) {
+		calls++
+		child, err := Create[*recursiveDepthTest](ctx, WithRegistry(opts.Registry))
+		return &recursiveDepthTest{child: child}, err
+	}, WithRegistry(registry)))
+
+	_, err := Create[*recursiveDepthTest](NewContext(), WithRegistry(registry))
+	_, tooDeep := errors.Has(err, ResolutionDepthErrorCode)
+	assert.True(t, tooDeep)
+	assert.ErrorContains(t, err, "exceeds the maximum depth 5")
+	assert.ErrorContains(t, err, "chain: di.recursiveDepthTest -> di.recursiveDepthTest")
+	assert.Equal(t, 6, calls)
+
+	t.Run("default", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, Register[chainDepthTest](func(ctx Context, opts *RegistryOpts) (chainDepthTest, error) {
+			if ctx.CreateInfo().Depth < DefaultMaxResolutionDepth {
+				return Create[chainDepthTest](ctx, WithRegistry(opts.Registry))
+			}
+
+			return chainDepthTest{}, nil
+		}, WithRegistry(registry)))
+
+		_, err := Create[chainDepthTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err, "resolutions as deep as DefaultMaxResolutionDepth are allowed")
+	})
+}
@@ -0,0 +1,207 @@
+package di
+
+import (
+	goctx "context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pixie-sh/logger-go/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLogger is a logger.Interface keeping the warnings it receives
+type recordingLogger struct {
+	mu       *sync.Mutex
+	warnings *[]string
+}
+
+func newRecordingLogger() recordingLogger {
+	return recordingLogger{mu: &sync.Mutex{}, warnings: &[]string{}}
+}
+
+func (l recordingLogger) Clone() logger.Interface                  { return l }
+func (l recordingLogger) WithCtx(_ goctx.Context) logger.Interface { return l }
+func (l recordingLogger) With(_ string, _ any) logger.Interface    { return l }
+func (l recordingLogger) Log(_ string, _ ...any)                   {}
+func (l recordingLogger) Error(_ string, _ ...any)                 {}
+func (l recordingLogger) Debug(_ string, _ ...any)                 {}
+func (l recordingLogger) Warn(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*l.warnings = append(*l.warnings, fmt.Sprintf(format, args...))
+}
+
+func (l recordingLogger) Warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, *l.warnings...)
+}
+
+func TestGlobalInstanceDiagnostics(t *testing.T) {
+	recorder := newRecordingLogger()
+	previous := Logger
+	Logger = recorder
+	t.Cleanup(func() { Logger = previous })
+
+	Instance = NewRegistry()
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{Level: "global"}, nil
+	}))
+
+	registry := NewRegistry(WithGlobalInstanceDiagnostics())
+	require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+		// forgot WithRegistry, silently resolves from the global Instance
+		log, err := Create[*loggerTest](ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return &serviceTest{Logger: log}, nil
+	}, WithRegistry(registry), WithToken("payments")))
+
+	service, err := Create[*serviceTest](NewContext(), WithRegistry(registry), WithToken("payments"))
+	require.NoError(t, err)
+	assert.Equal(t, "global", service.Logger.Level)
+
+	warnings := recorder.Warnings()
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "di.loggerTest")
+	assert.Contains(t, warnings[0], "payments")
+
+	t.Run("no warning without diagnostics", func(t *testing.T) {
+		silent := NewRegistry()
+		require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+			log, err := Create[*loggerTest](ctx)
+			return &serviceTest{Logger: log}, err
+		}, WithRegistry(silent)))
+
+		_, err := Create[*serviceTest](NewContext(), WithRegistry(silent))
+		require.NoError(t, err)
+		assert.Len(t, recorder.Warnings(), 1)
+	})
+}
+
+func TestRegistryLogger(t *testing.T) {
+	newDiagnosedRegistry := func(options ...RegistryOption) *diRegistry {
+		registry := NewRegistry(append(options, WithGlobalInstanceDiagnostics())...)
+		require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+			log, err := Create[*loggerTest](ctx)
+			return &serviceTest{Logger: log}, err
+		}, WithRegistry(registry)))
+		return registry
+	}
+
+	global := newRecordingLogger()
+	previous := Logger
+	Logger = global
+	t.Cleanup(func() { Logger = previous })
+
+	Instance = NewRegistry()
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{}, nil
+	}))
+
+	recorder := newRecordingLogger()
+	_, err := Create[*serviceTest](NewContext(), WithRegistry(newDiagnosedRegistry(WithLogger(recorder))))
+	require.NoError(t, err)
+	assert.Len(t, recorder.Warnings(), 1)
+
+	_, err = Create[*serviceTest](NewContext(), WithRegistry(newDiagnosedRegistry(WithLogLevel(logger.WARN))))
+	require.NoError(t, err)
+	assert.Len(t, global.Warnings(), 1)
+
+	_, err = Create[*serviceTest](NewContext(), WithRegistry(newDiagnosedRegistry(WithLogLevel(logger.ERROR), WithLogger(recorder))))
+	require.NoError(t, err)
+	_, err = Create[*serviceTest](NewContext(), WithRegistry(newDiagnosedRegistry(WithSilent())))
+	require.NoError(t, err)
+	assert.Len(t, recorder.Warnings(), 1)
+	assert.Len(t, global.Warnings(), 1)
+
+	t.Run("runtime level", func(t *testing.T) {
+		recorder := newRecordingLogger()
+		registry := newDiagnosedRegistry(WithLogger(recorder), WithSilent())
+		_, err := Create[*serviceTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Empty(t, recorder.Warnings())
+
+		registry.SetResolutionLogLevel(logger.WARN)
+		registry.ClearHotInstances()
+		_, err = Create[*serviceTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Len(t, recorder.Warnings(), 1)
+
+		handler := DebugHandler(registry)
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/debug/di?log_level=silent", nil))
+		assert.Equal(t, http.StatusNoContent, response.Code)
+		registry.ClearHotInstances()
+		_, err = Create[*serviceTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Len(t, recorder.Warnings(), 1)
+
+		response = httptest.NewRecorder()
+		handler.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/debug/di?log_level=verbose", nil))
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func TestRegistrationCallSites(t *testing.T) {
+	recorder := newRecordingLogger()
+	registry := NewRegistry(WithLogger(recorder))
+
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return nil, fmt.Errorf("unreachable sink")
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return nil, fmt.Errorf("unreachable sink")
+	}, WithRegistry(registry)))
+
+	warnings := recorder.Warnings()
+	require.Len(t, warnings, 1)
+	assert.Regexp(t, `registered at .*registry_diagnostics_test.go:\d+ is overridden by the one registered at .*registry_diagnostics_test.go:\d+`, warnings[0])
+
+	_, err := Create[*loggerTest](NewContext(), WithRegistry(registry))
+	require.Error(t, err)
+	assert.Regexp(t, `registered at .*registry_diagnostics_test.go:\d+ failed`, err.Error())
+	assert.ErrorContains(t, err, "unreachable sink")
+
+	other := NewRegistry()
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{}, nil
+	}, WithRegistry(other)))
+
+	err = registry.Merge(other, MergeConflictError)
+	require.Error(t, err)
+	assert.Regexp(t, `\(registered at .*registry_diagnostics_test.go:\d+ and registered at .*registry_diagnostics_test.go:\d+\)`, err.Error())
+
+	t.Run("pairs", func(t *testing.T) {
+		registry := NewRegistry()
+		require.NoError(t, RegisterPair[*overrideClientTest, overrideClientConfigTest](
+			func(ctx Context, opts *RegistryOpts, config overrideClientConfigTest) (*overrideClientTest, error) {
+				return nil, fmt.Errorf("unreachable client")
+			},
+			func(ctx Context, opts *RegistryOpts) (overrideClientConfigTest, error) {
+				return overrideClientConfigTest{}, fmt.Errorf("missing addr")
+			},
+			WithRegistry(registry),
+		))
+
+		infos := registry.List()
+		require.Len(t, infos, 2)
+		for _, info := range infos {
+			assert.Contains(t, info.CallSite, "registry_diagnostics_test.go:")
+		}
+
+		_, err := CreatePair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry))
+		assert.Regexp(t, `registered at .*registry_diagnostics_test.go:\d+ failed`, err.Error())
+		assert.ErrorContains(t, err, "missing addr")
+
+		_, err = CreatePair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithConfigOverride(overrideClientConfigTest{}))
+		assert.Regexp(t, `registered at .*registry_diagnostics_test.go:\d+ failed`, err.Error())
+		assert.ErrorContains(t, err, "unreachable client")
+	})
+}
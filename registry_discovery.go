@@ -0,0 +1,100 @@
+package di
+
+import (
+	"github.com/pixie-sh/errors-go"
+)
+
+// SelfRegistering is implemented by values that know how to wire themselves into a registry,
+// so a package can expose a single value instead of a list of Register calls.
+type SelfRegistering interface {
+	DIRegister(r Registry) error
+}
+
+// Module is a self-contained registration bundle shipped by a reusable package,
+// registering its providers with their own tokens and config paths.
+type Module interface {
+	Register(r Registry) error
+}
+
+// ModuleFunc adapts a plain function to the Module interface.
+type ModuleFunc func(r Registry) error
+
+func (fn ModuleFunc) Register(r Registry) error {
+	return fn(r)
+}
+
+// Use registers the provided modules into the global Instance, in order.
+func Use(modules ...Module) error {
+	return UseIn(DefaultRegistry(), modules...)
+}
+
+// UseIn registers the provided modules into r, in order. A failing module doesn't stop the others,
+// the failures are joined into the returned error so one run reveals every broken module.
+func UseIn(r Registry, modules ...Module) error {
+	var errs []error
+	for i, module := range modules {
+		if module == nil {
+			continue
+		}
+
+		err := module.Register(r)
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, "failed to register module %T at position %d", module, i, ErrorCreatingDependencyErrorCode))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Registrations joins the errors of the registrations made by a module, so a failing registration
+// doesn't hide the following ones:
+//
+//	func (m module) Register(r di.Registry) error {
+//		return di.Registrations(
+//			di.Register[*Client](newClient, di.WithRegistry(r)),
+//			di.RegisterConfiguration[Config](newConfig, di.WithRegistry(r)),
+//		)
+//	}
+func Registrations(errs ...error) error {
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+
+	return errors.Join(failed...)
+}
+
+// DiscoverAndRegister calls DIRegister on every provided SelfRegistering value, in order.
+// A Registry passed among the values selects where the following values are registered,
+// the global Instance is used until then. Module values are registered as well,
+// any other value is rejected. Failures don't stop the following values, they're joined
+// into the returned error.
+//
+//	err := di.DiscoverAndRegister(registry, payments.Module, auth.Module)
+func DiscoverAndRegister(values ...any) error {
+	var registry = DefaultRegistry()
+
+	var errs []error
+	for i, value := range values {
+		switch v := value.(type) {
+		case Registry:
+			registry = v
+		case SelfRegistering:
+			err := v.DIRegister(registry)
+			if err != nil {
+				errs = append(errs, errors.Wrap(err, "failed to register %T at position %d", value, i, ErrorCreatingDependencyErrorCode))
+			}
+		case Module:
+			err := v.Register(registry)
+			if err != nil {
+				errs = append(errs, errors.Wrap(err, "failed to register module %T at position %d", value, i, ErrorCreatingDependencyErrorCode))
+			}
+		default:
+			errs = append(errs, errors.New("value %T at position %d does not implement SelfRegistering nor Module", value, i, ErrorCreatingDependencyErrorCode))
+		}
+	}
+
+	return errors.Join(errs...)
+}
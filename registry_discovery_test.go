@@ -0,0 +1,95 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type loggerModuleTest struct {
+	level string
+}
+
+func (m loggerModuleTest) DIRegister(r Registry) error {
+	return Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{Level: m.level}, nil
+	}, WithRegistry(r))
+}
+
+func TestDiscoverAndRegister(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, DiscoverAndRegister(registry, loggerModuleTest{level: "DEBUG"}))
+
+	logger, err := Create[*loggerTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "DEBUG", logger.Level)
+
+	err = DiscoverAndRegister(registry, "not a module")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not implement SelfRegistering nor Module")
+}
+
+func cacheModuleTest(endpoint string) Module {
+	return ModuleFunc(func(r Registry) error {
+		return Register[*metricsCollectorTest](func(ctx Context, opts *RegistryOpts) (*metricsCollectorTest, error) {
+			return &metricsCollectorTest{Endpoint: endpoint}, nil
+		}, WithRegistry(r), WithToken("cache"))
+	})
+}
+
+func TestUseModules(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, UseIn(registry, cacheModuleTest("redis://cache"), nil))
+
+	metrics, err := Create[*metricsCollectorTest](NewContext(), WithRegistry(registry), WithToken("cache"))
+	require.NoError(t, err)
+	assert.Equal(t, "redis://cache", metrics.Endpoint)
+
+	other := NewTestRegistry(t)
+	require.NoError(t, DiscoverAndRegister(other, cacheModuleTest("redis://other")))
+	metrics, err = Create[*metricsCollectorTest](NewContext(), WithRegistry(other), WithToken("cache"))
+	require.NoError(t, err)
+	assert.Equal(t, "redis://other", metrics.Endpoint)
+
+	err = UseIn(registry, ModuleFunc(func(r Registry) error {
+		return errors.New("broken module")
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken module")
+}
+
+func TestModules_AggregateErrors(t *testing.T) {
+	registry := NewTestRegistry(t)
+	broken := func(message string) Module {
+		return ModuleFunc(func(r Registry) error {
+			return errors.New(message, RegistrationConflictErrorCode)
+		})
+	}
+
+	err := UseIn(registry, broken("first broken"), cacheModuleTest("redis://cache"), broken("second broken"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "first broken")
+	assert.Contains(t, err.Error(), "at position 2")
+	assert.Contains(t, err.Error(), "second broken")
+	_, conflicting := errors.Has(err, RegistrationConflictErrorCode, true)
+	assert.True(t, conflicting)
+
+	_, err = Create[*metricsCollectorTest](NewContext(), WithRegistry(registry), WithToken("cache"))
+	assert.NoError(t, err, "modules after a failing one are registered")
+
+	err = DiscoverAndRegister(NewTestRegistry(t), "not a module", broken("third broken"), loggerModuleTest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value string at position 1 does not implement SelfRegistering nor Module")
+	assert.Contains(t, err.Error(), "third broken")
+
+	err = Registrations(
+		Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) { return &loggerTest{}, nil }, WithRegistry(registry)),
+		nil,
+		errors.New("invalid registration"),
+	)
+	require.Error(t, err)
+	assert.Equal(t, "invalid registration", err.Error())
+	assert.NoError(t, Registrations(nil, nil))
+}
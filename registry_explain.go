@@ -0,0 +1,305 @@
+package di
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// ExplainStep is a single registration lookup performed while explaining a resolution.
+type ExplainStep struct {
+	Name       string `json:"name"`
+	Registered bool   `json:"registered"`
+	Reason     string `json:"reason"`
+}
+
+// ExplainReport describes how Create would resolve a type, without constructing anything.
+type ExplainReport struct {
+	TypeName       string         `json:"type_name"`
+	InjectionToken InjectionToken `json:"injection_token,omitempty"`
+	Steps          []ExplainStep  `json:"steps"`
+	Selected       string         `json:"selected,omitempty"`
+	TokenFallback  bool           `json:"token_fallback"`
+	Upcast         bool           `json:"upcast"`
+	Overridden     bool           `json:"overridden"` // the context substitutes the instance, see WithOverride
+	FreshInstance  bool           `json:"fresh_instance"`
+	HotInstance    bool           `json:"hot_instance"`
+	Decorators     []string       `json:"decorators,omitempty"` // call sites of the decorators applied, in order
+	Config         string         `json:"config,omitempty"`     // configuration registration of a pair, see ExplainPair
+	ConfigCached   bool           `json:"config_cached"`        // the configuration of the pair is reused rather than created
+	Tenant         TenantKey      `json:"tenant,omitempty"`
+	ConfigPath     string         `json:"config_path"`
+	ScopedConfig   bool           `json:"scoped_config"`
+	Breadcrumbs    []string       `json:"breadcrumbs"`
+}
+
+// String renders the report as a human readable multi line text.
+func (r ExplainReport) String() string {
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("explain %s (token '%s')\n", r.TypeName, r.InjectionToken))
+	for i, step := range r.Steps {
+		b.WriteString(fmt.Sprintf("  %d. %s registered=%t: %s\n", i+1, step.Name, step.Registered, step.Reason))
+	}
+
+	if r.Overridden {
+		b.WriteString("  overridden by the context\n")
+		return b.String()
+	}
+
+	if len(r.Selected) == 0 {
+		b.WriteString("  no registration selected\n")
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("  selected %s (token fallback=%t, upcast=%t)\n", r.Selected, r.TokenFallback, r.Upcast))
+	b.WriteString(fmt.Sprintf("  hot instance=%t (fresh=%t), tenant '%s', config path '%s' (scoped=%t), breadcrumbs %v\n", r.HotInstance, r.FreshInstance, r.Tenant, r.ConfigPath, r.ScopedConfig, r.Breadcrumbs))
+	if len(r.Config) > 0 {
+		b.WriteString(fmt.Sprintf("  configuration %s (cached=%t), config path '%s'\n", r.Config, r.ConfigCached, r.ConfigPath))
+	}
+	for i, site := range r.Decorators {
+		b.WriteString(fmt.Sprintf("  decorator %d added at %s\n", i, site))
+	}
+
+	return b.String()
+}
+
+// Explain performs a dry-run of Create[T] with the same options and reports which registration
+// would be selected, whether the token fallback or interface upcasting kicks in, the config path
+// lookups would use, whether a hot instance would be returned and the decorators applied otherwise.
+// The context is prepared as Create does, so its overrides, see WithOverride, and its tenant apply.
+// No factory is executed.
+func Explain[T any](ctx Context, options ...func(opts *RegistryOpts)) (ExplainReport, error) {
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	if _, overridden := overrideOf[T](ctx, &registryOpts); overridden {
+		return overriddenReport[T](ctx, &registryOpts), nil
+	}
+
+	return explainSingleWithToken[T](creationContext(ctx, &registryOpts), &registryOpts)
+}
+
+// ExplainPair is the CreatePair counterpart of Explain, reporting the pair registration and the
+// configuration side of CreatePair[T, CT]: the tokened configuration registration, the untokened one
+// when it's missing, the config path its factory would look up and whether the configuration created
+// earlier is reused rather than created. Pairs have no untokened fallback nor upcast.
+func ExplainPair[T any, CT Configuration](ctx Context, options ...func(opts *RegistryOpts)) (ExplainReport, error) {
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	if _, overridden := overrideOf[T](ctx, &registryOpts); overridden {
+		return overriddenReport[T](ctx, &registryOpts), nil
+	}
+
+	return explainPairWithToken[T, CT](pairCreationContext(ctx, &registryOpts), &registryOpts)
+}
+
+// explainPairWithToken is an internal function mirroring createPairWithToken lookups,
+// recording them into an ExplainReport instead of creating the instance.
+func explainPairWithToken[T any, CT any](ctx Context, opts *RegistryOpts) (ExplainReport, error) {
+	var (
+		f     = DefaultRegistry()
+		token = opts.InjectionToken
+	)
+
+	if opts.Registry != nil {
+		f = opts.Registry
+	}
+
+	report := ExplainReport{
+		TypeName:       TypeNameIn[T](f),
+		InjectionToken: token,
+		FreshInstance:  opts.freshInstance,
+		Tenant:         opts.Tenant,
+		ScopedConfig:   ctx.IsScoped(),
+		Breadcrumbs:    ctx.Breadcrumbs(),
+	}
+	report.ConfigPath, _ = assembleConfigurationLookupPath(ctx, opts)
+
+	ctType := TypeNameIn[CT](f, token)
+	tType := TypeNameIn[T](f, token)
+	pairTypeName := PairTypeName(tType, ctType)
+	if !registeredFor(ctx, f, pairTypeName) {
+		report.Steps = append(report.Steps, missingStep(f, pairTypeName, "pair registration missing"))
+		return report, errors.New("dependency not registered: %s", pairTypeName, DependencyMissingErrorCode)
+	}
+
+	report.Steps = append(report.Steps, ExplainStep{pairTypeName, true, "pair registration found"})
+	report.Selected = pairTypeName
+
+	configTypeName := PairTypeName(ctType, tType)
+	switch {
+	case opts.configOverride != nil:
+		report.Steps = append(report.Steps, ExplainStep{configTypeName, configurationRegisteredFor(ctx, f, configTypeName), "configuration overridden inline"})
+	case configurationRegisteredFor(ctx, f, configTypeName):
+		report.Steps = append(report.Steps, ExplainStep{configTypeName, true, "configuration registration found"})
+		report.Config = configTypeName
+	default:
+		reason := "configuration registration missing"
+		if configurationRegistered(f, configTypeName) {
+			reason = "no configuration registration condition holds"
+		}
+
+		report.Steps = append(report.Steps, ExplainStep{configTypeName, false, reason})
+		if len(token) > 0 {
+			untokened := PairTypeName(TypeNameIn[CT](f), TypeNameIn[T](f))
+			report.Steps = append(report.Steps, ExplainStep{untokened, configurationRegisteredFor(ctx, f, untokened), "untokened configuration not tried, pairs have no untokened fallback"})
+		}
+
+		return report, errors.New("configuration not registered: %s", configTypeName, DependencyMissingErrorCode)
+	}
+
+	fresh := opts.configOverride != nil || opts.freshInstance
+	if len(report.Config) > 0 {
+		_, err := f.GetHotInstance(ctx, opts, configTypeName)
+		report.ConfigCached = err == nil && !fresh
+	}
+
+	_, err := f.GetHotInstance(ctx, opts, pairTypeName)
+	report.HotInstance = err == nil && !fresh
+	if !report.HotInstance {
+		report.Decorators = decoratorCallSites(f, tType)
+	}
+
+	return report, nil
+}
+
+// explainSingleWithToken is an internal function mirroring createSingleWithToken lookups,
+// recording them into an ExplainReport instead of creating the instance.
+func explainSingleWithToken[T any](ctx Context, opts *RegistryOpts) (ExplainReport, error) {
+	var (
+		f     = DefaultRegistry()
+		token = opts.InjectionToken
+	)
+
+	if opts.Registry != nil {
+		f = opts.Registry
+	}
+
+	report := ExplainReport{
+		TypeName:       TypeNameIn[T](f),
+		InjectionToken: token,
+		FreshInstance:  opts.freshInstance,
+		Tenant:         opts.Tenant,
+		ScopedConfig:   ctx.IsScoped(),
+		Breadcrumbs:    ctx.Breadcrumbs(),
+	}
+	report.ConfigPath, _ = assembleConfigurationLookupPath(ctx, opts)
+
+	tType := TypeNameIn[T](f, token)
+	if registeredFor(ctx, f, tType) {
+		report.Steps = append(report.Steps, ExplainStep{tType, true, "registration found"})
+		report.Selected = tType
+	} else {
+		report.Steps = append(report.Steps, missingStep(f, tType, "registration missing"))
+		if tokenFallbackDisabled(f, opts) {
+			report.Steps = append(report.Steps, ExplainStep{TypeNameIn[T](f), registeredFor(ctx, f, TypeNameIn[T](f)), "untokened fallback disabled by strict token"})
+			return report, errors.New("dependency not registered: %s", tType, DependencyMissingErrorCode)
+		}
+
+		tType = TypeNameIn[T](f)
+		if registeredFor(ctx, f, tType) {
+			report.Steps = append(report.Steps, ExplainStep{tType, true, "registration found without token"})
+			report.Selected = tType
+			report.TokenFallback = len(token) > 0
+		} else {
+			report.Steps = append(report.Steps, missingStep(f, tType, "registration missing without token"))
+		}
+	}
+
+	if len(report.Selected) == 0 && opts.InterfaceUpcast {
+		candidates, err := upcastCandidates[T](f, opts)
+		for _, candidate := range candidates {
+			report.Steps = append(report.Steps, ExplainStep{candidate, registeredFor(ctx, f, candidate), "implements " + report.TypeName})
+		}
+
+		if err != nil {
+			return report, err
+		}
+
+		report.Selected = candidates[0]
+		report.Upcast = true
+	}
+
+	if len(report.Selected) == 0 {
+		return report, errors.New("dependency not registered: %s", report.TypeName, DependencyMissingErrorCode)
+	}
+
+	_, err := f.GetHotInstance(ctx, opts, report.Selected)
+	report.HotInstance = err == nil
+	if report.Upcast && !report.HotInstance {
+		_, err = f.GetHotInstance(ctx, nil, report.Selected)
+		report.HotInstance = err == nil
+	}
+
+	report.HotInstance = report.HotInstance && !opts.freshInstance
+	if !report.HotInstance {
+		report.Decorators = decoratorCallSites(f, report.Selected)
+	}
+
+	return report, nil
+}
+
+// missingStep returns the step of the registration name not selected by a creation, telling the
+// conditional registrations whose condition doesn't hold apart from the missing ones.
+func missingStep(f Registry, name string, reason string) ExplainStep {
+	if registered(f, name) {
+		return ExplainStep{name, false, "no registration condition holds"}
+	}
+
+	return ExplainStep{name, false, reason}
+}
+
+// overriddenReport returns the report of a creation of T substituted by an override of ctx, see WithOverride.
+func overriddenReport[T any](ctx Context, opts *RegistryOpts) ExplainReport {
+	f := opts.Registry
+	if f == nil {
+		f = DefaultRegistry()
+	}
+
+	tType := TypeNameIn[T](f, opts.InjectionToken)
+	return ExplainReport{
+		TypeName:       TypeNameIn[T](f),
+		InjectionToken: opts.InjectionToken,
+		Steps:          []ExplainStep{{tType, registeredFor(ctx, f, tType), "overridden by the context"}},
+		Overridden:     true,
+		Breadcrumbs:    ctx.Breadcrumbs(),
+	}
+}
+
+// decoratorCallSites returns where the decorators of the registration name were added, in the order they apply.
+func decoratorCallSites(f Registry, name string) []string {
+	recorder, ok := f.(decoratorRecorder)
+	if !ok {
+		return nil
+	}
+
+	var sites []string
+	for i, d := range recorder.decoratorsOf(name) {
+		site := d.callSite
+		if len(site) == 0 {
+			site = fmt.Sprintf("decorator %d", i)
+		}
+
+		sites = append(sites, site)
+	}
+
+	return sites
+}
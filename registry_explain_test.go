@@ -0,0 +1,149 @@
+package di
+
+import (
+	"io"
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	registry := NewTestRegistry(t)
+	calls := 0
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		calls++
+		return &loggerTest{Level: "INFO"}, nil
+	}, WithRegistry(registry)))
+
+	t.Run("token fallback", func(t *testing.T) {
+		report, err := Explain[*loggerTest](NewContext(), WithRegistry(registry), WithToken("audit"))
+		require.NoError(t, err)
+		assert.Equal(t, "di.loggerTest", report.Selected)
+		assert.True(t, report.TokenFallback)
+		assert.False(t, report.HotInstance)
+		assert.Equal(t, "audit", report.ConfigPath)
+		require.Len(t, report.Steps, 2)
+		assert.Equal(t, "audit:di.loggerTest", report.Steps[0].Name)
+		assert.False(t, report.Steps[0].Registered)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("hot instance expected", func(t *testing.T) {
+		_, err := Create[*loggerTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+
+		report, err := Explain[*loggerTest](NewContext(), WithRegistry(registry), WithConfigNodePath("logging"))
+		require.NoError(t, err)
+		assert.True(t, report.HotInstance)
+		assert.False(t, report.TokenFallback)
+		assert.Equal(t, "logging", report.ConfigPath)
+		assert.Contains(t, report.String(), "selected di.loggerTest")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("as created", func(t *testing.T) {
+		report, err := Explain[*loggerTest](NewContext(), WithRegistry(registry), WithFreshInstance())
+		require.NoError(t, err)
+		assert.True(t, report.FreshInstance)
+		assert.False(t, report.HotInstance, "fresh instances skip the hot instance")
+
+		acme := ContextWithTenant(NewContext(), "acme")
+		report, err = Explain[*loggerTest](acme, WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, TenantKey("acme"), report.Tenant)
+		assert.False(t, report.HotInstance, "tenants have their own hot instances")
+
+		require.NoError(t, Decorate[*loggerTest](func(ctx Context, inner *loggerTest) (*loggerTest, error) {
+			return inner, nil
+		}, WithRegistry(registry)))
+		report, err = Explain[*loggerTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		require.Len(t, report.Decorators, 1)
+		assert.Contains(t, report.Decorators[0], "registry_explain_test.go:")
+		assert.Contains(t, report.String(), "decorator 0 added at")
+
+		report, err = Explain[*loggerTest](WithOverride(NewContext(), &loggerTest{}), WithRegistry(registry), WithToken("audit"))
+		require.NoError(t, err)
+		assert.True(t, report.Overridden)
+		assert.Empty(t, report.Selected)
+		assert.Contains(t, report.String(), "overridden by the context")
+	})
+
+	t.Run("upcast", func(t *testing.T) {
+		require.NoError(t, Register[*closerTest](func(ctx Context, opts *RegistryOpts) (*closerTest, error) {
+			return &closerTest{}, nil
+		}, WithRegistry(registry)))
+
+		report, err := Explain[io.Closer](NewContext(), WithRegistry(registry), WithInterfaceUpcast())
+		require.NoError(t, err)
+		assert.True(t, report.Upcast)
+		assert.Equal(t, "di.closerTest", report.Selected)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		report, err := Explain[*serviceTest](NewContext(), WithRegistry(registry))
+		_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+		assert.True(t, isMissing)
+		assert.Empty(t, report.Selected)
+		assert.Contains(t, report.String(), "no registration selected")
+	})
+}
+
+func TestExplainPair(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, RegisterPair[*overrideClientTest, overrideClientConfigTest](
+		func(ctx Context, opts *RegistryOpts, config overrideClientConfigTest) (*overrideClientTest, error) {
+			return &overrideClientTest{Addr: config.Addr}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (overrideClientConfigTest, error) {
+			return overrideClientConfigTest{Addr: "registered:8080"}, nil
+		},
+		WithRegistry(registry),
+		WithToken("billing"),
+	))
+
+	report, err := ExplainPair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithToken("billing"))
+	require.NoError(t, err)
+	assert.Equal(t, "billing:di.overrideClientTest;billing:di.overrideClientConfigTest", report.Selected)
+	assert.Equal(t, "billing", report.ConfigPath)
+	assert.False(t, report.HotInstance)
+	assert.Equal(t, "billing:di.overrideClientConfigTest;billing:di.overrideClientTest", report.Config)
+	assert.False(t, report.ConfigCached)
+	require.Len(t, report.Steps, 2)
+	assert.Equal(t, "billing:di.overrideClientConfigTest;billing:di.overrideClientTest", report.Steps[1].Name)
+
+	_, err = CreatePair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithToken("billing"))
+	require.NoError(t, err)
+
+	report, err = ExplainPair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithToken("billing"))
+	require.NoError(t, err)
+	assert.True(t, report.HotInstance)
+	assert.True(t, report.ConfigCached)
+	assert.Contains(t, report.String(), "configuration billing:di.overrideClientConfigTest;billing:di.overrideClientTest (cached=true), config path 'billing'")
+
+	report, err = ExplainPair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithToken("billing"), WithConfigOverride(overrideClientConfigTest{}))
+	require.NoError(t, err)
+	assert.False(t, report.HotInstance, "overridden configurations bypass the hot instance")
+	assert.Equal(t, "configuration overridden inline", report.Steps[1].Reason)
+
+	report, err = ExplainPair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithToken("audit"))
+	_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+	assert.True(t, isMissing, "pairs have no untokened fallback")
+	assert.Empty(t, report.Selected)
+
+	t.Run("configuration missing", func(t *testing.T) {
+		configTypeName := PairTypeName(TypeName[overrideClientConfigTest]("billing"), TypeName[*overrideClientTest]("billing"))
+		require.NoError(t, registry.Unregister(configTypeName, nil))
+
+		report, err := ExplainPair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithToken("billing"))
+		_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+		assert.True(t, isMissing)
+		assert.Empty(t, report.Config)
+		require.Len(t, report.Steps, 3)
+		assert.Equal(t, ExplainStep{configTypeName, false, "configuration registration missing"}, report.Steps[1])
+		assert.Equal(t, "di.overrideClientConfigTest;di.overrideClientTest", report.Steps[2].Name)
+		assert.Equal(t, "untokened configuration not tried, pairs have no untokened fallback", report.Steps[2].Reason)
+	})
+}
@@ -0,0 +1,47 @@
+package di
+
+import (
+	"github.com/pixie-sh/errors-go"
+)
+
+// WithFallback returns a function that gives a registration a fallback provider, used when its
+// provider fails, e.g. because a dependency or configuration node it needs is missing:
+//
+//	di.Register[Cache](newRedisCache, di.WithFallback[Cache](newMemoryCache))
+//
+// The failure is logged and the fallback instance is kept as hot instance like the primary one would,
+// combine with WithTTL to retry the primary provider later. It applies to Register and RegisterConfiguration.
+func WithFallback[T any](fn TypedCreateInstanceNoConfigHandler[T]) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.fallback = func(ctx Context, opts *RegistryOpts) (any, error) {
+			return fn(ctx, opts)
+		}
+	}
+}
+
+// fallbackHandler wraps a creation function so fallback creates the instance when it fails.
+func fallbackHandler[T any](fn TypedCreateInstanceNoConfigHandler[T], fallback CreateConfigurationHandler, typeName string) TypedCreateInstanceNoConfigHandler[T] {
+	return func(ctx Context, opts *RegistryOpts) (T, error) {
+		instance, err := fn(ctx, opts)
+		if err == nil {
+			return instance, nil
+		}
+
+		loggerFor(opts).
+			With("type", typeName).
+			With("error", err).
+			Warn("di provider of '%s' failed, creating it with its fallback: %s", typeName, err)
+
+		unknownInstance, fallbackErr := fallback(ctx, opts)
+		if fallbackErr != nil {
+			return instance, errors.Wrap(fallbackErr, "fallback provider of %s failed", typeName, ErrorCreatingDependencyErrorCode).WithNestedError(err)
+		}
+
+		typed, ok := typeAssertFor[T](unknownInstance, opts)
+		if !ok {
+			return instance, errors.New("fallback provider of %s returned a %T", typeName, unknownInstance, DependencyTypeMismatchErrorCode)
+		}
+
+		return typed, nil
+	}
+}
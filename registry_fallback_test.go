@@ -0,0 +1,72 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fallbackCacheTest interface {
+	Backend() string
+}
+
+type fallbackRedisTest struct{}
+
+func (fallbackRedisTest) Backend() string { return "redis" }
+
+type fallbackMemoryTest struct{}
+
+func (fallbackMemoryTest) Backend() string { return "memory" }
+
+type fallbackRedisConfigTest struct {
+	Addr string `json:"addr"`
+}
+
+func (c fallbackRedisConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+func TestWithFallback(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, Register[fallbackCacheTest](func(ctx Context, opts *RegistryOpts) (fallbackCacheTest, error) {
+		if _, err := CreateConfiguration[fallbackRedisConfigTest](ctx, WithRegistry(opts.Registry)); err != nil {
+			return nil, err
+		}
+
+		return fallbackRedisTest{}, nil
+	}, WithRegistry(registry), WithFallback[fallbackCacheTest](func(ctx Context, opts *RegistryOpts) (fallbackCacheTest, error) {
+		return fallbackMemoryTest{}, nil
+	})))
+
+	cache, err := Create[fallbackCacheTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "memory", cache.Backend(), "the fallback is used while the redis configuration is missing")
+
+	t.Run("configuration", func(t *testing.T) {
+		require.NoError(t, RegisterConfiguration[fallbackRedisConfigTest](func(ctx Context, opts *RegistryOpts) (fallbackRedisConfigTest, error) {
+			return ConfigurationLookup[fallbackRedisConfigTest](ctx, opts)
+		}, WithRegistry(registry), WithConfigNodePath("redis"), WithFallback[fallbackRedisConfigTest](func(ctx Context, opts *RegistryOpts) (fallbackRedisConfigTest, error) {
+			return fallbackRedisConfigTest{Addr: "localhost:6379"}, nil
+		})))
+
+		cfg, err := CreateConfiguration[fallbackRedisConfigTest](NewContext(fallbackRedisConfigTest{}), WithRegistry(registry), WithConfigNodePath("redis"))
+		require.NoError(t, err)
+		assert.Equal(t, "localhost:6379", cfg.Addr)
+	})
+
+	t.Run("failing fallback", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, Register[fallbackCacheTest](func(ctx Context, opts *RegistryOpts) (fallbackCacheTest, error) {
+			return nil, errors.New("redis unreachable")
+		}, WithRegistry(registry), WithFallback[fallbackCacheTest](func(ctx Context, opts *RegistryOpts) (fallbackCacheTest, error) {
+			return nil, errors.New("memory exhausted")
+		})))
+
+		_, err := Create[fallbackCacheTest](NewContext(), WithRegistry(registry))
+		assert.ErrorContains(t, err, "fallback provider of di.fallbackCacheTest failed")
+		assert.ErrorContains(t, err, "memory exhausted")
+		assert.ErrorContains(t, err, "redis unreachable")
+	})
+}
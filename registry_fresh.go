@@ -0,0 +1,35 @@
+package di
+
+// WithFreshInstance returns a function that makes a Create or CreatePair call run the factory of T,
+// neither served from nor kept as its hot instance, e.g. for a second database connection used by
+// a migration while the singleton stays untouched:
+//
+//	conn, err := di.Create[*sql.DB](ctx, di.WithFreshInstance())
+//
+// Only T is created anew, the dependencies its factory creates and the configuration of a pair are
+// resolved as usual. Fresh instances are still decorated and post processed, but they belong to
+// the caller, who disposes them when Disposable.
+func WithFreshInstance() func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.freshInstance = true
+	}
+}
+
+// freshInstance runs fn for a creation made WithFreshInstance, bypassing the hot instance of typeName.
+// The instance is recorded for disposal when pooled, the pools being owned by f.
+func freshInstance[T any](ctx Context, f Registry, fn TypedCreateInstanceNoConfigHandler[T], typeName string, opts *RegistryOpts, pooled bool) (any, error) {
+	instance, err := fn(ctx, opts)
+	if err != nil {
+		opts.disposable = nil
+		return nil, err
+	}
+
+	processed, err := postProcessInstance(ctx, f, typeName, instance)
+	if err != nil || !pooled {
+		opts.disposable = nil
+		return processed, err
+	}
+
+	recordDisposable(f, opts, typeName, false)
+	return processed, nil
+}
@@ -0,0 +1,56 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFreshInstance(t *testing.T) {
+	registry := NewTestRegistry(t)
+	calls := 0
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		calls++
+		return &loggerTest{Level: "INFO"}, nil
+	}, WithRegistry(registry)))
+
+	singleton, err := Create[*loggerTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+
+	fresh, err := Create[*loggerTest](NewContext(), WithRegistry(registry), WithFreshInstance())
+	require.NoError(t, err)
+	assert.NotSame(t, singleton, fresh)
+	assert.Equal(t, 2, calls)
+
+	again, err := Create[*loggerTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, singleton, again, "the singleton stays untouched")
+
+	t.Run("pairs", func(t *testing.T) {
+		lookups := 0
+		require.NoError(t, RegisterPair[*overrideClientTest, overrideClientConfigTest](
+			func(ctx Context, opts *RegistryOpts, config overrideClientConfigTest) (*overrideClientTest, error) {
+				return &overrideClientTest{Addr: config.Addr}, nil
+			},
+			func(ctx Context, opts *RegistryOpts) (overrideClientConfigTest, error) {
+				lookups++
+				return overrideClientConfigTest{Addr: "db:5432"}, nil
+			},
+			WithRegistry(registry),
+		))
+
+		singleton, err := CreatePair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+
+		fresh, err := CreatePair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithFreshInstance())
+		require.NoError(t, err)
+		assert.NotSame(t, singleton, fresh)
+		assert.Equal(t, "db:5432", fresh.Addr)
+		assert.Equal(t, 1, lookups, "the configuration is resolved as usual")
+
+		again, err := CreatePair[*overrideClientTest, overrideClientConfigTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Same(t, singleton, again)
+	})
+}
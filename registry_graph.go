@@ -0,0 +1,156 @@
+package di
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// GraphNodeKind tells instances and configurations apart in a Graph.
+type GraphNodeKind string
+
+const (
+	GraphInstance      GraphNodeKind = "instance"
+	GraphConfiguration GraphNodeKind = "configuration"
+)
+
+// GraphNode is a type known to the registry, named as by TypeName with its injection token.
+type GraphNode struct {
+	Name string        `json:"name"`
+	Kind GraphNodeKind `json:"kind"`
+}
+
+// GraphEdge links a type to a dependency its factory resolved.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph is the dependency graph of a registry, see RegistryInspector.Graph.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// WriteJSON writes the graph as JSON.
+func (g Graph) WriteJSON(w io.Writer) error {
+	encoder := gojson.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(g)
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language, configurations drawn as notes.
+func (g Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph di {\n")
+	for _, node := range g.Nodes {
+		shape := "box"
+		if node.Kind == GraphConfiguration {
+			shape = "note"
+		}
+
+		fmt.Fprintf(&b, "\t%s [shape=%s];\n", strconv.Quote(node.Name), shape)
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s;\n", strconv.Quote(edge.From), strconv.Quote(edge.To))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dependencyRecorder is implemented by registries able to remember the dependencies
+// factories resolved, exposed through RegistryInspector.Graph.
+type dependencyRecorder interface {
+	recordDependency(from string, to GraphNode)
+}
+
+// graphOf returns the dependency graph of f, an empty one for registries not implementing RegistryInspector.
+func graphOf(f Registry) Graph {
+	if inspector, ok := f.(RegistryInspector); ok {
+		return inspector.Graph()
+	}
+
+	return Graph{}
+}
+
+// Graph returns the dependency graph of the registry. Nodes are the registered types, and the edges
+// link pairs to their configuration and factories to the dependencies they resolved so far. Factories
+// are opaque until run, so the graph is complete once every registration was created, e.g. after WarmUp.
+func (dif *diRegistry) Graph() Graph {
+	dif.mu.RLock()
+	defer dif.mu.RUnlock()
+
+	nodes := map[string]GraphNodeKind{}
+	edges := map[GraphEdge]struct{}{}
+	for name := range dif.registrations {
+		first, second, isPair := strings.Cut(name, ";")
+		nodes[first] = GraphInstance
+		if isPair {
+			nodes[second] = GraphConfiguration
+			edges[GraphEdge{From: first, To: second}] = struct{}{}
+		}
+	}
+
+	for name := range dif.configurationRegistrations {
+		if _, _, isPair := strings.Cut(name, ";"); !isPair {
+			nodes[name] = GraphConfiguration
+		}
+	}
+
+	for edge, kind := range dif.dependencies {
+		if _, known := nodes[edge.From]; !known {
+			nodes[edge.From] = GraphInstance
+		}
+
+		if _, known := nodes[edge.To]; !known {
+			nodes[edge.To] = kind
+		}
+
+		edges[edge] = struct{}{}
+	}
+
+	graph := Graph{Nodes: make([]GraphNode, 0, len(nodes)), Edges: make([]GraphEdge, 0, len(edges))}
+	for name, kind := range nodes {
+		graph.Nodes = append(graph.Nodes, GraphNode{Name: name, Kind: kind})
+	}
+
+	for edge := range edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Name < graph.Nodes[j].Name
+	})
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+
+	return graph
+}
+
+func (dif *diRegistry) recordDependency(from string, to GraphNode) {
+	edge := GraphEdge{From: from, To: to.Name}
+
+	dif.mu.RLock()
+	_, known := dif.dependencies[edge]
+	dif.mu.RUnlock()
+	if known {
+		return
+	}
+
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	dif.dependencies[edge] = to.Kind
+}
@@ -0,0 +1,64 @@
+package di
+
+import (
+	"bytes"
+	"testing"
+
+	gojson "github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph(t *testing.T) {
+	registry := NewTestRegistry(t)
+
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{}, nil
+	}, WithRegistry(registry), WithToken("audit")))
+	require.NoError(t, RegisterPair[*databaseTest, databaseConfigTest](
+		func(ctx Context, opts *RegistryOpts, cfg databaseConfigTest) (*databaseTest, error) {
+			return &databaseTest{ConnectionString: cfg.ConnectionString}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (databaseConfigTest, error) {
+			return databaseConfigTest{ConnectionString: "postgres://"}, nil
+		},
+		WithRegistry(registry)))
+	require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+		db, err := CreatePair[*databaseTest, databaseConfigTest](ctx, WithRegistry(registry))
+		if err != nil {
+			return nil, err
+		}
+
+		logger, err := Create[*loggerTest](ctx, WithRegistry(registry), WithToken("audit"))
+		return &serviceTest{DB: db, Logger: logger}, err
+	}, WithRegistry(registry)))
+
+	_, err := Create[*serviceTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+
+	service, db, dbConfig, logger := TypeName[*serviceTest](), TypeName[*databaseTest](), TypeName[databaseConfigTest](), TypeName[*loggerTest]("audit")
+	graph := registry.Graph()
+	assert.Equal(t, []GraphNode{
+		{Name: logger, Kind: GraphInstance},
+		{Name: dbConfig, Kind: GraphConfiguration},
+		{Name: db, Kind: GraphInstance},
+		{Name: service, Kind: GraphInstance},
+	}, graph.Nodes)
+	assert.Equal(t, []GraphEdge{
+		{From: db, To: dbConfig},
+		{From: service, To: logger},
+		{From: service, To: db},
+	}, graph.Edges)
+
+	var dot bytes.Buffer
+	require.NoError(t, graph.WriteDOT(&dot))
+	assert.Contains(t, dot.String(), `"di.serviceTest" -> "audit:di.loggerTest";`)
+	assert.Contains(t, dot.String(), `"di.databaseConfigTest" [shape=note];`)
+
+	var encoded bytes.Buffer
+	require.NoError(t, graph.WriteJSON(&encoded))
+
+	var decoded Graph
+	require.NoError(t, gojson.Unmarshal(encoded.Bytes(), &decoded))
+	assert.Equal(t, graph, decoded)
+}
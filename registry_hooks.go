@@ -0,0 +1,111 @@
+package di
+
+import (
+	"strings"
+	"time"
+)
+
+// CreateEvent describes a Registry.Create or Registry.CreateConfiguration call to hooks and tracers.
+// HotInstance, Duration and Err are only set once the call returns.
+type CreateEvent struct {
+	TypeName       string
+	InjectionToken InjectionToken
+	Breadcrumbs    []string
+	Configuration  bool
+	Registered     bool // a registration exists under TypeName
+	TokenFallback  bool // the call resolves the untokened registration of a creation asked with InjectionToken
+	HotInstance    bool // the call was served by an existing hot instance rather than a factory
+	Duration       time.Duration
+	Err            error
+}
+
+// Hook is called around every Create and CreateConfiguration call of a registry, see RegistryInterceptor.AddHook.
+// Either function may be nil. Calls that miss, like the tokened lookup before the untokened fallback,
+// are reported too with their error.
+type Hook struct {
+	BeforeCreate func(ctx Context, event CreateEvent)
+	AfterCreate  func(ctx Context, event CreateEvent)
+}
+
+// Tracer opens a span around every Create and CreateConfiguration call of a registry, see WithTracer.
+// StartCreate returns the context the creation runs with, carrying the span so the creations nested
+// in the factory are its children, and the function ending the span with the outcome.
+type Tracer interface {
+	StartCreate(ctx Context, event CreateEvent) (Context, func(event CreateEvent))
+}
+
+// WithTracer makes the registry trace its creations with tracer, see the diotel package for OpenTelemetry.
+func WithTracer(tracer Tracer) RegistryOption {
+	return func(r *diRegistry) {
+		r.tracer = tracer
+	}
+}
+
+// AddHook adds a hook called around every creation made by the registry, in the order added.
+func (dif *diRegistry) AddHook(hook Hook) {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	dif.hooks = append(dif.hooks, hook)
+}
+
+// observeCreate calls the before hooks and starts the tracer span of a creation. It returns the
+// context to create with and the function reporting the outcome to the tracer and after hooks.
+// The creation records in opts whether it was served by a hot instance.
+func (dif *diRegistry) observeCreate(ctx Context, event CreateEvent, opts *RegistryOpts) (Context, func(err error)) {
+	dif.mu.RLock()
+	hooks, tracer, metrics := dif.hooks, dif.tracer, dif.metrics
+	dif.mu.RUnlock()
+
+	if len(hooks) == 0 && tracer == nil && metrics == nil {
+		return ctx, func(error) {}
+	}
+
+	return dif.observeCreateWith(ctx, event, opts, hooks, tracer, metrics)
+}
+
+// observeCreateWith is the part of observeCreate run when something observes the creations,
+// kept apart so the unobserved ones don't move event to the heap.
+func (dif *diRegistry) observeCreateWith(ctx Context, event CreateEvent, opts *RegistryOpts, hooks []Hook, tracer Tracer, metrics *registryMetrics) (Context, func(err error)) {
+	if opts != nil {
+		opts.servedHot = false
+	}
+
+	event.Breadcrumbs = ctx.Breadcrumbs()
+
+	for _, hook := range hooks {
+		if hook.BeforeCreate != nil {
+			hook.BeforeCreate(ctx, event)
+		}
+	}
+
+	endSpan := func(CreateEvent) {}
+	if tracer != nil {
+		ctx, endSpan = tracer.StartCreate(ctx, event)
+	}
+
+	start := time.Now()
+	return ctx, func(err error) {
+		event.Duration, event.Err = time.Since(start), err
+		event.HotInstance = opts != nil && opts.servedHot
+		endSpan(event)
+		if metrics != nil {
+			metrics.observe(event)
+		}
+		for _, hook := range hooks {
+			if hook.AfterCreate != nil {
+				hook.AfterCreate(ctx, event)
+			}
+		}
+	}
+}
+
+func createEvent(typeNameOf string, opts *RegistryOpts, configuration bool, registered bool) CreateEvent {
+	event := CreateEvent{TypeName: typeNameOf, Configuration: configuration, Registered: registered}
+	if opts != nil {
+		event.InjectionToken = opts.InjectionToken
+		event.TokenFallback = len(opts.InjectionToken) > 0 && !strings.HasPrefix(typeNameOf, opts.InjectionToken.String()+":")
+	}
+
+	return event
+}
@@ -0,0 +1,67 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddHook(t *testing.T) {
+	registry := NewTestRegistry(t)
+	var before, after []CreateEvent
+	registry.AddHook(Hook{
+		BeforeCreate: func(ctx Context, event CreateEvent) { before = append(before, event) },
+		AfterCreate:  func(ctx Context, event CreateEvent) { after = append(after, event) },
+	})
+	registry.AddHook(Hook{})
+
+	require.NoError(t, RegisterPair[*databaseTest, databaseConfigTest](
+		func(ctx Context, opts *RegistryOpts, cfg databaseConfigTest) (*databaseTest, error) {
+			return nil, errors.New("connection refused")
+		},
+		func(ctx Context, opts *RegistryOpts) (databaseConfigTest, error) {
+			return databaseConfigTest{}, nil
+		},
+		WithRegistry(registry), WithToken("replica")))
+
+	_, err := CreatePair[*databaseTest, databaseConfigTest](NewContext(), WithRegistry(registry), WithToken("replica"))
+	require.Error(t, err)
+
+	require.Len(t, before, 2)
+	require.Len(t, after, 2)
+
+	assert.Equal(t, PairTypeName(TypeName[databaseConfigTest]("replica"), TypeName[*databaseTest]("replica")), after[0].TypeName)
+	assert.Equal(t, InjectionToken("replica"), after[0].InjectionToken)
+	assert.True(t, after[0].Configuration)
+	assert.NoError(t, after[0].Err)
+
+	assert.Equal(t, PairTypeName(TypeName[*databaseTest]("replica"), TypeName[databaseConfigTest]("replica")), after[1].TypeName)
+	assert.False(t, after[1].Configuration)
+	assert.ErrorContains(t, after[1].Err, "connection refused")
+	assert.Nil(t, before[1].Err)
+	assert.Zero(t, before[1].Duration)
+}
+
+func TestAddHookHotInstance(t *testing.T) {
+	registry := NewTestRegistry(t)
+	var after []CreateEvent
+	registry.AddHook(Hook{AfterCreate: func(ctx Context, event CreateEvent) { after = append(after, event) }})
+
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{Level: "INFO"}, nil
+	}, WithRegistry(registry)))
+
+	_, err := Create[*loggerTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	_, err = Create[*loggerTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	_, err = Create[*loggerTest](NewContext(), WithRegistry(registry), WithFreshInstance())
+	require.NoError(t, err)
+
+	require.Len(t, after, 3)
+	assert.False(t, after[0].HotInstance, "created by the factory")
+	assert.True(t, after[1].HotInstance)
+	assert.False(t, after[2].HotInstance, "fresh instances skip the hot instance")
+}
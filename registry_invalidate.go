@@ -0,0 +1,115 @@
+package di
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// InvalidateHot drops the hot instances of type T created with the token of the options, so the
+// next creation runs the factory again, e.g. after a config change or a broken connection.
+// The registrations of T under the token and without it, as the untokened fallback, are both
+// covered, pairs have their configuration dropped along with the instance.
+// It fails when T is not registered, it's a no-op when T has no hot instance.
+// The registry must implement HotInstanceEvictor and RegistryInspector.
+func InvalidateHot[T any](options ...func(opts *RegistryOpts)) error {
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	f := registryOpts.Registry
+	if f == nil {
+		f = DefaultRegistry()
+	}
+
+	evictor, ok := f.(HotInstanceEvictor)
+	if !ok {
+		return errors.New("registry of type %T cannot invalidate hot instances", f, ErrorCreatingDependencyErrorCode)
+	}
+
+	names := []string{TypeNameIn[T](f, registryOpts.InjectionToken), TypeNameIn[T](f)}
+	registered := false
+	for _, info := range registrationsIn(f) {
+		first, second, isPair := strings.Cut(info.Name, ";")
+		instanceOfT := !info.Configuration && (first == names[0] || first == names[1])
+		configOfT := info.Configuration && isPair && (second == names[0] || second == names[1])
+		if !instanceOfT && !configOfT {
+			continue
+		}
+
+		registered = registered || instanceOfT
+		evictor.InvalidateHotInstance(&registryOpts, info.Name)
+	}
+
+	if !registered {
+		return errors.New("dependency not registered: %s", names[0], DependencyMissingErrorCode)
+	}
+
+	return nil
+}
+
+// InvalidateHotInstance drops the hot instance created from the registration name with the
+// token of opts, reporting whether there was one.
+func (dif *diRegistry) InvalidateHotInstance(opts *RegistryOpts, name string) bool {
+	key := hotInstanceKey(opts, dif.registryName(name))
+
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	_, ok := dif.hotInstances[key]
+	dif.dropHotKeyLocked(key)
+	return ok
+}
+
+// ClearHotInstances drops every hot instance of the registry, keeping the registrations,
+// so every type is created again on its next use.
+func (dif *diRegistry) ClearHotInstances() {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	for key := range dif.hotInstances {
+		dif.retireDisposableLocked(key)
+	}
+
+	dif.hotInstances = map[string]any{}
+	dif.hotExpirations = map[string]time.Time{}
+	dif.hotTags = map[string][]string{}
+	dif.configLinks = map[string]configLink{}
+	dif.pinnedConfigs = map[string]any{}
+}
+
+// EvictByTag drops the hot instances tagged with tag by their provider, see RegistryOpts.Tag,
+// so they're created again on their next use. It returns the number of instances dropped.
+func (dif *diRegistry) EvictByTag(tag string) int {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	evicted := 0
+	for key, tags := range dif.hotTags {
+		if slices.Contains(tags, tag) {
+			dif.dropHotKeyLocked(key)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+func (dif *diRegistry) tagHotInstance(opts *RegistryOpts, typeName string, tags []string) {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	key := hotInstanceKey(opts, typeName)
+	if _, ok := dif.hotInstances[key]; ok {
+		dif.hotTags[key] = tags
+	}
+}
@@ -0,0 +1,89 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidateHot(t *testing.T) {
+	registry := NewTestRegistry(t)
+	builds := map[string]int{}
+
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		builds["logger"]++
+		return &loggerTest{}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, RegisterPair[*databaseTest, databaseConfigTest](
+		func(ctx Context, opts *RegistryOpts, cfg databaseConfigTest) (*databaseTest, error) {
+			builds["database"]++
+			return &databaseTest{}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (databaseConfigTest, error) {
+			builds["config"]++
+			return databaseConfigTest{}, nil
+		},
+		WithRegistry(registry)))
+
+	create := func(options ...func(opts *RegistryOpts)) {
+		_, err := Create[*loggerTest](NewContext(), append(options, WithRegistry(registry))...)
+		require.NoError(t, err)
+		_, err = CreatePair[*databaseTest, databaseConfigTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+	}
+
+	create()
+	create(WithToken("audit"))
+	assert.Equal(t, map[string]int{"logger": 2, "database": 1, "config": 1}, builds)
+
+	require.NoError(t, InvalidateHot[*loggerTest](WithRegistry(registry), WithToken("audit")))
+	require.NoError(t, InvalidateHot[*databaseTest](WithRegistry(registry)))
+	create()
+	create(WithToken("audit"))
+	assert.Equal(t, map[string]int{"logger": 3, "database": 2, "config": 2}, builds)
+
+	registry.ClearHotInstances()
+	create()
+	assert.Equal(t, map[string]int{"logger": 4, "database": 3, "config": 3}, builds)
+
+	assert.Error(t, InvalidateHot[*serviceTest](WithRegistry(registry)))
+}
+
+func TestEvictByTag(t *testing.T) {
+	registry := NewTestRegistry(t)
+	builds := map[string]int{}
+
+	require.NoError(t, Register[*databaseTest](func(ctx Context, opts *RegistryOpts) (*databaseTest, error) {
+		builds["database"]++
+		opts.Tag("uses-db-primary")
+		return &databaseTest{}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+		builds["service"]++
+		db, err := Create[*databaseTest](ctx, WithRegistry(registry))
+		opts.Tag("uses-db-primary", "api")
+		return &serviceTest{DB: db}, err
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		builds["logger"]++
+		return &loggerTest{}, nil
+	}, WithRegistry(registry)))
+
+	create := func() {
+		_, err := Create[*serviceTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		_, err = Create[*loggerTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+	}
+
+	create()
+	assert.Equal(t, 2, registry.EvictByTag("uses-db-primary"))
+	assert.Zero(t, registry.EvictByTag("uses-db-primary"))
+	create()
+	assert.Equal(t, map[string]int{"database": 2, "service": 2, "logger": 1}, builds)
+
+	assert.Equal(t, 1, registry.EvictByTag("api"))
+	create()
+	assert.Equal(t, map[string]int{"database": 2, "service": 3, "logger": 1}, builds)
+}
@@ -0,0 +1,23 @@
+package di
+
+// CreateKeyed creates T like Create, keeping a hot instance per runtime key, e.g. per region S3
+// clients or per topic producers, without registering a token for every possible value:
+//
+//	di.Register[*s3.Client](func(ctx di.Context, opts *di.RegistryOpts) (*s3.Client, error) {
+//		return newS3Client(opts.Key())
+//	})
+//
+//	client, err := di.CreateKeyed[*s3.Client](ctx, "eu-west-1")
+//
+// The factory reads the key from the opts it's handed with RegistryOpts.Key. The dependencies it
+// creates are not keyed. An empty key creates the instance shared by Create.
+func CreateKeyed[T any](ctx Context, key string, options ...func(opts *RegistryOpts)) (T, error) {
+	return Create[T](ctx, append(options[:len(options):len(options)], func(opts *RegistryOpts) {
+		opts.key = key
+	})...)
+}
+
+// Key returns the runtime key of the instance being created by CreateKeyed, empty otherwise.
+func (o *RegistryOpts) Key() string {
+	return o.key
+}
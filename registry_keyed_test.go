@@ -0,0 +1,46 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateKeyed(t *testing.T) {
+	registry := NewTestRegistry(t)
+	calls := 0
+	require.NoError(t, Register[*overrideClientTest](func(ctx Context, opts *RegistryOpts) (*overrideClientTest, error) {
+		calls++
+		return &overrideClientTest{Addr: "s3." + opts.Key() + ".amazonaws.com"}, nil
+	}, WithRegistry(registry)))
+
+	eu, err := CreateKeyed[*overrideClientTest](NewContext(), "eu-west-1", WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "s3.eu-west-1.amazonaws.com", eu.Addr)
+
+	us, err := CreateKeyed[*overrideClientTest](NewContext(), "us-east-1", WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "s3.us-east-1.amazonaws.com", us.Addr)
+
+	again, err := CreateKeyed[*overrideClientTest](NewContext(), "eu-west-1", WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, eu, again, "instances are cached per key")
+	assert.Equal(t, 2, calls)
+
+	shared, err := Create[*overrideClientTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "s3..amazonaws.com", shared.Addr)
+
+	unkeyed, err := CreateKeyed[*overrideClientTest](NewContext(), "", WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, shared, unkeyed)
+
+	require.NoError(t, Replace[*overrideClientTest](func(ctx Context, opts *RegistryOpts) (*overrideClientTest, error) {
+		return &overrideClientTest{Addr: "minio." + opts.Key()}, nil
+	}, WithRegistry(registry)))
+
+	replaced, err := CreateKeyed[*overrideClientTest](NewContext(), "eu-west-1", WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "minio.eu-west-1", replaced.Addr, "keyed instances are dropped with their registration")
+}
@@ -0,0 +1,244 @@
+package di
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// Initializable is implemented by the instances needing to run code once constructed,
+// Init is called right after their factory returns, before they're decorated or kept as hot instance.
+// An error fails the creation.
+type Initializable interface {
+	Init(ctx Context) error
+}
+
+// Disposable is implemented by the instances holding resources to release, Dispose is called
+// by RegistryLifecycle.Shutdown on the hot instances of the registry, consumers before the dependencies
+// recorded in RegistryInspector.Graph, otherwise in the reverse order of creation. Hot instances dropped
+// before, invalidated, evicted, rebuilt once expired or cleared, are disposed in the background
+// right away, while their consumers may still hold them. Fresh instances belong to their caller
+// and are not disposed by the registry, pooled ones are by RegistryLifecycle.Shutdown.
+type Disposable interface {
+	Dispose(ctx Context) error
+}
+
+// disposableRecorder is implemented by registries able to dispose the instances they create, see Disposable.
+type disposableRecorder interface {
+	recordDisposable(opts *RegistryOpts, typeName string, instance Disposable, hot bool)
+}
+
+type disposable struct {
+	key      string // hot instance key, empty for the pooled instances
+	typeName string
+	instance Disposable
+}
+
+// lifecycleHandler wraps a creation function so its instances are initialized and, as Initializable
+// and Disposable, left in opts to be recorded for disposal once their creation fully succeeded,
+// see recordDisposable.
+func lifecycleHandler[T any](fn TypedCreateInstanceNoConfigHandler[T], typeName string) TypedCreateInstanceNoConfigHandler[T] {
+	return func(ctx Context, opts *RegistryOpts) (T, error) {
+		instance, err := fn(ctx, opts)
+		if err != nil {
+			return instance, err
+		}
+
+		return initializeInstance(ctx, opts, typeName, instance)
+	}
+}
+
+// lifecyclePairHandler is the RegisterPair counterpart of lifecycleHandler.
+func lifecyclePairHandler[T any, CT any](fn TypedCreateInstanceHandler[T, CT], typeName string) TypedCreateInstanceHandler[T, CT] {
+	return func(ctx Context, opts *RegistryOpts, config CT) (T, error) {
+		instance, err := fn(ctx, opts, config)
+		if err != nil {
+			return instance, err
+		}
+
+		return initializeInstance(ctx, opts, typeName, instance)
+	}
+}
+
+// initializeInstance calls Init on instance when it's Initializable and leaves it in opts when it's Disposable.
+func initializeInstance[T any](ctx Context, opts *RegistryOpts, typeName string, instance T) (T, error) {
+	if initializable, ok := any(instance).(Initializable); ok {
+		if err := initializable.Init(ctx); err != nil {
+			return instance, errors.Wrap(err, "failed to initialize %s", typeName, ErrorCreatingDependencyErrorCode)
+		}
+	}
+
+	if d, ok := any(instance).(Disposable); ok && opts != nil {
+		opts.disposable = d
+	}
+
+	return instance, nil
+}
+
+// recordDisposable records in f the Disposable instance left in opts by initializeInstance, once
+// its creation fully succeeded, tied to its hot instance when hot.
+func recordDisposable(f Registry, opts *RegistryOpts, typeName string, hot bool) {
+	if opts == nil || opts.disposable == nil {
+		return
+	}
+
+	d := opts.disposable
+	opts.disposable = nil
+	if recorder, ok := f.(disposableRecorder); ok {
+		recorder.recordDisposable(opts, typeName, d, hot)
+	}
+}
+
+func (dif *diRegistry) recordDisposable(opts *RegistryOpts, typeName string, instance Disposable, hot bool) {
+	d := disposable{typeName: typeName, instance: instance}
+	if hot {
+		d.key = hotInstanceKey(opts, dif.registryName(typeName))
+	}
+
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	dif.disposables = append(dif.disposables, d)
+	if _, kept := dif.hotInstances[d.key]; hot && !kept {
+		// dropped while being recorded
+		dif.retireDisposableLocked(d.key)
+	}
+}
+
+// retireDisposableLocked stops tracking the disposable kept as the hot instance key, dropped or
+// replaced, and disposes it in the background. The caller must hold the write lock.
+func (dif *diRegistry) retireDisposableLocked(key string) {
+	i := slices.IndexFunc(dif.disposables, func(d disposable) bool {
+		return d.key == key
+	})
+	if len(key) == 0 || i < 0 {
+		return
+	}
+
+	d := dif.disposables[i]
+	dif.disposables = slices.Delete(dif.disposables, i, i+1)
+
+	dif.retiring.Add(1)
+	go func() {
+		defer dif.retiring.Done()
+
+		if err := dif.disposeOne(NewContext(), d); err != nil {
+			dif.logger().With("type", d.typeName).Warn("di failed to dispose the dropped hot instance '%s': %s", d.typeName, err)
+		}
+	}()
+}
+
+// WithDisposeTimeout bounds the time each Dispose may take during RegistryLifecycle.Shutdown. Once d elapses
+// the instance is reported with DisposeTimeoutErrorCode and the shutdown moves on to the next one,
+// while its Dispose sees its context done. Dispose implementations ignoring it keep running.
+func WithDisposeTimeout(d time.Duration) RegistryOption {
+	return func(r *diRegistry) {
+		r.disposeTimeout = d
+	}
+}
+
+// dispose waits for the hot instances dropped to be disposed, then calls Dispose on the instances
+// recorded so far, each one once, in the order of disposalOrder, and reports every failure at once.
+func (dif *diRegistry) dispose(ctx Context) error {
+	retired := make(chan struct{})
+	go func() {
+		dif.retiring.Wait()
+		close(retired)
+	}()
+
+	select {
+	case <-retired:
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "shutdown interrupted disposing dropped hot instances", ErrorCreatingDependencyErrorCode)
+	}
+
+	dif.mu.Lock()
+	disposables := dif.disposables
+	dif.disposables = nil
+	dif.mu.Unlock()
+
+	var errs []error
+	for _, d := range dif.disposalOrder(disposables) {
+		if err := dif.disposeOne(ctx, d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// disposalOrder sorts disposables so the consumers come before the dependencies they resolved,
+// as recorded for Graph, e.g. a service before its database pool even when the pool was recreated
+// after it. Pairs are ordered as their instance type, the node of the graph. Disposables unrelated
+// through the graph stay most recent first.
+func (dif *diRegistry) disposalOrder(disposables []disposable) []disposable {
+	dif.mu.RLock()
+	dependsOn := map[string][]string{}
+	for edge := range dif.dependencies {
+		dependsOn[edge.From] = append(dependsOn[edge.From], edge.To)
+	}
+	dif.mu.RUnlock()
+
+	heights := map[string]int{}
+	var heightOf func(name string) int
+	heightOf = func(name string) int {
+		if height, seen := heights[name]; seen {
+			return height
+		}
+
+		heights[name] = 0 // breaks dependency cycles
+		height := 0
+		for _, dependency := range dependsOn[name] {
+			height = max(height, heightOf(dependency)+1)
+		}
+
+		heights[name] = height
+		return height
+	}
+
+	nodeOf := func(d disposable) string {
+		node, _, _ := strings.Cut(d.typeName, ";")
+		return node
+	}
+
+	ordered := slices.Clone(disposables)
+	slices.Reverse(ordered)
+	slices.SortStableFunc(ordered, func(a, b disposable) int {
+		return cmp.Compare(heightOf(nodeOf(b)), heightOf(nodeOf(a)))
+	})
+
+	return ordered
+}
+
+// disposeOne calls Dispose on d, within the WithDisposeTimeout of the registry if any.
+func (dif *diRegistry) disposeOne(ctx Context, d disposable) error {
+	if dif.disposeTimeout <= 0 {
+		if err := d.instance.Dispose(ctx); err != nil {
+			return errors.Wrap(err, "failed to dispose %s", d.typeName, ErrorCreatingDependencyErrorCode)
+		}
+
+		return nil
+	}
+
+	timedCtx, cancel := ctx.WithDeadline(time.Now().Add(dif.disposeTimeout))
+	defer cancel()
+
+	disposed := make(chan error, 1)
+	go func() {
+		disposed <- d.instance.Dispose(timedCtx)
+	}()
+
+	select {
+	case err := <-disposed:
+		if err != nil {
+			return errors.Wrap(err, "failed to dispose %s", d.typeName, ErrorCreatingDependencyErrorCode)
+		}
+
+		return nil
+	case <-timedCtx.Done():
+		return errors.Wrap(timedCtx.Err(), "failed to dispose %s within %s", d.typeName, dif.disposeTimeout, DisposeTimeoutErrorCode)
+	}
+}
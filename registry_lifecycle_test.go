@@ -0,0 +1,193 @@
+package di
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lifecycleComponentTest struct {
+	name     string
+	events   *[]string
+	initErr  error
+	disposed int
+}
+
+func (c *lifecycleComponentTest) Init(ctx Context) error {
+	*c.events = append(*c.events, "init "+c.name)
+	return c.initErr
+}
+
+func (c *lifecycleComponentTest) Dispose(ctx Context) error {
+	c.disposed++
+	*c.events = append(*c.events, "dispose "+c.name)
+	if c.name == "cache" {
+		return errors.New("flush failed")
+	}
+
+	return nil
+}
+
+func TestLifecycle(t *testing.T) {
+	registry := NewTestRegistry(t)
+	var events []string
+	components := map[string]*lifecycleComponentTest{}
+	for _, name := range []string{"database", "cache", "broken"} {
+		component := &lifecycleComponentTest{name: name, events: &events}
+		if name == "broken" {
+			component.initErr = errors.New("port in use")
+		}
+
+		components[name] = component
+		require.NoError(t, Register[*lifecycleComponentTest](func(ctx Context, opts *RegistryOpts) (*lifecycleComponentTest, error) {
+			return component, nil
+		}, WithRegistry(registry), WithToken(InjectionToken(name))))
+	}
+
+	for range 2 {
+		for _, name := range []string{"database", "cache"} {
+			component, err := Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry), WithToken(InjectionToken(name)))
+			require.NoError(t, err)
+			assert.Same(t, components[name], component)
+		}
+	}
+
+	_, err := Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry), WithToken("broken"))
+	assert.ErrorContains(t, err, "failed to initialize broken:di.lifecycleComponentTest")
+	assert.Equal(t, []string{"init database", "init cache", "init broken"}, events, "hot instances are initialized once")
+
+	events = nil
+	err = registry.Shutdown(NewContext())
+	assert.ErrorContains(t, err, "failed to dispose cache:di.lifecycleComponentTest")
+	assert.Equal(t, []string{"dispose cache", "dispose database"}, events, "instances are disposed in reverse creation order")
+
+	require.NoError(t, registry.Shutdown(NewContext()))
+	assert.Equal(t, 1, components["database"].disposed)
+}
+
+type stuckDisposableTest struct {
+	released chan struct{}
+}
+
+func (s *stuckDisposableTest) Dispose(ctx Context) error {
+	<-ctx.Done()
+	close(s.released)
+	return ctx.Err()
+}
+
+func TestShutdownOrder(t *testing.T) {
+	registry := NewTestRegistry(t)
+	var events []string
+
+	require.NoError(t, Register[*lifecycleComponentTest](func(ctx Context, opts *RegistryOpts) (*lifecycleComponentTest, error) {
+		return &lifecycleComponentTest{name: "pool", events: &events}, nil
+	}, WithRegistry(registry), WithToken("pool")))
+	require.NoError(t, Register[*lifecycleComponentTest](func(ctx Context, opts *RegistryOpts) (*lifecycleComponentTest, error) {
+		if _, err := Create[*lifecycleComponentTest](ctx, WithRegistry(registry), WithToken("pool")); err != nil {
+			return nil, err
+		}
+
+		return &lifecycleComponentTest{name: "service", events: &events}, nil
+	}, WithRegistry(registry), WithToken("service")))
+
+	_, err := Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry), WithToken("service"))
+	require.NoError(t, err)
+
+	events = nil
+	require.NoError(t, InvalidateHot[*lifecycleComponentTest](WithRegistry(registry), WithToken("pool")))
+	registry.retiring.Wait()
+	_, err = Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry), WithToken("pool"))
+	require.NoError(t, err)
+
+	require.NoError(t, registry.Shutdown(NewContext()))
+	assert.Equal(t, []string{"dispose pool", "init pool", "dispose service", "dispose pool"}, events, "dropped hot instances are disposed right away, consumers before the dependencies they resolved")
+
+	t.Run("pair consumer", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		var events []string
+
+		require.NoError(t, Register[*lifecycleComponentTest](func(ctx Context, opts *RegistryOpts) (*lifecycleComponentTest, error) {
+			return &lifecycleComponentTest{name: "pool", events: &events}, nil
+		}, WithRegistry(registry), WithToken("pool")))
+		require.NoError(t, RegisterPair[*lifecycleComponentTest, overrideClientConfigTest](
+			func(ctx Context, opts *RegistryOpts, config overrideClientConfigTest) (*lifecycleComponentTest, error) {
+				if _, err := Create[*lifecycleComponentTest](ctx, WithRegistry(registry), WithToken("pool")); err != nil {
+					return nil, err
+				}
+
+				return &lifecycleComponentTest{name: "service", events: &events}, nil
+			},
+			func(ctx Context, opts *RegistryOpts) (overrideClientConfigTest, error) {
+				return overrideClientConfigTest{Addr: "localhost:5432"}, nil
+			},
+			WithRegistry(registry), WithToken("service"),
+		))
+
+		_, err := CreatePair[*lifecycleComponentTest, overrideClientConfigTest](NewContext(), WithRegistry(registry), WithToken("service"))
+		require.NoError(t, err)
+
+		events = nil
+		require.NoError(t, InvalidateHot[*lifecycleComponentTest](WithRegistry(registry), WithToken("pool")))
+		registry.retiring.Wait()
+		_, err = Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry), WithToken("pool"))
+		require.NoError(t, err)
+
+		require.NoError(t, registry.Shutdown(NewContext()))
+		assert.Equal(t, []string{"dispose pool", "init pool", "dispose service", "dispose pool"}, events, "pairs are disposed before the dependencies they resolved")
+	})
+
+	t.Run("tracked instances", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		var events []string
+		require.NoError(t, Register[*lifecycleComponentTest](func(ctx Context, opts *RegistryOpts) (*lifecycleComponentTest, error) {
+			return &lifecycleComponentTest{name: "conn", events: &events}, nil
+		}, WithRegistry(registry)))
+
+		fresh, err := Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry), WithFreshInstance())
+		require.NoError(t, err)
+		hot, err := Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+
+		registry.AddPostProcessor(func(ctx Context, typeName string, instance any) (any, error) {
+			return nil, errors.New("rejected")
+		})
+		registry.ClearHotInstances()
+		registry.retiring.Wait()
+		_, err = Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry))
+		assert.ErrorContains(t, err, "rejected")
+
+		require.NoError(t, registry.Shutdown(NewContext()))
+		assert.Equal(t, 1, hot.disposed, "cleared hot instances are disposed")
+		assert.Equal(t, 0, fresh.disposed, "fresh instances belong to their caller")
+		assert.Equal(t, []string{"init conn", "init conn", "dispose conn", "init conn"}, events, "failed creations are not disposed")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		registry := NewRegistry(WithDisposeTimeout(10 * time.Millisecond))
+		var events []string
+		stuck := &stuckDisposableTest{released: make(chan struct{})}
+
+		require.NoError(t, Register[*stuckDisposableTest](func(ctx Context, opts *RegistryOpts) (*stuckDisposableTest, error) {
+			return stuck, nil
+		}, WithRegistry(registry)))
+		require.NoError(t, Register[*lifecycleComponentTest](func(ctx Context, opts *RegistryOpts) (*lifecycleComponentTest, error) {
+			return &lifecycleComponentTest{name: "cache", events: &events}, nil
+		}, WithRegistry(registry)))
+
+		_, err := Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		_, err = Create[*stuckDisposableTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+
+		events = nil
+		err = registry.Shutdown(NewContext())
+		assert.ErrorContains(t, err, "DisposeTimeoutErrorCode")
+		assert.ErrorContains(t, err, "failed to dispose di.stuckDisposableTest within 10ms")
+		assert.ErrorContains(t, err, "flush failed", "every failure is reported")
+		assert.Equal(t, []string{"dispose cache"}, events, "the shutdown moves on past a stuck instance")
+		<-stuck.released
+	})
+}
@@ -0,0 +1,98 @@
+package di
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Lifetime tells how long the instances of a registration live.
+type Lifetime string
+
+const (
+	// LifetimeSingleton registrations are created once per registry and kept as hot instances
+	LifetimeSingleton Lifetime = "singleton"
+	// LifetimePooled registrations are created up to a bounded number of instances, leased by Acquire
+	LifetimePooled Lifetime = "pooled"
+)
+
+// RegistrationInfo describes a registration, see RegistryInspector.List.
+type RegistrationInfo struct {
+	Name           string         `json:"name"`      // registration name, as built by TypeName and PairTypeName
+	TypeName       string         `json:"type_name"` // untokened name of the registered type, the instance type for pairs
+	InjectionToken InjectionToken `json:"injection_token,omitempty"`
+	Configuration  bool           `json:"configuration"` // registered through RegisterConfiguration, or the configuration side of a pair
+	HasConfig      bool           `json:"has_config"`    // instance registered through RegisterPair
+	ConfigNodePath string         `json:"config_node_path,omitempty"`
+	Lifetime       Lifetime       `json:"lifetime"`
+	Conditionals   int            `json:"conditionals"`
+	Eager          bool           `json:"eager"`
+	TTL            time.Duration  `json:"ttl,omitempty"`       // hot instance expiration, see WithTTL
+	PoolSize       int            `json:"pool_size,omitempty"` // instances pooled, see WithPool
+	CallSite       string         `json:"call_site,omitempty"` // file:line of the registration call
+}
+
+// List describes every registration of the registry, sorted by name, instances before
+// configurations sharing it.
+func (dif *diRegistry) List() []RegistrationInfo {
+	dif.mu.RLock()
+	defer dif.mu.RUnlock()
+
+	infos := make([]RegistrationInfo, 0, len(dif.registrations)+len(dif.configurationRegistrations))
+	for name, reg := range dif.registrations {
+		opts := reg.opts
+		if reg.creator == nil && len(reg.conditionals) > 0 {
+			opts = reg.conditionals[0].opts
+		}
+
+		info := describeRegistration(name, opts, len(reg.conditionals))
+		info.Eager = reg.warm != nil
+		infos = append(infos, info)
+	}
+
+	for name, reg := range dif.configurationRegistrations {
+		opts := reg.opts
+		if reg.creator == nil && len(reg.conditionals) > 0 {
+			opts = reg.conditionals[0].opts
+		}
+
+		info := describeRegistration(name, opts, len(reg.conditionals))
+		info.Configuration, info.HasConfig = true, false
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Name != infos[j].Name {
+			return infos[i].Name < infos[j].Name
+		}
+
+		return !infos[i].Configuration
+	})
+
+	return infos
+}
+
+func describeRegistration(name string, opts *RegistryOpts, conditionals int) RegistrationInfo {
+	first, _, isPair := strings.Cut(name, ";")
+	_, typeName, _ := splitTokenedName(first)
+
+	info := RegistrationInfo{
+		Name:         name,
+		TypeName:     typeName,
+		HasConfig:    isPair,
+		Lifetime:     LifetimeSingleton,
+		Conditionals: conditionals,
+	}
+
+	if opts != nil {
+		info.InjectionToken = opts.InjectionToken
+		info.ConfigNodePath = opts.ConfigNodePath
+		info.CallSite = opts.callSite
+		info.TTL = opts.TTL
+		if opts.isPooled() {
+			info.Lifetime, info.PoolSize = LifetimePooled, opts.poolSize
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,139 @@
+package di
+
+import (
+	goctx "context"
+	"strings"
+
+	"github.com/pixie-sh/logger-go/logger"
+)
+
+// silentLevel is below logger.ERROR, dropping every message.
+const silentLevel logger.LogLevelEnum = -1
+
+// WithLogger makes the registry log through log instead of the package Logger.
+func WithLogger(log logger.Interface) RegistryOption {
+	return func(r *diRegistry) {
+		r.log = log
+	}
+}
+
+// WithLogLevel drops the registry messages less severe than level, e.g. logger.WARN
+// keeps the warnings and errors and drops the debug chatter of every creation.
+func WithLogLevel(level logger.LogLevelEnum) RegistryOption {
+	return func(r *diRegistry) {
+		r.logLevel.Store(&level)
+	}
+}
+
+// SetResolutionLogLevel changes the level of the registry messages at runtime, e.g. to enable the
+// debug messages of every creation in production while diagnosing a wiring issue, then turn them
+// off again. DebugHandler sets it on POST requests with a log_level parameter.
+func (dif *diRegistry) SetResolutionLogLevel(level logger.LogLevelEnum) {
+	dif.logLevel.Store(&level)
+}
+
+// parseLogLevel parses the name of a level, as printed by logger.LogLevelEnum, or SILENT.
+func parseLogLevel(name string) (logger.LogLevelEnum, bool) {
+	if strings.EqualFold(name, "SILENT") {
+		return silentLevel, true
+	}
+
+	for _, level := range []logger.LogLevelEnum{logger.ERROR, logger.WARN, logger.LOG, logger.DEBUG} {
+		if strings.EqualFold(name, level.String()) {
+			return level, true
+		}
+	}
+
+	return 0, false
+}
+
+// WithSilent drops every message of the registry.
+func WithSilent() RegistryOption {
+	return WithLogLevel(silentLevel)
+}
+
+// loggerProvider is implemented by registries carrying their own logger, see WithLogger.
+type loggerProvider interface {
+	logger() logger.Interface
+}
+
+// logger returns the logger of the registry, the package Logger when none was given.
+func (dif *diRegistry) logger() logger.Interface {
+	log := dif.log
+	if log == nil {
+		log = Logger
+	}
+
+	if level := dif.logLevel.Load(); level != nil {
+		return leveledLogger{inner: log, level: *level}
+	}
+
+	return log
+}
+
+// loggerFor returns the logger of the registry opts create with, the package Logger
+// when the registry has none.
+func loggerFor(opts *RegistryOpts) logger.Interface {
+	var registry Registry = DefaultRegistry()
+	if opts != nil && opts.Registry != nil {
+		registry = opts.Registry
+	}
+
+	if provider, ok := registry.(loggerProvider); ok {
+		return provider.logger()
+	}
+
+	return Logger
+}
+
+// debugEnabled reports whether log may write debug messages, so callers can skip
+// building the fields of the ones it would drop.
+func debugEnabled(log logger.Interface) bool {
+	if leveled, ok := log.(leveledLogger); ok {
+		return leveled.level >= logger.DEBUG
+	}
+
+	return true
+}
+
+// leveledLogger drops the messages of inner less severe than level.
+type leveledLogger struct {
+	inner logger.Interface
+	level logger.LogLevelEnum
+}
+
+func (l leveledLogger) Clone() logger.Interface {
+	return leveledLogger{inner: l.inner.Clone(), level: l.level}
+}
+
+func (l leveledLogger) WithCtx(ctx goctx.Context) logger.Interface {
+	return leveledLogger{inner: l.inner.WithCtx(ctx), level: l.level}
+}
+
+func (l leveledLogger) With(field string, value any) logger.Interface {
+	return leveledLogger{inner: l.inner.With(field, value), level: l.level}
+}
+
+func (l leveledLogger) Log(format string, args ...any) {
+	if l.level >= logger.LOG {
+		l.inner.Log(format, args...)
+	}
+}
+
+func (l leveledLogger) Error(format string, args ...any) {
+	if l.level >= logger.ERROR {
+		l.inner.Error(format, args...)
+	}
+}
+
+func (l leveledLogger) Warn(format string, args ...any) {
+	if l.level >= logger.WARN {
+		l.inner.Warn(format, args...)
+	}
+}
+
+func (l leveledLogger) Debug(format string, args ...any) {
+	if l.level >= logger.DEBUG {
+		l.inner.Debug(format, args...)
+	}
+}
@@ -21,6 +21,8 @@ func isPointer(i interface{}) bool {
 	return reflect.TypeOf(i).Kind() == reflect.Ptr
 }
 
+// DecodeStruct decodes from, usually a map, into the struct to points to, by the json tags of its fields.
+// It fails listing the required fields left missing, see the `di:"required"` tag.
 func DecodeStruct(from any, to any) error {
 	if !isPointer(to) {
 		return errors.New("destination must be pointer", StructMapTypeMismatchErrorCode)
@@ -44,7 +46,7 @@ func DecodeStruct(from any, to any) error {
 		return errors.Wrap(err, "failed to decode", StructMapTypeMismatchErrorCode)
 	}
 
-	return nil
+	return checkRequired(to)
 }
 
 func Decode[T any](from any) (T, error) {
@@ -0,0 +1,57 @@
+package di
+
+import (
+	"sync"
+)
+
+// WithResolutionMemo returns a function that makes a creation reuse, across its whole resolution
+// tree, the first instance created for each type and token, even the ones created WithFreshInstance,
+// e.g. so the three sub-factories of a handler asking for a fresh local cache share a single one:
+//
+//	handler, err := di.Create[*Handler](ctx, di.WithResolutionMemo())
+//
+// The instances are memoized on the injection context of the creation, so they're dropped with it
+// and never shared with other creations.
+func WithResolutionMemo() func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.memoize = true
+	}
+}
+
+// resolutionMemo holds the instances created in a resolution tree, by hot instance key.
+type resolutionMemo struct {
+	mu        sync.Mutex
+	instances map[string]any
+}
+
+func (m *resolutionMemo) get(key string) (any, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	instance, ok := m.instances[key]
+	return instance, ok
+}
+
+func (m *resolutionMemo) set(key string, instance any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.instances[key]; !ok {
+		m.instances[key] = instance
+	}
+}
+
+// resolutionMemoOf returns the memo of the resolution tree ctx belongs to, starting one when the
+// creation made with opts asks for it, nil when the tree isn't memoized.
+func resolutionMemoOf(ctx Context, opts *RegistryOpts) *resolutionMemo {
+	diCtx, ok := ctx.(*context)
+	if !ok {
+		return nil
+	}
+
+	if diCtx.memo == nil && opts != nil && opts.memoize {
+		diCtx.memo = &resolutionMemo{instances: map[string]any{}}
+	}
+
+	return diCtx.memo
+}
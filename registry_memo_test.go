@@ -0,0 +1,52 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoCacheTest struct{ id int }
+
+type memoHandlerTest struct {
+	caches []*memoCacheTest
+}
+
+func TestWithResolutionMemo(t *testing.T) {
+	registry := NewTestRegistry(t)
+	created := 0
+	require.NoError(t, Register[*memoCacheTest](func(ctx Context, opts *RegistryOpts) (*memoCacheTest, error) {
+		created++
+		return &memoCacheTest{id: created}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*memoHandlerTest](func(ctx Context, opts *RegistryOpts) (*memoHandlerTest, error) {
+		handler := &memoHandlerTest{}
+		for range 3 {
+			cache, err := Create[*memoCacheTest](ctx, WithRegistry(registry), WithFreshInstance())
+			if err != nil {
+				return nil, err
+			}
+
+			handler.caches = append(handler.caches, cache)
+		}
+
+		return handler, nil
+	}, WithRegistry(registry)))
+
+	memoized, err := Create[*memoHandlerTest](NewContext(), WithRegistry(registry), WithResolutionMemo(), WithFreshInstance())
+	require.NoError(t, err)
+	assert.Same(t, memoized.caches[0], memoized.caches[1])
+	assert.Same(t, memoized.caches[0], memoized.caches[2])
+	assert.Equal(t, 1, created)
+
+	other, err := Create[*memoHandlerTest](NewContext(), WithRegistry(registry), WithResolutionMemo(), WithFreshInstance())
+	require.NoError(t, err)
+	assert.NotSame(t, memoized.caches[0], other.caches[0], "memos are not shared across creations")
+	assert.Equal(t, 2, created)
+
+	plain, err := Create[*memoHandlerTest](NewContext(), WithRegistry(registry), WithFreshInstance())
+	require.NoError(t, err)
+	assert.NotSame(t, plain.caches[0], plain.caches[1])
+	assert.Equal(t, 5, created)
+}
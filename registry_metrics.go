@@ -0,0 +1,112 @@
+package di
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsCollector receives the outcome of every creation made by a registry with metrics
+// enabled, see WithMetrics. The diprom package exports them to Prometheus.
+type MetricsCollector interface {
+	ObserveCreate(event CreateEvent)
+}
+
+// TypeMetrics counts the creations of a registered type, see RegistryInspector.Metrics.
+// Creations is the number of Create or CreateConfiguration calls, each either served by a
+// hot instance or running the factory, which FactoryDuration sums up.
+type TypeMetrics struct {
+	TypeName        string        `json:"type_name"`
+	Configuration   bool          `json:"configuration"`
+	Creations       uint64        `json:"creations"`
+	HotHits         uint64        `json:"hot_hits"`
+	HotMisses       uint64        `json:"hot_misses"`
+	Failures        uint64        `json:"failures"`
+	FactoryDuration time.Duration `json:"factory_duration"`
+}
+
+// WithMetrics makes the registry count its creations per type, exposed through RegistryInspector.Metrics,
+// and report each of them to collectors.
+func WithMetrics(collectors ...MetricsCollector) RegistryOption {
+	return func(r *diRegistry) {
+		r.metrics = &registryMetrics{
+			types:      map[metricsKey]*TypeMetrics{},
+			collectors: collectors,
+		}
+	}
+}
+
+type metricsKey struct {
+	typeName      string
+	configuration bool
+}
+
+type registryMetrics struct {
+	mu         sync.Mutex
+	types      map[metricsKey]*TypeMetrics
+	collectors []MetricsCollector
+}
+
+// observe counts a creation. calls for names not registered, like the tokened lookup
+// before the untokened fallback, aren't counted nor reported.
+func (m *registryMetrics) observe(event CreateEvent) {
+	if !event.Registered {
+		return
+	}
+
+	m.mu.Lock()
+	key := metricsKey{typeName: event.TypeName, configuration: event.Configuration}
+	metrics, ok := m.types[key]
+	if !ok {
+		metrics = &TypeMetrics{TypeName: event.TypeName, Configuration: event.Configuration}
+		m.types[key] = metrics
+	}
+
+	metrics.Creations++
+	if event.HotInstance {
+		metrics.HotHits++
+	} else {
+		metrics.HotMisses++
+		metrics.FactoryDuration += event.Duration
+	}
+	if event.Err != nil {
+		metrics.Failures++
+	}
+	m.mu.Unlock()
+
+	for _, collector := range m.collectors {
+		collector.ObserveCreate(event)
+	}
+}
+
+// Metrics returns the creation counters of every type created so far, sorted by name.
+// It's empty unless the registry was created WithMetrics.
+func (dif *diRegistry) Metrics() []TypeMetrics {
+	if dif.metrics == nil {
+		return nil
+	}
+
+	dif.metrics.mu.Lock()
+	defer dif.metrics.mu.Unlock()
+
+	metrics := make([]TypeMetrics, 0, len(dif.metrics.types))
+	for _, typeMetrics := range dif.metrics.types {
+		metrics = append(metrics, *typeMetrics)
+	}
+
+	slices.SortFunc(metrics, func(a, b TypeMetrics) int {
+		if c := strings.Compare(a.TypeName, b.TypeName); c != 0 {
+			return c
+		}
+		if a.Configuration == b.Configuration {
+			return 0
+		}
+		if a.Configuration {
+			return -1
+		}
+		return 1
+	})
+
+	return metrics
+}
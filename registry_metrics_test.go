@@ -0,0 +1,56 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type metricsCollectorFunc func(event CreateEvent)
+
+func (f metricsCollectorFunc) ObserveCreate(event CreateEvent) {
+	f(event)
+}
+
+func TestMetrics(t *testing.T) {
+	var observed []CreateEvent
+	registry := NewRegistry(WithMetrics(metricsCollectorFunc(func(event CreateEvent) {
+		observed = append(observed, event)
+	})))
+
+	require.NoError(t, Register[*databaseTest](func(ctx Context, opts *RegistryOpts) (*databaseTest, error) {
+		return &databaseTest{}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+		return nil, errors.New("service unavailable")
+	}, WithRegistry(registry)))
+
+	for range 3 {
+		_, err := Create[*databaseTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+	}
+	_, err := Create[*serviceTest](NewContext(), WithRegistry(registry))
+	require.Error(t, err)
+	_, err = Create[*WorkerStatus](NewContext(), WithRegistry(registry))
+	require.Error(t, err)
+
+	metrics := registry.Metrics()
+	require.Len(t, metrics, 2)
+
+	database := metrics[0]
+	assert.Equal(t, TypeName[*databaseTest](), database.TypeName)
+	assert.Equal(t, uint64(3), database.Creations)
+	assert.Equal(t, uint64(2), database.HotHits)
+	assert.Equal(t, uint64(1), database.HotMisses)
+	assert.Zero(t, database.Failures)
+
+	service := metrics[1]
+	assert.Equal(t, TypeName[*serviceTest](), service.TypeName)
+	assert.Equal(t, uint64(1), service.HotMisses)
+	assert.Equal(t, uint64(1), service.Failures)
+
+	assert.Len(t, observed, 4)
+	assert.Empty(t, NewRegistry().Metrics())
+}
@@ -0,0 +1,61 @@
+package di
+
+// CreateCall describes a creation going through the middlewares of a registry.
+// Config is the configuration of the instance created, nil for configuration creations.
+type CreateCall struct {
+	TypeName      string
+	Configuration bool
+	Config        any
+	Opts          *RegistryOpts
+}
+
+// CreateFunc creates the instance or configuration described by call, see Middleware.
+type CreateFunc func(ctx Context, call CreateCall) (any, error)
+
+// Middleware intercepts every Create and CreateConfiguration call of a registry, see WithMiddleware.
+// It may alter the call or the context before handing them to next, serve the call itself, e.g. with
+// a test double, or alter what next returns:
+//
+//	func logCreations(next di.CreateFunc) di.CreateFunc {
+//		return func(ctx di.Context, call di.CreateCall) (any, error) {
+//			instance, err := next(ctx, call)
+//			log.Printf("created %s: %v", call.TypeName, err)
+//			return instance, err
+//		}
+//	}
+type Middleware func(next CreateFunc) CreateFunc
+
+// WithMiddleware installs middlewares around every creation of the registry, the first one being the
+// outermost. They run before the hooks, the tracer and the registration lookup, so they see the calls
+// of unregistered types too, and once per Create call, hot instances included.
+func WithMiddleware(middlewares ...Middleware) RegistryOption {
+	return func(r *diRegistry) {
+		r.middlewares = append(r.middlewares, middlewares...)
+	}
+}
+
+func (dif *diRegistry) Create(ctx Context, typeNameOf string, config any, opts *RegistryOpts) (any, error) {
+	return dif.createChain(ctx, CreateCall{TypeName: dif.registryName(typeNameOf), Config: config, Opts: opts})
+}
+
+func (dif *diRegistry) CreateConfiguration(ctx Context, typeNameOf string, opts *RegistryOpts) (any, error) {
+	return dif.createChain(ctx, CreateCall{TypeName: dif.registryName(typeNameOf), Configuration: true, Opts: opts})
+}
+
+// dispatchCreate is the innermost CreateFunc, creating the call with the registrations.
+func (dif *diRegistry) dispatchCreate(ctx Context, call CreateCall) (any, error) {
+	if call.Configuration {
+		return dif.createConfiguration(ctx, call.TypeName, call.Opts)
+	}
+
+	return dif.createInstance(ctx, call.TypeName, call.Config, call.Opts)
+}
+
+// chainMiddlewares wraps create with middlewares, the first one being the outermost.
+func chainMiddlewares(middlewares []Middleware, create CreateFunc) CreateFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		create = middlewares[i](create)
+	}
+
+	return create
+}
@@ -0,0 +1,49 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type middlewareClockTest struct {
+	Now string
+}
+
+type middlewareServiceTest struct{}
+
+func TestWithMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next CreateFunc) CreateFunc {
+			return func(ctx Context, call CreateCall) (any, error) {
+				calls = append(calls, name+" "+call.TypeName)
+				return next(ctx, call)
+			}
+		}
+	}
+
+	fake := func(next CreateFunc) CreateFunc {
+		return func(ctx Context, call CreateCall) (any, error) {
+			if call.TypeName == TypeName[middlewareClockTest]() {
+				return middlewareClockTest{Now: "frozen"}, nil
+			}
+
+			return next(ctx, call)
+		}
+	}
+
+	registry := NewRegistry(WithMiddleware(record("outer"), record("inner")), WithMiddleware(fake))
+	require.NoError(t, Register[*middlewareServiceTest](func(ctx Context, opts *RegistryOpts) (*middlewareServiceTest, error) {
+		return &middlewareServiceTest{}, nil
+	}, WithRegistry(registry)))
+
+	_, err := Create[*middlewareServiceTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer di.middlewareServiceTest", "inner di.middlewareServiceTest"}, calls)
+
+	clock, err := Create[middlewareClockTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err, "middlewares serve unregistered types")
+	assert.Equal(t, "frozen", clock.Now)
+}
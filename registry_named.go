@@ -0,0 +1,73 @@
+package di
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+var (
+	namedRegistriesMu sync.RWMutex
+	namedRegistries   = map[string]Registry{}
+)
+
+// RegisterRegistry makes registry available under name, so applications maintaining several
+// containers, e.g. "control-plane" and "data-plane", select them by name in wiring code and tooling:
+//
+//	_ = di.RegisterRegistry("data-plane", di.NewRegistry())
+//	registry, err := di.RegistryNamed("data-plane")
+//
+// It fails when name is empty or already taken.
+func RegisterRegistry(name string, registry Registry) error {
+	if len(name) == 0 || registry == nil {
+		return errors.New("cannot register registry '%s', name and registry are required", name, ErrorCreatingDependencyErrorCode)
+	}
+
+	namedRegistriesMu.Lock()
+	defer namedRegistriesMu.Unlock()
+
+	if _, exists := namedRegistries[name]; exists {
+		return errors.New("registry '%s' already registered", name, RegistrationConflictErrorCode)
+	}
+
+	namedRegistries[name] = registry
+	return nil
+}
+
+// RegistryNamed returns the registry registered under name with RegisterRegistry.
+func RegistryNamed(name string) (Registry, error) {
+	namedRegistriesMu.RLock()
+	defer namedRegistriesMu.RUnlock()
+
+	registry, ok := namedRegistries[name]
+	if !ok {
+		return nil, errors.New("registry '%s' not registered", name, DependencyMissingErrorCode)
+	}
+
+	return registry, nil
+}
+
+// RegistryNames returns the names of the registries registered with RegisterRegistry, sorted.
+func RegistryNames() []string {
+	namedRegistriesMu.RLock()
+	defer namedRegistriesMu.RUnlock()
+
+	names := make([]string, 0, len(namedRegistries))
+	for name := range namedRegistries {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// UnregisterRegistry removes the registry registered under name, reporting whether there was one.
+func UnregisterRegistry(name string) bool {
+	namedRegistriesMu.Lock()
+	defer namedRegistriesMu.Unlock()
+
+	_, ok := namedRegistries[name]
+	delete(namedRegistries, name)
+	return ok
+}
@@ -0,0 +1,42 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterRegistry(t *testing.T) {
+	controlPlane, dataPlane := NewTestRegistry(t), NewTestRegistry(t)
+	t.Cleanup(func() {
+		UnregisterRegistry("control-plane")
+		UnregisterRegistry("data-plane")
+	})
+
+	require.NoError(t, RegisterRegistry("data-plane", dataPlane))
+	require.NoError(t, RegisterRegistry("control-plane", controlPlane))
+	assert.Equal(t, []string{"control-plane", "data-plane"}, RegistryNames())
+
+	err := RegisterRegistry("data-plane", NewRegistry())
+	_, isConflict := errors.Has(err, RegistrationConflictErrorCode)
+	assert.True(t, isConflict)
+	assert.Error(t, RegisterRegistry("", NewRegistry()))
+
+	registry, err := RegistryNamed("data-plane")
+	require.NoError(t, err)
+	assert.Same(t, dataPlane, registry)
+
+	require.NoError(t, Register[*tenantPoolTest](func(ctx Context, opts *RegistryOpts) (*tenantPoolTest, error) {
+		return &tenantPoolTest{DSN: "data-plane"}, nil
+	}, WithRegistry(registry)))
+	assert.True(t, dataPlane.IsRegistered(TypeName[*tenantPoolTest]()))
+	assert.False(t, controlPlane.IsRegistered(TypeName[*tenantPoolTest]()))
+
+	assert.True(t, UnregisterRegistry("data-plane"))
+	assert.False(t, UnregisterRegistry("data-plane"))
+	_, err = RegistryNamed("data-plane")
+	_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+	assert.True(t, isMissing)
+}
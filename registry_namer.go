@@ -0,0 +1,181 @@
+package di
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// TypeNamer derives the registration names of the types of a registry, see WithTypeNamer, e.g. to hash
+// them, version them or use the message names of protobuf types, so names stay stable across processes
+// persisting registry metadata. It's given types dereferenced once, like TypeName does, and the registry
+// prefixes the names of tokened registrations with their token.
+//
+// Names must not be empty, contain the ";" separating pair names or read as tokened, with a colon
+// after a token like text. Implementations must be safe for concurrent use and return the same name
+// for a type every time, the registry caching it.
+type TypeNamer interface {
+	TypeName(t reflect.Type) string
+}
+
+// TypeNamerFunc adapts a function to the TypeNamer interface.
+type TypeNamerFunc func(t reflect.Type) string
+
+func (fn TypeNamerFunc) TypeName(t reflect.Type) string {
+	return fn(t)
+}
+
+var (
+	// ShortTypeNamer names types like TypeName, the registries default.
+	ShortTypeNamer TypeNamer = TypeNamerFunc(typeNameOf)
+	// QualifiedTypeNamer names types like QualifiedTypeName.
+	QualifiedTypeNamer TypeNamer = TypeNamerFunc(qualifiedTypeName)
+)
+
+// WithTypeNamer makes the registry name its types with namer. It's the only naming option,
+// WithQualifiedTypeNames sets QualifiedTypeNamer, so when several are given the last one wins.
+// Use TypeNameIn to build the names given to the Registry methods:
+//
+//	registry := di.NewRegistry(di.WithTypeNamer(di.TypeNamerFunc(func(t reflect.Type) string {
+//		return di.QualifiedTypeNamer.TypeName(t) + "@v2"
+//	})))
+//
+// To ease the migration of registries named with TypeName, the names given to its Create,
+// CreateConfiguration, IsRegistered, IsConfigurationRegistered, Unregister and hot instance
+// methods resolve to the name namer gives the type sharing their TypeName, when it's the only one:
+//
+//	registry.IsRegistered(di.TypeName[auth.Config]()) // "auth.Config", resolved to "github.com/acme/auth.Config"
+func WithTypeNamer(namer TypeNamer) RegistryOption {
+	return func(r *diRegistry) {
+		r.namer = namer
+	}
+}
+
+// TypeNameIn returns the registration name of T in registry r, its TypeName unless r names types its
+// own way, see WithTypeNamer, prefixed by the injection token when provided. A nil r is the DefaultRegistry.
+func TypeNameIn[T any](r Registry, tokens ...InjectionToken) string {
+	names := namesIn(r, reflect.TypeOf((*T)(nil)).Elem())
+	if len(tokens) > 0 && len(tokens[0]) > 0 {
+		return names.tokened(tokens[0])
+	}
+
+	return names.name
+}
+
+// registryNamer is implemented by registries naming their types their own way, see WithTypeNamer.
+type registryNamer interface {
+	namesOf(t reflect.Type) *typeNames
+}
+
+// namesOf returns the cached names of t in the registry.
+func (dif *diRegistry) namesOf(t reflect.Type) *typeNames {
+	if dif.namer == nil {
+		return typeNamesOf(t)
+	}
+
+	return dif.namedTypesOf(t)
+}
+
+// namedTypesOf returns the names the TypeNamer of the registry gives t, cached per registry,
+// indexing them under the TypeName of t. It panics when the name is invalid, see TypeNamer.
+func (dif *diRegistry) namedTypesOf(t reflect.Type) *typeNames {
+	if cached, ok := dif.namedTypes.Load(t); ok {
+		return cached.(*typeNames)
+	}
+
+	named := t
+	if t.Kind() == reflect.Ptr {
+		named = t.Elem()
+	}
+
+	name := dif.namer.TypeName(named)
+	errors.Must(validateTypeName(name, named))
+
+	cached, loaded := dif.namedTypes.LoadOrStore(t, &typeNames{name: name})
+	if !loaded {
+		dif.recordShortName(t, name)
+	}
+
+	return cached.(*typeNames)
+}
+
+// recordShortName indexes the name the registry gives t under its TypeName, marking the TypeName
+// shared by distinct names as ambiguous with an empty name.
+func (dif *diRegistry) recordShortName(t reflect.Type, named string) {
+	short := typeNamesOf(t).name
+	if short == named {
+		return
+	}
+
+	if current, ok := dif.shortNames.Load(short); ok && (current == named || current == "") {
+		return
+	}
+
+	if current, loaded := dif.shortNames.LoadOrStore(short, named); loaded && current != named {
+		dif.shortNames.Store(short, "")
+	}
+}
+
+// registryName returns the name the registry gives a name built with TypeName, tokened and pair
+// names included, see WithTypeNamer. Other names are returned as is.
+func (dif *diRegistry) registryName(name string) string {
+	if dif.namer == nil {
+		return name
+	}
+
+	if first, second, isPair := strings.Cut(name, ";"); isPair {
+		namedFirst, namedSecond := dif.registryName(first), dif.registryName(second)
+		if namedFirst == first && namedSecond == second {
+			return name
+		}
+
+		return namedFirst + ";" + namedSecond
+	}
+
+	token, short, _ := splitTokenedName(name)
+	named, ok := dif.shortNames.Load(short)
+	if !ok || named == "" {
+		return name
+	}
+
+	if log := dif.logger(); debugEnabled(log) {
+		log.With("type", short).Debug("di resolved the TypeName '%s' to the name '%s'", short, named)
+	}
+
+	if len(token) > 0 {
+		return token.String() + ":" + named.(string)
+	}
+
+	return named.(string)
+}
+
+// validateTypeName checks the name given to t by a TypeNamer against the rules documented in TypeNamer.
+func validateTypeName(name string, t reflect.Type) error {
+	if len(name) == 0 {
+		return errors.New("type namer gave %s an empty name", t, ErrorCreatingDependencyErrorCode)
+	}
+
+	if strings.Contains(name, ";") {
+		return errors.New("type namer gave %s the name '%s', containing the pair separator ';'", t, name, ErrorCreatingDependencyErrorCode)
+	}
+
+	if _, _, tokened := splitTokenedName(name); tokened {
+		return errors.New("type namer gave %s the name '%s', which reads as tokened", t, name, ErrorCreatingDependencyErrorCode)
+	}
+
+	return nil
+}
+
+// namesIn returns the cached names of t in registry f, its TypeName ones unless f names types its own way.
+func namesIn(f Registry, t reflect.Type) *typeNames {
+	if f == nil {
+		f = DefaultRegistry()
+	}
+
+	if namer, ok := f.(registryNamer); ok {
+		return namer.namesOf(t)
+	}
+
+	return typeNamesOf(t)
+}
@@ -0,0 +1,70 @@
+package di
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type namerServiceTest struct {
+	Config namerConfigTest
+}
+
+type namerConfigTest struct {
+	Addr string `json:"addr"`
+}
+
+func (c namerConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+func TestWithTypeNamer(t *testing.T) {
+	versioned := TypeNamerFunc(func(t reflect.Type) string {
+		return QualifiedTypeNamer.TypeName(t) + "@v2"
+	})
+	registry := NewRegistry(WithTypeNamer(versioned))
+
+	require.NoError(t, RegisterPair[*namerServiceTest, namerConfigTest](
+		func(ctx Context, opts *RegistryOpts, config namerConfigTest) (*namerServiceTest, error) {
+			return &namerServiceTest{Config: config}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (namerConfigTest, error) {
+			return namerConfigTest{Addr: "localhost"}, nil
+		},
+		WithRegistry(registry),
+		WithToken("primary"),
+	))
+
+	name := TypeNameIn[*namerServiceTest](registry, "primary")
+	assert.Equal(t, "primary:github.com/pixie-sh/di-go.namerServiceTest@v2", name)
+	assert.Equal(t, TypeName[namerServiceTest](), TypeNameIn[namerServiceTest](NewRegistry()))
+	assert.True(t, registry.IsRegistered(PairTypeName(name, TypeNameIn[namerConfigTest](registry, "primary"))))
+
+	service, err := CreatePair[*namerServiceTest, namerConfigTest](NewContext(), WithRegistry(registry), WithToken("primary"))
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", service.Config.Addr)
+
+	t.Run("TypeName migration", func(t *testing.T) {
+		assert.True(t, registry.IsRegistered(PairTypeName(TypeName[*namerServiceTest]("primary"), TypeName[namerConfigTest]("primary"))))
+	})
+
+	t.Run("last naming option wins", func(t *testing.T) {
+		assert.Equal(t, QualifiedTypeName[namerServiceTest](), TypeNameIn[namerServiceTest](NewRegistry(WithTypeNamer(versioned), WithQualifiedTypeNames())))
+		assert.Equal(t, versioned.TypeName(reflect.TypeOf(namerServiceTest{})), TypeNameIn[namerServiceTest](NewRegistry(WithTypeKeys(), WithTypeNamer(versioned))))
+	})
+
+	t.Run("invalid names", func(t *testing.T) {
+		for _, namer := range []TypeNamer{
+			TypeNamerFunc(func(reflect.Type) string { return "" }),
+			TypeNamerFunc(func(t reflect.Type) string { return t.Name() + ";v2" }),
+			TypeNamerFunc(func(t reflect.Type) string { return "v2:" + t.Name() }),
+		} {
+			registry := NewRegistry(WithTypeNamer(namer))
+			assert.Panics(t, func() {
+				_ = TypeNameIn[namerServiceTest](registry)
+			})
+		}
+	})
+}
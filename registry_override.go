@@ -0,0 +1,66 @@
+package di
+
+import (
+	"maps"
+	"reflect"
+)
+
+// overrideKey identifies the type and injection token an instance is substituted for, see WithOverride.
+type overrideKey struct {
+	t     reflect.Type
+	token InjectionToken
+}
+
+// WithOverride returns a copy of ctx in which Create and CreatePair return instance for T, with the
+// first of tokens if any, instead of resolving the registrations, e.g. so a single request or test case
+// sees a substitute while the registry stays untouched:
+//
+//	ctx = di.WithOverride[Clock](ctx, di.NewFrozenClock(now))
+//
+// The override applies to the whole resolution tree of the creations made with the returned context,
+// and to the contexts created from it with NewContext. Tokened creations fall back to the untokened
+// override, unless the untokened fallback is disabled. Contexts other than the ones of NewContext
+// cannot carry overrides and are returned as is.
+func WithOverride[T any](ctx Context, instance T, tokens ...InjectionToken) Context {
+	diCtx, ok := ctx.(*context)
+	if !ok {
+		return ctx
+	}
+
+	var token InjectionToken
+	if len(tokens) > 0 {
+		token = tokens[0]
+	}
+
+	clone := diCtx.Clone().(*context)
+	clone.isScoped = diCtx.isScoped
+	clone.overrides = maps.Clone(diCtx.overrides)
+	if clone.overrides == nil {
+		clone.overrides = map[overrideKey]any{}
+	}
+
+	clone.overrides[overrideKey{reflect.TypeFor[T](), token}] = instance
+	return clone
+}
+
+// overrideOf returns the instance substituted for T in ctx for a creation made with opts, see WithOverride.
+func overrideOf[T any](ctx Context, opts *RegistryOpts) (T, bool) {
+	var result T
+
+	diCtx, ok := ctx.(*context)
+	if !ok || len(diCtx.overrides) == 0 {
+		return result, false
+	}
+
+	t := reflect.TypeFor[T]()
+	instance, ok := diCtx.overrides[overrideKey{t, opts.InjectionToken}]
+	if !ok && len(opts.InjectionToken) > 0 && !tokenFallbackDisabled(opts.Registry, opts) {
+		instance, ok = diCtx.overrides[overrideKey{t, ""}]
+	}
+
+	if !ok {
+		return result, false
+	}
+
+	return instance.(T), true
+}
@@ -0,0 +1,52 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOverride(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{Level: "INFO"}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+		logger, err := Create[*loggerTest](ctx, WithRegistry(registry), WithToken("audit"))
+		if err != nil {
+			return nil, err
+		}
+
+		return &serviceTest{Logger: logger}, nil
+	}, WithRegistry(registry), WithFreshInstance()))
+
+	base := NewContext()
+	substitute := &loggerTest{Level: "DEBUG"}
+	ctx := WithOverride(base, substitute)
+
+	logger, err := Create[*loggerTest](ctx, WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, substitute, logger)
+
+	service, err := Create[*serviceTest](ctx, WithRegistry(registry), WithFreshInstance())
+	require.NoError(t, err)
+	assert.Equal(t, "DEBUG", service.Logger.Level, "the whole resolution tree sees the override, tokened creations falling back to it")
+
+	logger, err = Create[*loggerTest](base, WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "INFO", logger.Level, "the registry and the parent context are untouched")
+
+	_, err = Create[*loggerTest](ctx, WithRegistry(registry), WithToken("audit"), WithStrictToken())
+	assert.Error(t, err, "strict tokens don't fall back to the untokened override")
+
+	audit := WithOverride(ctx, &loggerTest{Level: "AUDIT"}, "audit")
+	logger, err = Create[*loggerTest](NewContext(audit), WithRegistry(registry), WithToken("audit"))
+	require.NoError(t, err)
+	assert.Equal(t, "AUDIT", logger.Level, "contexts created from it inherit the overrides")
+
+	client := &overrideClientTest{Addr: "stub"}
+	paired, err := CreatePair[*overrideClientTest, overrideClientConfigTest](WithOverride(base, client), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, client, paired)
+}
@@ -0,0 +1,178 @@
+package di
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// PlanNode is a type of the resolution tree replayed by Plan, with the dependencies its factory was observed resolving.
+type PlanNode struct {
+	Name           string         `json:"name"` // registration name requested, with its injection token
+	Kind           GraphNodeKind  `json:"kind"` // instance or configuration
+	InjectionToken InjectionToken `json:"injection_token,omitempty"`
+	Selected       string         `json:"selected,omitempty"` // registration name used, empty when none is registered
+	TokenFallback  bool           `json:"token_fallback"`     // Selected is the untokened name of Name
+	ConfigPath     string         `json:"config_path"`        // config path the lookups would use
+	Cycle          bool           `json:"cycle,omitempty"`    // Name already resolves higher in the tree, its dependencies are not repeated
+	Dependencies   []PlanNode     `json:"dependencies,omitempty"`
+}
+
+// String renders the tree as an indented text, one type per line, stable across runs so plans can be diffed.
+func (n PlanNode) String() string {
+	b := strings.Builder{}
+	n.write(&b, 0)
+	return b.String()
+}
+
+func (n PlanNode) write(b *strings.Builder, depth int) {
+	selected := n.Selected
+	switch {
+	case len(selected) == 0:
+		selected = "not registered"
+	case n.TokenFallback:
+		selected += " (token fallback)"
+	}
+
+	fmt.Fprintf(b, "%s%s %s -> %s, config path '%s'", strings.Repeat("  ", depth), n.Kind, n.Name, selected, n.ConfigPath)
+	if n.Cycle {
+		b.WriteString(", cycle")
+	}
+	b.WriteString("\n")
+
+	for _, dependency := range n.Dependencies {
+		dependency.write(b, depth+1)
+	}
+}
+
+// Plan replays the wiring the registry observed for Create[T] with the same options: the types, tokens
+// and config paths of every dependency its factories resolved when they ran, without executing any factory:
+//
+//	plan, err := di.Plan[*Server](ctx)
+//	fmt.Print(plan) // diffed between releases to catch tokens pointing elsewhere
+//
+// It's not derived from the registrations alone, factories being opaque until run: the dependencies
+// of a type are the ones recorded in RegistryInspector.Graph, none for the types never created in the
+// registry. Plan a registry whose registrations were all created, e.g. after WarmUp of a staging
+// instance, for a complete tree. The token selection of every listed type follows the registrations
+// and conditions as they stand, and the config paths follow the breadcrumbs, ignoring the
+// WithConfigNodePath their factories may pass. It fails with DependencyMissingErrorCode when T itself
+// is not registered.
+func Plan[T any](ctx Context, options ...func(opts *RegistryOpts)) (PlanNode, error) {
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	f := registryOpts.Registry
+	injectionCtx := newInjectionContext(ctx, &registryOpts)
+	configPath, _ := assembleConfigurationLookupPath(injectionCtx, &registryOpts)
+
+	graph := graphOf(f)
+	dependsOn := map[string][]GraphEdge{}
+	for _, edge := range graph.Edges {
+		dependsOn[edge.From] = append(dependsOn[edge.From], edge)
+	}
+
+	kinds := map[string]GraphNodeKind{}
+	for _, node := range graph.Nodes {
+		kinds[node.Name] = node.Kind
+	}
+
+	planner := planner{ctx: ctx, f: f, dependsOn: dependsOn, kinds: kinds}
+	root := planner.plan(TypeNameIn[T](f, registryOpts.InjectionToken), GraphInstance, configPath, ctx.Breadcrumbs(), nil)
+	if len(root.Selected) == 0 {
+		return root, errors.New("dependency not registered: %s", root.Name, DependencyMissingErrorCode)
+	}
+
+	return root, nil
+}
+
+// planner walks the dependency graph of a registry for Plan.
+type planner struct {
+	ctx       Context
+	f         Registry
+	dependsOn map[string][]GraphEdge
+	kinds     map[string]GraphNodeKind
+}
+
+// plan returns the node name resolved with the breadcrumbs of its parent, path holding the names
+// resolving above it.
+func (p planner) plan(name string, kind GraphNodeKind, configPath string, breadcrumbs []string, path []string) PlanNode {
+	token, untokened, _ := splitTokenedName(name)
+	node := PlanNode{Name: name, Kind: kind, InjectionToken: token, ConfigPath: configPath}
+
+	switch {
+	case p.registered(name, kind):
+		node.Selected = name
+	case len(token) > 0 && p.registered(untokened, kind):
+		node.Selected, node.TokenFallback = untokened, true
+	}
+
+	if slices.Contains(path, name) {
+		node.Cycle = true
+		return node
+	}
+
+	if len(token) > 0 {
+		breadcrumbs = append(breadcrumbs[:len(breadcrumbs):len(breadcrumbs)], token.String())
+	}
+
+	path = append(path[:len(path):len(path)], name)
+	for _, edge := range p.dependsOn[name] {
+		childKind := p.kinds[edge.To]
+		childPath := strings.Join(breadcrumbs, ".")
+		if childToken, _, tokened := splitTokenedName(edge.To); tokened {
+			childPath = strings.Join(append(breadcrumbs[:len(breadcrumbs):len(breadcrumbs)], childToken.String()), ".")
+		}
+
+		if registered(p.f, PairTypeName(name, edge.To)) {
+			childKind, childPath = GraphConfiguration, configPath
+		}
+
+		node.Dependencies = append(node.Dependencies, p.plan(edge.To, childKind, childPath, breadcrumbs, path))
+	}
+
+	return node
+}
+
+// registered reports whether a creation with the context of the plan would select a registration
+// of name as kind, pairs included.
+func (p planner) registered(name string, kind GraphNodeKind) bool {
+	if kind == GraphConfiguration {
+		return configurationRegisteredFor(p.ctx, p.f, name) || p.pairConfiguration(name)
+	}
+
+	if registeredFor(p.ctx, p.f, name) {
+		return true
+	}
+
+	for _, edge := range p.dependsOn[name] {
+		if registeredFor(p.ctx, p.f, PairTypeName(name, edge.To)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pairConfiguration reports whether name is the configuration of a pair registration.
+func (p planner) pairConfiguration(name string) bool {
+	for from, edges := range p.dependsOn {
+		for _, edge := range edges {
+			if edge.To == name && configurationRegisteredFor(p.ctx, p.f, PairTypeName(name, from)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
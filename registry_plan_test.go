@@ -0,0 +1,85 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type planServerTest struct {
+	Client *overrideClientTest
+	Logger *loggerTest
+}
+
+func TestPlan(t *testing.T) {
+	registry := NewTestRegistry(t)
+	calls := 0
+
+	require.NoError(t, RegisterPair[*overrideClientTest, overrideClientConfigTest](
+		func(ctx Context, opts *RegistryOpts, config overrideClientConfigTest) (*overrideClientTest, error) {
+			return &overrideClientTest{Addr: config.Addr}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (overrideClientConfigTest, error) {
+			return overrideClientConfigTest{Addr: "billing:8080"}, nil
+		},
+		WithRegistry(registry),
+		WithToken("billing"),
+	))
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{Level: "INFO"}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*planServerTest](func(ctx Context, opts *RegistryOpts) (*planServerTest, error) {
+		calls++
+		client, err := CreatePair[*overrideClientTest, overrideClientConfigTest](ctx, WithRegistry(registry), WithToken("billing"))
+		if err != nil {
+			return nil, err
+		}
+
+		logger, err := Create[*loggerTest](ctx, WithRegistry(registry), WithToken("audit"))
+		if err != nil {
+			return nil, err
+		}
+
+		return &planServerTest{Client: client, Logger: logger}, nil
+	}, WithRegistry(registry), WithToken("api")))
+
+	unobserved, err := Plan[*planServerTest](NewContext(), WithRegistry(registry), WithToken("api"))
+	require.NoError(t, err)
+	assert.Empty(t, unobserved.Dependencies, "dependencies are only known once the factory ran")
+
+	_, err = Create[*planServerTest](NewContext(), WithRegistry(registry), WithToken("api"))
+	require.NoError(t, err)
+
+	plan, err := Plan[*planServerTest](NewContext(), WithRegistry(registry), WithToken("api"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "no factory is executed")
+	assert.Equal(t, "api:di.planServerTest", plan.Selected)
+	assert.Equal(t, "api", plan.ConfigPath)
+	require.Len(t, plan.Dependencies, 2)
+
+	logger := plan.Dependencies[0]
+	assert.Equal(t, "audit:di.loggerTest", logger.Name)
+	assert.Equal(t, "di.loggerTest", logger.Selected)
+	assert.True(t, logger.TokenFallback)
+	assert.Equal(t, "api.audit", logger.ConfigPath)
+
+	client := plan.Dependencies[1]
+	assert.Equal(t, "billing:di.overrideClientTest", client.Selected)
+	assert.Equal(t, "api.billing", client.ConfigPath)
+	require.Len(t, client.Dependencies, 1)
+	assert.Equal(t, GraphConfiguration, client.Dependencies[0].Kind)
+	assert.Equal(t, "billing:di.overrideClientConfigTest", client.Dependencies[0].Selected)
+	assert.Equal(t, "api.billing", client.Dependencies[0].ConfigPath)
+
+	assert.Equal(t, `instance api:di.planServerTest -> api:di.planServerTest, config path 'api'
+  instance audit:di.loggerTest -> di.loggerTest (token fallback), config path 'api.audit'
+  instance billing:di.overrideClientTest -> billing:di.overrideClientTest, config path 'api.billing'
+    configuration billing:di.overrideClientConfigTest -> billing:di.overrideClientConfigTest, config path 'api.billing'
+`, plan.String())
+
+	_, err = Plan[*planServerTest](NewContext(), WithRegistry(registry), WithToken("admin"))
+	_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+	assert.True(t, isMissing)
+}
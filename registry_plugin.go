@@ -0,0 +1,72 @@
+package di
+
+import (
+	"path/filepath"
+	"plugin"
+	"slices"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// PluginSymbol is the symbol LoadPlugins looks up in every plugin, a function registering the providers
+// of the plugin into the registry it's given:
+//
+//	// built with go build -buildmode=plugin
+//	package main
+//
+//	func Register(r di.Registry) error {
+//		return di.Register[payments.Gateway](newGateway, di.WithRegistry(r))
+//	}
+const PluginSymbol = "Register"
+
+// LoadPlugins opens the Go plugins (.so files) at paths and registers their providers into r through
+// their PluginSymbol, in order. As with UseIn, a failing plugin doesn't stop the others, the failures are
+// joined into the returned error. Plugins are only supported where the plugin package is, see its documentation,
+// and must be built with the same versions of the packages they share with the application, di included.
+func LoadPlugins(r Registry, paths ...string) error {
+	var errs []error
+	for _, path := range paths {
+		p, err := plugin.Open(path)
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, "failed to open plugin %s", path, ErrorCreatingDependencyErrorCode))
+			continue
+		}
+
+		symbol, err := p.Lookup(PluginSymbol)
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, "plugin %s does not export %s", path, PluginSymbol, DependencyMissingErrorCode))
+			continue
+		}
+
+		if err = registerPlugin(r, path, symbol); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// LoadPluginDir loads, with LoadPlugins, every .so file of dir in lexical order.
+func LoadPluginDir(r Registry, dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return errors.Wrap(err, "failed to list plugins of %s", dir, ErrorCreatingDependencyErrorCode)
+	}
+
+	slices.Sort(paths)
+	return LoadPlugins(r, paths...)
+}
+
+// registerPlugin calls the PluginSymbol looked up in the plugin at path with r.
+func registerPlugin(r Registry, path string, symbol plugin.Symbol) error {
+	register, ok := symbol.(func(Registry) error)
+	if !ok {
+		return errors.New("plugin %s exports %s as %T, expected func(di.Registry) error", path, PluginSymbol, symbol, DependencyTypeMismatchErrorCode)
+	}
+
+	if err := register(r); err != nil {
+		return errors.Wrap(err, "failed to register plugin %s", path, ErrorCreatingDependencyErrorCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package di
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPlugins(t *testing.T) {
+	registry := NewTestRegistry(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "payments.so"), []byte("not a plugin"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o600))
+
+	err := LoadPluginDir(registry, dir)
+	assert.ErrorContains(t, err, "failed to open plugin "+filepath.Join(dir, "payments.so"))
+
+	err = LoadPlugins(registry, filepath.Join(dir, "missing.so"), filepath.Join(dir, "payments.so"))
+	assert.ErrorContains(t, err, "missing.so")
+	assert.ErrorContains(t, err, "payments.so", "a failing plugin doesn't stop the others")
+
+	require.NoError(t, LoadPluginDir(registry, t.TempDir()))
+
+	t.Run("symbol", func(t *testing.T) {
+		require.NoError(t, registerPlugin(registry, "payments.so", func(r Registry) error {
+			return Register[*tenantPoolTest](func(ctx Context, opts *RegistryOpts) (*tenantPoolTest, error) {
+				return &tenantPoolTest{}, nil
+			}, WithRegistry(r))
+		}))
+		assert.True(t, registry.IsRegistered(TypeName[*tenantPoolTest]()))
+
+		err := registerPlugin(registry, "payments.so", func() {})
+		_, isMismatch := errors.Has(err, DependencyTypeMismatchErrorCode)
+		assert.True(t, isMismatch)
+
+		err = registerPlugin(registry, "payments.so", func(r Registry) error { return errors.New("gateway unreachable") })
+		assert.ErrorContains(t, err, "failed to register plugin payments.so")
+	})
+}
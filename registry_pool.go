@@ -0,0 +1,194 @@
+package di
+
+import (
+	"github.com/pixie-sh/errors-go"
+)
+
+// WithPool returns a function that gives a registration the pooled lifetime: up to size instances
+// are created, each one leased by a single caller at a time through Acquire and handed back with
+// Release, e.g. for expensive but not shareable resources like dedicated worker connections.
+// Pooled registrations are not created by Create, only Register supports them.
+func WithPool(size int) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.poolSize = size
+	}
+}
+
+// WithPoolSizeAt returns a function that reads the size of the pool of a registration from the
+// configuration node at path, looked up in the context of the first Acquire, falling back to the
+// size given by WithPool when the node is missing:
+//
+//	di.Register[*WorkerConn](newWorkerConn, di.WithPool(4), di.WithPoolSizeAt("workers.connections"))
+func WithPoolSizeAt(path string) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.poolSizePath = path
+	}
+}
+
+// isPooled reports whether the registration made with o has the pooled lifetime.
+func (o *RegistryOpts) isPooled() bool {
+	return o.poolSize > 0 || len(o.poolSizePath) > 0
+}
+
+// pooler is implemented by registries able to pool the instances of a registration, see WithPool.
+type pooler interface {
+	poolOf(ctx Context, opts *RegistryOpts, name string) (*instancePool, error)
+	leasingPool(opts *RegistryOpts, name string) (*instancePool, bool)
+}
+
+// instancePool holds the instances of a pooled registration, slots bounding how many exist.
+type instancePool struct {
+	slots chan struct{}
+	idle  chan any
+}
+
+// Acquire leases an instance of the pooled registration of T, see WithPool, creating it when
+// the pool has no idle instance and isn't full yet, otherwise waiting for a Release until ctx
+// is done. The instance must be handed back with Release once done with it.
+//
+// ctx stands for the scope of the lease: its tenant picks the pool, each tenant pooling its own
+// instances, and its configuration the size read by WithPoolSizeAt. Release is given a ctx of
+// the same tenant, e.g. the same ctx.
+func Acquire[T any](ctx Context, options ...func(opts *RegistryOpts)) (T, error) {
+	var result T
+
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	p, name, err := pooledRegistrationOf[T](&registryOpts)
+	if err != nil {
+		return result, err
+	}
+
+	pool, err := p.poolOf(resolveTenant(ctx, &registryOpts), &registryOpts, name)
+	if err != nil {
+		return result, err
+	}
+
+	select {
+	case instance := <-pool.idle:
+		return instance.(T), nil
+	default:
+	}
+
+	select {
+	case instance := <-pool.idle:
+		return instance.(T), nil
+	case pool.slots <- struct{}{}:
+	case <-ctx.Done():
+		return result, errors.Wrap(ctx.Err(), "acquiring %s aborted, context done", TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), ResolutionCancelledErrorCode)
+	}
+
+	instance, err := Create[T](ctx, append(options[:len(options):len(options)], WithFreshInstance())...)
+	if err != nil {
+		<-pool.slots
+		return result, err
+	}
+
+	return instance, nil
+}
+
+// Release hands instance, leased by Acquire with the same options and a ctx of the same tenant,
+// back to its pool. It fails when no such pool was created by Acquire.
+func Release[T any](ctx Context, instance T, options ...func(opts *RegistryOpts)) error {
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	p, name, err := pooledRegistrationOf[T](&registryOpts)
+	if err != nil {
+		return err
+	}
+
+	resolveTenant(ctx, &registryOpts)
+	pool, ok := p.leasingPool(&registryOpts, name)
+	if !ok {
+		return errors.New("cannot release %s, none was acquired with these options", TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), ErrorCreatingDependencyErrorCode)
+	}
+
+	select {
+	case pool.idle <- instance:
+		return nil
+	default:
+		return errors.New("cannot release %s, its pool is full", TypeNameIn[T](registryOpts.Registry, registryOpts.InjectionToken), ErrorCreatingDependencyErrorCode)
+	}
+}
+
+// pooledRegistrationOf returns the registry of opts, able to pool, with the name of the registration
+// of T a creation with opts would use.
+func pooledRegistrationOf[T any](opts *RegistryOpts) (pooler, string, error) {
+	f := opts.Registry
+	if f == nil {
+		f = DefaultRegistry()
+		opts.Registry = f
+	}
+
+	names := registrationsOf[T](opts)
+	if len(names) == 0 {
+		return nil, "", errors.New("dependency not registered: %s", TypeNameIn[T](f, opts.InjectionToken), DependencyMissingErrorCode)
+	}
+
+	p, ok := f.(pooler)
+	if !ok {
+		return nil, "", errors.New("registry %T cannot pool %s", f, names[0], ErrorCreatingDependencyErrorCode)
+	}
+
+	return p, names[0], nil
+}
+
+func (dif *diRegistry) leasingPool(opts *RegistryOpts, name string) (*instancePool, bool) {
+	pool, ok := dif.pools.Load(hotInstanceKey(opts, name))
+	if !ok {
+		return nil, false
+	}
+
+	return pool.(*instancePool), true
+}
+
+func (dif *diRegistry) poolOf(ctx Context, opts *RegistryOpts, name string) (*instancePool, error) {
+	key := hotInstanceKey(opts, name)
+	if pool, ok := dif.pools.Load(key); ok {
+		return pool.(*instancePool), nil
+	}
+
+	dif.mu.RLock()
+	reg := dif.registrations[dif.registryName(name)]
+	dif.mu.RUnlock()
+	if reg.opts == nil || !reg.opts.isPooled() {
+		return nil, errors.New("%s is not pooled, see WithPool", name, ErrorCreatingDependencyErrorCode)
+	}
+
+	size := reg.opts.poolSize
+	if len(reg.opts.poolSizePath) > 0 && ctx.Configuration() != nil {
+		if node, err := ctx.Configuration().LookupNode(reg.opts.poolSizePath); err == nil && node != nil {
+			configured, good := SafeConvertTypeAssert[int](node)
+			if !good {
+				return nil, errors.New("pool size of %s at %s is a %T, not an int", name, reg.opts.poolSizePath, node, ConfigurationLookupErrorCode)
+			}
+
+			size = configured
+		}
+	}
+
+	if size <= 0 {
+		return nil, errors.New("pool size of %s must be positive, got %d", name, size, ErrorCreatingDependencyErrorCode)
+	}
+
+	pool, _ := dif.pools.LoadOrStore(key, &instancePool{slots: make(chan struct{}, size), idle: make(chan any, size)})
+	return pool.(*instancePool), nil
+}
@@ -0,0 +1,124 @@
+package di
+
+import (
+	goctx "context"
+	"testing"
+	"time"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type poolConfigTest struct {
+	Workers ConfigRawData `json:"workers"`
+}
+
+func (c poolConfigTest) LookupNode(lookupPath string) (any, error) {
+	return ConfigurationNodeLookup(c, lookupPath)
+}
+
+func TestWithPool(t *testing.T) {
+	registry := NewTestRegistry(t)
+	created := 0
+	require.NoError(t, Register[*overrideClientTest](func(ctx Context, opts *RegistryOpts) (*overrideClientTest, error) {
+		created++
+		return &overrideClientTest{Addr: "worker"}, nil
+	}, WithRegistry(registry), WithPool(2)))
+
+	assert.Equal(t, LifetimePooled, registry.List()[0].Lifetime)
+	assert.Equal(t, 2, registry.List()[0].PoolSize)
+
+	_, err := Create[*overrideClientTest](NewContext(), WithRegistry(registry))
+	assert.ErrorContains(t, err, "is pooled, it's created by Acquire")
+
+	first, err := Acquire[*overrideClientTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	second, err := Acquire[*overrideClientTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+
+	ctx, cancel := NewContext().WithDeadline(time.Now().Add(10 * time.Millisecond))
+	defer cancel()
+	_, err = Acquire[*overrideClientTest](ctx, WithRegistry(registry))
+	_, isCancelled := errors.Has(err, ResolutionCancelledErrorCode)
+	assert.True(t, isCancelled, "a full pool waits for a release")
+
+	require.NoError(t, Release(NewContext(), first, WithRegistry(registry)))
+	reused, err := Acquire[*overrideClientTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, first, reused)
+	assert.Equal(t, 2, created)
+
+	released := make(chan *overrideClientTest)
+	go func() {
+		instance, _ := Acquire[*overrideClientTest](NewContext(goctx.Background()), WithRegistry(registry))
+		released <- instance
+	}()
+	require.NoError(t, Release(NewContext(), second, WithRegistry(registry)))
+	assert.Same(t, second, <-released)
+
+	t.Run("size from config", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, Register[*overrideClientTest](func(ctx Context, opts *RegistryOpts) (*overrideClientTest, error) {
+			return &overrideClientTest{}, nil
+		}, WithRegistry(registry), WithPool(1), WithPoolSizeAt("workers.connections")))
+
+		ctx := NewContext(poolConfigTest{Workers: ConfigRawData{"connections": 3}})
+		for range 3 {
+			_, err := Acquire[*overrideClientTest](ctx, WithRegistry(registry))
+			require.NoError(t, err)
+		}
+
+		full, cancel := ctx.WithDeadline(time.Now().Add(10 * time.Millisecond))
+		defer cancel()
+		_, err := Acquire[*overrideClientTest](full, WithRegistry(registry))
+		assert.Error(t, err)
+
+		require.NoError(t, Release(ctx, &overrideClientTest{}, WithRegistry(registry)))
+		_, err = Acquire[*overrideClientTest](ctx, WithRegistry(registry))
+		assert.NoError(t, err)
+	})
+
+	t.Run("per tenant", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, Register[*overrideClientTest](func(ctx Context, opts *RegistryOpts) (*overrideClientTest, error) {
+			tenant, _ := TenantFrom(ctx)
+			return &overrideClientTest{Addr: tenant.String()}, nil
+		}, WithRegistry(registry), WithPool(1)))
+
+		acme := ContextWithTenant(NewContext(), "acme")
+		leased, err := Acquire[*overrideClientTest](acme, WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "acme", leased.Addr)
+
+		assert.Error(t, Release(ContextWithTenant(NewContext(), "globex"), leased, WithRegistry(registry)), "no pool of globex leased it")
+		require.NoError(t, Release(acme, leased, WithRegistry(registry)))
+
+		other, err := Acquire[*overrideClientTest](ContextWithTenant(NewContext(), "globex"), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "globex", other.Addr, "tenants don't share their pools")
+
+		reused, err := Acquire[*overrideClientTest](acme, WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Same(t, leased, reused)
+	})
+	t.Run("type namer", func(t *testing.T) {
+		registry := NewRegistry(WithQualifiedTypeNames())
+		require.NoError(t, Register[*overrideClientTest](func(ctx Context, opts *RegistryOpts) (*overrideClientTest, error) {
+			return &overrideClientTest{}, nil
+		}, WithRegistry(registry), WithPool(1)))
+
+		leased, err := Acquire[*overrideClientTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+
+		ctx, cancel := NewContext().WithDeadline(time.Now().Add(10 * time.Millisecond))
+		defer cancel()
+		_, err = Acquire[*overrideClientTest](ctx, WithRegistry(registry))
+		assert.ErrorContains(t, err, "acquiring "+QualifiedTypeName[*overrideClientTest]()+" aborted")
+
+		require.NoError(t, Release(NewContext(), leased, WithRegistry(registry)))
+		err = Release(NewContext(), leased, WithRegistry(registry))
+		assert.ErrorContains(t, err, "cannot release "+QualifiedTypeName[*overrideClientTest]()+", its pool is full")
+	})
+}
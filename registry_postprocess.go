@@ -0,0 +1,54 @@
+package di
+
+import (
+	"github.com/pixie-sh/errors-go"
+)
+
+// PostProcessor is called with every instance and configuration created by a factory of a registry,
+// before it's kept as hot instance, see RegistryInterceptor.AddPostProcessor. typeName is the registration name,
+// see TypeName and PairTypeName. The instance returned replaces the created one, so it must be of the
+// same type; an error fails the creation.
+type PostProcessor func(ctx Context, typeName string, instance any) (any, error)
+
+// postProcessorRunner is implemented by registries able to post process the instances
+// created by their factories, see RegistryInterceptor.AddPostProcessor.
+type postProcessorRunner interface {
+	postProcess(ctx Context, typeName string, instance any) (any, error)
+}
+
+// AddPostProcessor adds a post processor run after every successful factory call of the registry, in the
+// order added, to apply global policies like injecting a logger, validating invariants or wrapping with
+// tracing proxies. Hot instances are post processed once, when created.
+func (dif *diRegistry) AddPostProcessor(fn PostProcessor) {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	dif.postProcessors = append(dif.postProcessors, fn)
+}
+
+func (dif *diRegistry) postProcess(ctx Context, typeName string, instance any) (any, error) {
+	dif.mu.RLock()
+	postProcessors := dif.postProcessors
+	dif.mu.RUnlock()
+
+	for i, postProcessor := range postProcessors {
+		processed, err := postProcessor(ctx, typeName, instance)
+		if err != nil {
+			return nil, errors.Wrap(err, "post processor %d of %s failed", i, typeName, ErrorCreatingDependencyErrorCode)
+		}
+
+		instance = processed
+	}
+
+	return instance, nil
+}
+
+// postProcessInstance runs instance through the post processors of f, if it supports them.
+func postProcessInstance(ctx Context, f Registry, typeName string, instance any) (any, error) {
+	runner, ok := f.(postProcessorRunner)
+	if !ok {
+		return instance, nil
+	}
+
+	return runner.postProcess(ctx, typeName, instance)
+}
@@ -0,0 +1,52 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type postProcessedServiceTest struct {
+	Name   string
+	Logger string
+}
+
+func TestAddPostProcessor(t *testing.T) {
+	registry := NewTestRegistry(t)
+	var processed []string
+	registry.AddPostProcessor(func(ctx Context, typeName string, instance any) (any, error) {
+		processed = append(processed, typeName)
+		if service, ok := instance.(*postProcessedServiceTest); ok {
+			service.Logger = "json"
+		}
+
+		return instance, nil
+	})
+	registry.AddPostProcessor(func(ctx Context, typeName string, instance any) (any, error) {
+		if service, ok := instance.(*postProcessedServiceTest); ok && service.Name == "" {
+			return nil, errors.New("service without name")
+		}
+
+		return instance, nil
+	})
+
+	require.NoError(t, Register[*postProcessedServiceTest](func(ctx Context, opts *RegistryOpts) (*postProcessedServiceTest, error) {
+		return &postProcessedServiceTest{Name: "billing"}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*postProcessedServiceTest](func(ctx Context, opts *RegistryOpts) (*postProcessedServiceTest, error) {
+		return &postProcessedServiceTest{}, nil
+	}, WithRegistry(registry), WithToken("unnamed")))
+
+	for range 2 {
+		service, err := Create[*postProcessedServiceTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "json", service.Logger)
+	}
+	assert.Equal(t, []string{"di.postProcessedServiceTest"}, processed, "hot instances are post processed once")
+
+	_, err := Create[*postProcessedServiceTest](NewContext(), WithRegistry(registry), WithToken("unnamed"))
+	assert.ErrorContains(t, err, "post processor 1 of unnamed:di.postProcessedServiceTest failed")
+	assert.ErrorContains(t, err, "service without name")
+}
@@ -0,0 +1,48 @@
+package di
+
+import (
+	"reflect"
+	"sync"
+)
+
+// WithQualifiedTypeNames makes the registry name its types with their full import path, see
+// QualifiedTypeName, so packages sharing a short name, e.g. github.com/acme/auth and
+// github.com/acme/legacy/auth, stop overwriting each other's auth.Config registrations.
+// It's WithTypeNamer(QualifiedTypeNamer), names built with TypeName resolving to the qualified
+// name sharing them while migrating.
+func WithQualifiedTypeNames() RegistryOption {
+	return WithTypeNamer(QualifiedTypeNamer)
+}
+
+// QualifiedTypeName returns the registration name of T in a registry made WithQualifiedTypeNames,
+// prefixed by the injection token when provided. It's TypeName with every named type rendered with
+// its full import path:
+//
+//	QualifiedTypeName[auth.Config]()      // "github.com/acme/auth.Config"
+//	QualifiedTypeName[*auth.Config]("ro") // "ro:github.com/acme/auth.Config"
+func QualifiedTypeName[T any](tokens ...InjectionToken) string {
+	names := qualifiedNamesOf(reflect.TypeOf((*T)(nil)).Elem())
+	if len(tokens) > 0 && len(tokens[0]) > 0 {
+		return names.tokened(tokens[0])
+	}
+
+	return names.name
+}
+
+// qualifiedNameCache caches the QualifiedTypeName of every reflect.Type, as typeNameCache does for TypeName.
+var qualifiedNameCache sync.Map
+
+// qualifiedNamesOf returns the cached qualified names of t.
+func qualifiedNamesOf(t reflect.Type) *typeNames {
+	if cached, ok := qualifiedNameCache.Load(t); ok {
+		return cached.(*typeNames)
+	}
+
+	names := &typeNames{name: qualifiedTypeName(t)}
+	if t.Kind() == reflect.Ptr {
+		names.name = qualifiedTypeName(t.Elem())
+	}
+
+	cached, _ := qualifiedNameCache.LoadOrStore(t, names)
+	return cached.(*typeNames)
+}
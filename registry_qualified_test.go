@@ -0,0 +1,61 @@
+package di
+
+import (
+	"math/rand"
+	randv2 "math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type qualifiedServiceTest struct {
+	Name string
+}
+
+func TestWithQualifiedTypeNames(t *testing.T) {
+	registry := NewRegistry(WithQualifiedTypeNames())
+
+	require.Equal(t, TypeName[rand.Rand](), TypeName[randv2.Rand](), "both packages are named rand")
+	require.NoError(t, Register[*rand.Rand](func(ctx Context, opts *RegistryOpts) (*rand.Rand, error) {
+		return rand.New(rand.NewSource(1)), nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*randv2.Rand](func(ctx Context, opts *RegistryOpts) (*randv2.Rand, error) {
+		return randv2.New(randv2.NewPCG(1, 2)), nil
+	}, WithRegistry(registry)))
+
+	assert.True(t, registry.IsRegistered(QualifiedTypeName[rand.Rand]()))
+	assert.True(t, registry.IsRegistered(QualifiedTypeName[randv2.Rand]()))
+	assert.False(t, registry.IsRegistered(TypeName[rand.Rand]()), "ambiguous TypeName")
+
+	legacy, err := Create[*rand.Rand](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	current, err := Create[*randv2.Rand](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.NotNil(t, legacy)
+	assert.NotNil(t, current)
+
+	t.Run("TypeName migration", func(t *testing.T) {
+		require.NoError(t, Register[*qualifiedServiceTest](func(ctx Context, opts *RegistryOpts) (*qualifiedServiceTest, error) {
+			return &qualifiedServiceTest{Name: "billing"}, nil
+		}, WithRegistry(registry), WithToken("billing")))
+
+		assert.Equal(t, "billing:github.com/pixie-sh/di-go.qualifiedServiceTest", QualifiedTypeName[*qualifiedServiceTest]("billing"))
+		assert.True(t, registry.IsRegistered(TypeName[*qualifiedServiceTest]("billing")))
+
+		instance, err := registry.Create(NewContext(), TypeName[*qualifiedServiceTest]("billing"), struct{}{}, &RegistryOpts{Registry: registry})
+		require.NoError(t, err)
+		assert.Equal(t, "billing", instance.(*qualifiedServiceTest).Name)
+
+		hot, err := registry.GetHotInstance(NewContext(), nil, TypeName[*qualifiedServiceTest]("billing"))
+		require.NoError(t, err)
+		assert.Same(t, instance, hot)
+	})
+}
+
+func TestQualifiedTypeName(t *testing.T) {
+	assert.Equal(t, "math/rand.Rand", QualifiedTypeName[*rand.Rand]())
+	assert.Equal(t, "math/rand/v2.Rand", QualifiedTypeName[randv2.Rand]())
+	assert.Equal(t, "[]*math/rand/v2.Rand", QualifiedTypeName[[]*randv2.Rand]())
+	assert.Equal(t, "ro:github.com/pixie-sh/di-go.qualifiedServiceTest", QualifiedTypeName[qualifiedServiceTest]("ro"))
+}
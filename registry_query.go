@@ -0,0 +1,193 @@
+package di
+
+import (
+	"strings"
+)
+
+// IsRegistered reports whether Create[T], or CreatePair[T, CT] for a pair, would find a registration
+// of T with the options, the untokened fallback included unless disabled, without constructing it:
+//
+//	if !di.IsRegistered[MetricsSink]() {
+//		_ = di.Register[MetricsSink](newNoopSink)
+//	}
+//
+// Conditional registrations count as registered, whether their condition holds or not, see
+// IsRegisteredFor to evaluate them.
+func IsRegistered[T any](options ...func(opts *RegistryOpts)) bool {
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	return len(registrationsOf[T](&registryOpts)) > 0
+}
+
+// IsRegisteredFor is IsRegistered for a creation with ctx, the conditional registrations only
+// counting when their condition holds for ctx, e.g. WithCondition predicates reading its configuration.
+func IsRegisteredFor[T any](ctx Context, options ...func(opts *RegistryOpts)) bool {
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	if registryOpts.Registry == nil {
+		registryOpts.Registry = DefaultRegistry()
+	}
+
+	ctx = resolveTenant(ctx, &registryOpts)
+	for _, name := range registrationsOf[T](&registryOpts) {
+		if registeredFor(ctx, registryOpts.Registry, name) {
+			return true
+		}
+	}
+
+	if len(registryOpts.InjectionToken) == 0 || tokenFallbackDisabled(registryOpts.Registry, &registryOpts) {
+		return false
+	}
+
+	// the tokened registrations whose condition doesn't hold fall back to the untokened ones
+	untokened := registryOpts
+	untokened.InjectionToken = ""
+	for _, name := range registrationsOf[T](&untokened) {
+		if registeredFor(ctx, registryOpts.Registry, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// registrationSelector is implemented by registries able to tell whether a creation would select
+// one of the registrations of a name, evaluating the conditional ones, see WithCondition.
+type registrationSelector interface {
+	selectsRegistration(ctx Context, typeNameOf string) bool
+	selectsConfigurationRegistration(ctx Context, typeNameOf string) bool
+}
+
+// registeredFor reports whether a creation of name with ctx would select a registration of f,
+// the conditional ones only counting when their condition holds for ctx.
+func registeredFor(ctx Context, f Registry, name string) bool {
+	if selector, ok := f.(registrationSelector); ok {
+		return selector.selectsRegistration(ctx, name)
+	}
+
+	return registered(f, name)
+}
+
+// configurationRegisteredFor is the configuration counterpart of registeredFor.
+func configurationRegisteredFor(ctx Context, f Registry, name string) bool {
+	if selector, ok := f.(registrationSelector); ok {
+		return selector.selectsConfigurationRegistration(ctx, name)
+	}
+
+	return configurationRegistered(f, name)
+}
+
+// registered reports whether f holds a registration of name, conditional or not.
+// It's false for registries not implementing RegistryInspector, which can't tell.
+func registered(f Registry, name string) bool {
+	inspector, ok := f.(RegistryInspector)
+	return ok && inspector.IsRegistered(name)
+}
+
+// configurationRegistered is the configuration counterpart of registered.
+func configurationRegistered(f Registry, name string) bool {
+	inspector, ok := f.(RegistryInspector)
+	return ok && inspector.IsConfigurationRegistered(name)
+}
+
+// registrationsIn lists the registrations of f, none for registries not implementing RegistryInspector.
+func registrationsIn(f Registry) []RegistrationInfo {
+	if inspector, ok := f.(RegistryInspector); ok {
+		return inspector.List()
+	}
+
+	return nil
+}
+
+// HasHotInstance reports whether Create[T] with the options would return a hot instance of T,
+// created earlier and not expired, rather than running a factory.
+func HasHotInstance[T any](options ...func(opts *RegistryOpts)) bool {
+	_, ok := GetHot[T](NewContext(), options...)
+	return ok
+}
+
+// GetHot returns the hot instance Create[T] with the options would return, false when there is none,
+// without ever running a factory, e.g. in shutdown paths and admin endpoints which must not build
+// components lazily. Decorators and post processors already applied to the hot instance are kept.
+func GetHot[T any](ctx Context, options ...func(opts *RegistryOpts)) (T, bool) {
+	var result T
+
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	f := registryOpts.Registry
+	if f == nil {
+		f = DefaultRegistry()
+	}
+
+	ctx = resolveTenant(ctx, &registryOpts)
+	for _, name := range registrationsOf[T](&registryOpts) {
+		instance, err := f.GetHotInstance(ctx, &registryOpts, name)
+		if err != nil {
+			continue
+		}
+
+		if typed, ok := typeAssertFor[T](instance, &registryOpts); ok {
+			return typed, true
+		}
+	}
+
+	return result, false
+}
+
+// registrationsOf returns the names of the instance registrations of T, pairs included, a creation
+// with opts would use: the ones under the token of opts, then the untokened ones when falling back.
+func registrationsOf[T any](opts *RegistryOpts) []string {
+	f := opts.Registry
+	if f == nil {
+		f = DefaultRegistry()
+	}
+
+	tokened, untokened := TypeNameIn[T](f, opts.InjectionToken), TypeNameIn[T](f)
+	var names, fallbacks []string
+	for _, info := range registrationsIn(f) {
+		if info.Configuration {
+			continue
+		}
+
+		first, _, _ := strings.Cut(info.Name, ";")
+		switch first {
+		case tokened:
+			names = append(names, info.Name)
+		case untokened:
+			fallbacks = append(fallbacks, info.Name)
+		}
+	}
+
+	if len(names) > 0 || tokenFallbackDisabled(f, opts) {
+		return names
+	}
+
+	return fallbacks
+}
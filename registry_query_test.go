@@ -0,0 +1,116 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRegistered(t *testing.T) {
+	registry := NewTestRegistry(t)
+	assert.False(t, IsRegistered[*loggerTest](WithRegistry(registry)))
+
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{Level: "INFO"}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, RegisterPair[*overrideClientTest, overrideClientConfigTest](
+		func(ctx Context, opts *RegistryOpts, config overrideClientConfigTest) (*overrideClientTest, error) {
+			return &overrideClientTest{Addr: config.Addr}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (overrideClientConfigTest, error) {
+			return overrideClientConfigTest{Addr: "billing:8080"}, nil
+		},
+		WithRegistry(registry),
+		WithToken("billing"),
+	))
+
+	assert.True(t, IsRegistered[*loggerTest](WithRegistry(registry)))
+	assert.True(t, IsRegistered[*loggerTest](WithRegistry(registry), WithToken("audit")), "untokened fallback")
+	assert.False(t, IsRegistered[*loggerTest](WithRegistry(registry), WithToken("audit"), WithStrictToken()))
+	assert.True(t, IsRegistered[*overrideClientTest](WithRegistry(registry), WithToken("billing")))
+	assert.False(t, IsRegistered[*overrideClientTest](WithRegistry(registry)))
+	assert.False(t, IsRegistered[overrideClientConfigTest](WithRegistry(registry), WithToken("billing")), "configurations are not instances")
+}
+
+func TestHasHotInstance(t *testing.T) {
+	registry := NewTestRegistry(t)
+	calls := 0
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		calls++
+		return &loggerTest{Level: "INFO"}, nil
+	}, WithRegistry(registry)))
+
+	assert.False(t, HasHotInstance[*loggerTest](WithRegistry(registry)))
+	assert.Equal(t, 0, calls, "querying doesn't construct")
+
+	_, err := Create[*loggerTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.True(t, HasHotInstance[*loggerTest](WithRegistry(registry)))
+	assert.False(t, HasHotInstance[*loggerTest](WithRegistry(registry), WithToken("audit")), "fallbacks keep their own hot instance")
+
+	_, err = Create[*loggerTest](NewContext(), WithRegistry(registry), WithToken("audit"))
+	require.NoError(t, err)
+	assert.True(t, HasHotInstance[*loggerTest](WithRegistry(registry), WithToken("audit")))
+
+	require.NoError(t, InvalidateHot[*loggerTest](WithRegistry(registry)))
+	assert.False(t, HasHotInstance[*loggerTest](WithRegistry(registry)))
+	assert.False(t, HasHotInstance[*serviceTest](WithRegistry(registry)))
+}
+
+func TestGetHot(t *testing.T) {
+	registry := NewTestRegistry(t)
+	calls := 0
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		calls++
+		return &loggerTest{Level: "INFO"}, nil
+	}, WithRegistry(registry)))
+
+	_, ok := GetHot[*loggerTest](NewContext(), WithRegistry(registry))
+	assert.False(t, ok)
+	assert.Equal(t, 0, calls, "the factory is never run")
+
+	created, err := Create[*loggerTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+
+	hot, ok := GetHot[*loggerTest](NewContext(), WithRegistry(registry))
+	assert.True(t, ok)
+	assert.Same(t, created, hot)
+
+	_, ok = GetHot[*loggerTest](ContextWithTenant(NewContext(), "acme"), WithRegistry(registry))
+	assert.False(t, ok, "tenants have their own hot instances")
+	assert.Equal(t, 1, calls)
+}
+
+func TestIsRegisteredFor(t *testing.T) {
+	registry := NewTestRegistry(t)
+	isS3 := func(ctx Context) bool {
+		driver, _ := ctx.Value(tenantIDKeyTest{}).(string)
+		return driver == "s3"
+	}
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{Level: "S3"}, nil
+	}, WithRegistry(registry), WithToken("storage"), WithCondition(isS3)))
+
+	s3 := NewContext().WithValue(tenantIDKeyTest{}, "s3")
+	assert.True(t, IsRegistered[*loggerTest](WithRegistry(registry), WithToken("storage")), "conditions are not evaluated without context")
+	assert.True(t, IsRegisteredFor[*loggerTest](s3, WithRegistry(registry), WithToken("storage")))
+	assert.False(t, IsRegisteredFor[*loggerTest](NewContext(), WithRegistry(registry), WithToken("storage")))
+
+	report, err := Explain[*loggerTest](NewContext(), WithRegistry(registry), WithToken("storage"), WithStrictToken())
+	assert.Error(t, err)
+	assert.Equal(t, "no registration condition holds", report.Steps[0].Reason)
+
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{Level: "local"}, nil
+	}, WithRegistry(registry)))
+	assert.True(t, IsRegisteredFor[*loggerTest](NewContext(), WithRegistry(registry), WithToken("storage")), "falls back to the untokened registration")
+
+	report, err = Explain[*loggerTest](NewContext(), WithRegistry(registry), WithToken("storage"))
+	require.NoError(t, err)
+	assert.True(t, report.TokenFallback)
+
+	logger, err := Create[*loggerTest](NewContext(), WithRegistry(registry), WithToken("storage"))
+	require.NoError(t, err)
+	assert.Equal(t, "local", logger.Level)
+}
@@ -0,0 +1,124 @@
+package di
+
+import (
+	"github.com/pixie-sh/errors-go"
+)
+
+// ReadOnlyRegistry is a view of a Registry exposing creation and introspection only, handed to
+// application code that must not register anything once bootstrapped. Use it with Create and
+// friends through WithRegistryView. It's implemented by this package only, see ReadOnly. Views of
+// registries not implementing RegistryInspector or RegistryLifecycle have nothing to introspect.
+type ReadOnlyRegistry interface {
+	Create(ctx Context, typeNameOf string, c any, opts *RegistryOpts) (any, error)
+	CreateConfiguration(ctx Context, typeNameOf string, opts *RegistryOpts) (any, error)
+	GetHotInstance(ctx Context, opts *RegistryOpts, name string) (any, error)
+	IsRegistered(typeNameOf string) bool
+	IsConfigurationRegistered(typeNameOf string) bool
+	ConfigOf(instance any) (any, bool)
+	List() []RegistrationInfo
+	Validate(ctx Context) error
+	Graph() Graph
+	WarmUpPlan() []WarmUpStep
+	Workers() []WorkerStatus
+
+	// registry returns the viewed registry, used as such by the creation functions
+	registry() Registry
+}
+
+type readOnlyRegistry struct {
+	r Registry
+}
+
+// ReadOnly returns a read-only view of r.
+func ReadOnly(r Registry) ReadOnlyRegistry {
+	return readOnlyRegistry{r}
+}
+
+// WithRegistryView returns a function that sets the registry viewed by view in the options.
+// It's the WithRegistry counterpart for code holding a ReadOnlyRegistry, Register, RegisterPair,
+// RegisterConfiguration, Replace, Unregister and Decorate fail with ReadOnlyRegistryErrorCode
+// given it.
+func WithRegistryView(view ReadOnlyRegistry) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.Registry = view.registry()
+		opts.viewed = true
+	}
+}
+
+// rejectView fails the registrations made with the registry of a view, see WithRegistryView.
+func rejectView(opts *RegistryOpts) error {
+	if opts.viewed {
+		return errors.New("registry set by WithRegistryView is read-only", ReadOnlyRegistryErrorCode)
+	}
+
+	return nil
+}
+
+func (v readOnlyRegistry) Create(ctx Context, typeNameOf string, c any, opts *RegistryOpts) (any, error) {
+	return v.r.Create(ctx, typeNameOf, c, opts)
+}
+
+func (v readOnlyRegistry) CreateConfiguration(ctx Context, typeNameOf string, opts *RegistryOpts) (any, error) {
+	return v.r.CreateConfiguration(ctx, typeNameOf, opts)
+}
+
+func (v readOnlyRegistry) GetHotInstance(ctx Context, opts *RegistryOpts, name string) (any, error) {
+	return v.r.GetHotInstance(ctx, opts, name)
+}
+
+func (v readOnlyRegistry) IsRegistered(typeNameOf string) bool {
+	return registered(v.r, typeNameOf)
+}
+
+func (v readOnlyRegistry) IsConfigurationRegistered(typeNameOf string) bool {
+	return configurationRegistered(v.r, typeNameOf)
+}
+
+func (v readOnlyRegistry) ConfigOf(instance any) (any, bool) {
+	if inspector, ok := v.r.(RegistryInspector); ok {
+		return inspector.ConfigOf(instance)
+	}
+
+	return nil, false
+}
+
+func (v readOnlyRegistry) List() []RegistrationInfo {
+	return registrationsIn(v.r)
+}
+
+func (v readOnlyRegistry) Validate(ctx Context) error {
+	if inspector, ok := v.r.(RegistryInspector); ok {
+		return inspector.Validate(ctx)
+	}
+
+	return errors.New("registry of type %T cannot be validated", v.r, ErrorCreatingDependencyErrorCode)
+}
+
+func (v readOnlyRegistry) Graph() Graph {
+	return graphOf(v.r)
+}
+
+func (v readOnlyRegistry) WarmUpPlan() []WarmUpStep {
+	if inspector, ok := v.r.(RegistryInspector); ok {
+		return inspector.WarmUpPlan()
+	}
+
+	return nil
+}
+
+func (v readOnlyRegistry) Workers() []WorkerStatus {
+	if lifecycle, ok := v.r.(RegistryLifecycle); ok {
+		return lifecycle.Workers()
+	}
+
+	return nil
+}
+
+func (v readOnlyRegistry) registry() Registry {
+	return v.r
+}
+
+// ReadOnly returns a read-only view of the registry.
+func (dif *diRegistry) ReadOnly() ReadOnlyRegistry {
+	return ReadOnly(dif)
+}
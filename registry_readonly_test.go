@@ -0,0 +1,51 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly(t *testing.T) {
+	registry := NewTestRegistry(t)
+	calls := 0
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		calls++
+		return &loggerTest{Level: "info"}, nil
+	}, WithRegistry(registry)))
+
+	view := registry.ReadOnly()
+	_, canRegister := view.(Registry)
+	assert.False(t, canRegister, "views must not expose registration")
+	assert.True(t, view.IsRegistered(TypeName[*loggerTest]()))
+
+	logger, err := Create[*loggerTest](NewContext(), WithRegistryView(view))
+	require.NoError(t, err)
+	assert.Equal(t, "info", logger.Level)
+
+	again, err := Create[*loggerTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, logger, again, "views share the hot instances of the registry")
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithRegistryViewRejectsRegistration(t *testing.T) {
+	registry := NewTestRegistry(t)
+	view := registry.ReadOnly()
+	factory := func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{Level: "debug"}, nil
+	}
+
+	err := Register[*loggerTest](factory, WithRegistryView(view))
+	assert.ErrorContains(t, err, "ReadOnlyRegistryErrorCode")
+	err = Replace[*loggerTest](factory, WithRegistryView(view))
+	assert.ErrorContains(t, err, "ReadOnlyRegistryErrorCode")
+	err = Decorate[*loggerTest](func(ctx Context, inner *loggerTest) (*loggerTest, error) {
+		return inner, nil
+	}, WithRegistryView(view))
+	assert.ErrorContains(t, err, "ReadOnlyRegistryErrorCode")
+	assert.False(t, view.IsRegistered(TypeName[*loggerTest]()), "late registrations through a view are rejected")
+
+	require.NoError(t, Register[*loggerTest](factory, WithRegistryView(view), WithRegistry(registry)), "WithRegistry takes the registry back")
+}
@@ -1,6 +1,14 @@
 package di
 
 import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
 	"github.com/pixie-sh/errors-go"
 )
 
@@ -12,7 +20,7 @@ func RegisterPair[T any, CT Configuration](
 	fnCT TypedCreateInstanceNoConfigHandler[CT],
 	options ...func(opts *RegistryOpts)) error {
 	registryOpts := RegistryOpts{
-		Registry:       Instance,
+		Registry:       DefaultRegistry(),
 		InjectionToken: "",
 	}
 
@@ -22,6 +30,7 @@ func RegisterPair[T any, CT Configuration](
 		}
 	}
 
+	registryOpts.callSite = callSite(1)
 	return registerPairWithToken[T, CT](fn, fnCT, &registryOpts)
 }
 
@@ -30,7 +39,7 @@ func RegisterPair[T any, CT Configuration](
 // Options can be provided to customize the registration behavior.
 func Register[T any](fn TypedCreateInstanceNoConfigHandler[T], options ...func(*RegistryOpts)) error {
 	registryOpts := RegistryOpts{
-		Registry:       Instance,
+		Registry:       DefaultRegistry(),
 		InjectionToken: "",
 	}
 
@@ -40,6 +49,7 @@ func Register[T any](fn TypedCreateInstanceNoConfigHandler[T], options ...func(*
 		}
 	}
 
+	registryOpts.callSite = callSite(1)
 	return registerSingleWithToken[T](fn, &registryOpts)
 }
 
@@ -48,7 +58,7 @@ func Register[T any](fn TypedCreateInstanceNoConfigHandler[T], options ...func(*
 // Options can be provided to customize the registration behavior.
 func RegisterConfiguration[T Configuration](fn TypedCreateInstanceNoConfigHandler[T], options ...func(*RegistryOpts)) error {
 	registryOpts := RegistryOpts{
-		Registry:       Instance,
+		Registry:       DefaultRegistry(),
 		InjectionToken: "",
 	}
 
@@ -58,14 +68,60 @@ func RegisterConfiguration[T Configuration](fn TypedCreateInstanceNoConfigHandle
 		}
 	}
 
+	registryOpts.callSite = callSite(1)
 	return registerSingleConfigurationWithToken[T](fn, &registryOpts)
 }
 
+// Unregister removes the registration of type T from the registry, together with any hot instance
+// created from it. Options can be provided to select the registry and injection token.
+func Unregister[T any](options ...func(*RegistryOpts)) error {
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	return unregisterSingleWithToken[T](&registryOpts)
+}
+
+// Replace swaps the registration of type T for the provided creation function, invalidating
+// the hot instance of the previous one. T does not need to be registered beforehand.
+// Options can be provided to customize the registration behavior.
+func Replace[T any](fn TypedCreateInstanceNoConfigHandler[T], options ...func(*RegistryOpts)) error {
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	registryOpts.callSite = callSite(1)
+	err := unregisterSingleWithToken[T](&registryOpts)
+	if _, isMissing := errors.Has(err, DependencyMissingErrorCode); err != nil && !isMissing {
+		return err
+	}
+
+	return registerSingleWithToken[T](fn, &registryOpts)
+}
+
 // registerPairWithToken is an internal function that handles the registration of a type pair with specific tokens.
 // It registers both the configuration type CT and the dependent type T with their respective creation functions.
 func registerPairWithToken[T any, CT any](fn TypedCreateInstanceHandler[T, CT], fnCT TypedCreateInstanceNoConfigHandler[CT], opts *RegistryOpts) error {
+	if err := rejectView(opts); err != nil {
+		return err
+	}
+
 	var (
-		f     = Instance
+		f     = DefaultRegistry()
 		err   error
 		token = opts.InjectionToken
 	)
@@ -74,28 +130,48 @@ func registerPairWithToken[T any, CT any](fn TypedCreateInstanceHandler[T, CT],
 		f = opts.Registry
 	}
 
-	ctType := TypeName[CT](token)
-	tType := TypeName[T](token)
+	if opts.OptionalConfigNode {
+		fnCT = optionalConfigNodeHandler(fnCT)
+	}
+
+	if opts.ConfigDefaults != nil {
+		fnCT = configDefaultsHandler(fnCT, opts.ConfigDefaults)
+	}
+
+	ctType := TypeNameIn[CT](f, token)
+	tType := TypeNameIn[T](f, token)
 	pairTypeName := PairTypeName(ctType, tType)
-	err = f.RegisterConfiguration(pairTypeName, fromHotMemoryRegisterNoConfig(f, fnCT, pairTypeName), opts)
+	err = f.RegisterConfiguration(pairTypeName, fromHotMemoryRegisterNoConfig(f, fnCT, pairTypeName, opts.TTL, opts.Ownership), opts)
 	if err != nil {
 		return errors.Wrap(err, "failed to RegisterPair configuration creator", ErrorCreatingDependencyErrorCode)
 	}
 
 	pairTypeName = PairTypeName(tType, ctType)
-	err = f.Register(pairTypeName, fromHotMemoryRegisterWithConfig(f, fn, pairTypeName), opts)
+	fn = decoratedPairHandler(f, lifecyclePairHandler(fn, tType), tType)
+	err = f.Register(pairTypeName, fromHotMemoryRegisterWithConfig(f, fn, pairTypeName, opts.TTL, opts.Ownership), opts)
 	if err != nil {
 		return errors.Wrap(err, "failed to RegisterPair creator", ErrorCreatingDependencyErrorCode)
 	}
 
+	if recorder, ok := f.(eagerRecorder); ok && opts.Eager {
+		recorder.recordEager(pairTypeName, opts.WarmupPriority, func(ctx Context) error {
+			_, err := CreatePair[T, CT](ctx, WithOpts(opts))
+			return err
+		})
+	}
+
 	return nil
 }
 
 // registerSingleWithToken is an internal function that registers a single type T with a specific token.
 // It handles the registration of types that don't require configuration.
 func registerSingleWithToken[T any](fn TypedCreateInstanceNoConfigHandler[T], opts *RegistryOpts) error {
+	if err := rejectView(opts); err != nil {
+		return err
+	}
+
 	var (
-		f     = Instance
+		f     = DefaultRegistry()
 		err   error
 		token = opts.InjectionToken
 	)
@@ -104,23 +180,53 @@ func registerSingleWithToken[T any](fn TypedCreateInstanceNoConfigHandler[T], op
 		f = opts.Registry
 	}
 
-	tType := TypeName[T](token)
-	fromHotFn := fromHotMemoryRegisterNoConfig(f, fn, tType)
+	tType := TypeNameIn[T](f, token)
+	if opts.fallback != nil {
+		fn = fallbackHandler(fn, opts.fallback, tType)
+	}
+
+	fn = decoratedHandler(f, lifecycleHandler(fn, tType), tType)
+	fromHotFn := fromHotMemoryRegisterNoConfig(f, fn, tType, opts.TTL, opts.Ownership)
+	pooled := opts.isPooled()
 	err = f.Register(tType, func(ctx Context, opts *RegistryOpts, _ any) (any, error) {
+		if pooled && (opts == nil || !opts.freshInstance) {
+			return nil, errors.New("%s is pooled, it's created by Acquire", tType, ErrorCreatingDependencyErrorCode)
+		}
+
+		if opts != nil && opts.freshInstance {
+			opts.freshInstance = false
+			return freshInstance(ctx, f, fn, tType, opts, pooled)
+		}
+
 		return fromHotFn(ctx, opts)
 	}, opts)
 	if err != nil {
 		return errors.Wrap(err, "failed to RegisterPair creator", ErrorCreatingDependencyErrorCode)
 	}
 
+	if recorder, ok := f.(typeRecorder); ok {
+		recorder.recordType(tType, reflect.TypeOf((*T)(nil)).Elem())
+	}
+
+	if recorder, ok := f.(eagerRecorder); ok && opts.Eager {
+		recorder.recordEager(tType, opts.WarmupPriority, func(ctx Context) error {
+			_, err := Create[T](ctx, WithOpts(opts))
+			return err
+		})
+	}
+
 	return nil
 }
 
 // registerSingleConfigurationWithToken is an internal function that registers a configuration type T with a specific token.
 // It handles the registration of configuration types in the dependency injection system.
 func registerSingleConfigurationWithToken[T any](fn TypedCreateInstanceNoConfigHandler[T], opts *RegistryOpts) error {
+	if err := rejectView(opts); err != nil {
+		return err
+	}
+
 	var (
-		f     = Instance
+		f     = DefaultRegistry()
 		err   error
 		token = opts.InjectionToken
 	)
@@ -129,8 +235,20 @@ func registerSingleConfigurationWithToken[T any](fn TypedCreateInstanceNoConfigH
 		f = opts.Registry
 	}
 
-	tType := TypeName[T](token)
-	err = f.RegisterConfiguration(tType, fromHotMemoryRegisterNoConfig(f, fn, tType), opts)
+	if opts.OptionalConfigNode {
+		fn = optionalConfigNodeHandler(fn)
+	}
+
+	if opts.ConfigDefaults != nil {
+		fn = configDefaultsHandler(fn, opts.ConfigDefaults)
+	}
+
+	tType := TypeNameIn[T](f, token)
+	if opts.fallback != nil {
+		fn = fallbackHandler(fn, opts.fallback, tType)
+	}
+
+	err = f.RegisterConfiguration(tType, fromHotMemoryRegisterNoConfig(f, fn, tType, opts.TTL, opts.Ownership), opts)
 	if err != nil {
 		return errors.Wrap(err, "failed to RegisterPair creator", ErrorCreatingDependencyErrorCode)
 	}
@@ -138,17 +256,107 @@ func registerSingleConfigurationWithToken[T any](fn TypedCreateInstanceNoConfigH
 	return nil
 }
 
+// unregisterSingleWithToken is an internal function that removes the registration of type T with a specific token.
+func unregisterSingleWithToken[T any](opts *RegistryOpts) error {
+	if err := rejectView(opts); err != nil {
+		return err
+	}
+
+	var (
+		f     = DefaultRegistry()
+		token = opts.InjectionToken
+	)
+
+	if opts.Registry != nil {
+		f = opts.Registry
+	}
+
+	composer, ok := f.(RegistryComposer)
+	if !ok {
+		return errors.New("registry of type %T cannot unregister", f, ErrorCreatingDependencyErrorCode)
+	}
+
+	tType := TypeNameIn[T](f, token)
+	err := composer.Unregister(tType, opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to Unregister %s", tType, ErrorCreatingDependencyErrorCode)
+	}
+
+	return nil
+}
+
+// optionalConfigNodeHandler wraps a configuration creation function so it's only called when
+// the configuration node it would look up exists, returning the zero value otherwise.
+func optionalConfigNodeHandler[CT any](fn TypedCreateInstanceNoConfigHandler[CT]) TypedCreateInstanceNoConfigHandler[CT] {
+	return func(ctx Context, opts *RegistryOpts) (CT, error) {
+		var zero CT
+
+		lookupPath, err := assembleConfigurationLookupPath(ctx, opts)
+		if err != nil {
+			return zero, err
+		}
+
+		if !HasConfigNode(ctx, lookupPath) {
+			return zero, nil
+		}
+
+		return fn(ctx, opts)
+	}
+}
+
+// hotRegistry returns the registry holding hot instances for a creation as selected by ownership,
+// the registry the creation was resolved through unless the registration asks otherwise.
+func hotRegistry(ctx Context, registeredWith Registry, ownership InstanceOwnership, opts *RegistryOpts) Registry {
+	switch ownership {
+	case OwnedByRegisteringRegistry:
+		if registeredWith != nil {
+			return registeredWith
+		}
+	case OwnedByRootRegistry:
+		if diCtx, ok := ctx.(*context); ok && diCtx.rootRegistry != nil {
+			return diCtx.rootRegistry
+		}
+	}
 
-func fromHotMemoryRegisterWithConfig[T any, CT any](f Registry, fn TypedCreateInstanceHandler[T, CT], typeName string) func(ctx Context, opts *RegistryOpts, c any) (any, error) {
+	if opts != nil && opts.Registry != nil {
+		return opts.Registry
+	}
+
+	return registeredWith
+}
+
+func fromHotMemoryRegisterWithConfig[T any, CT any](f Registry, fn TypedCreateInstanceHandler[T, CT], typeName string, ttl time.Duration, ownership InstanceOwnership) func(ctx Context, opts *RegistryOpts, c any) (any, error) {
 	return func(ctx Context, opts *RegistryOpts, c any) (any, error) {
+		registeredWith, f := f, hotRegistry(ctx, f, ownership, opts)
+		if opts != nil && (opts.configOverride != nil || opts.freshInstance) {
+			// the instances of overridden configurations and fresh instances are not hot instances,
+			// see WithConfigOverride and WithFreshInstance
+			opts.configOverride, opts.freshInstance = nil, false
+			resultInstance, err := fn(ctx, opts, c.(CT))
+			opts.disposable = nil
+			if err != nil {
+				return nil, err
+			}
+
+			return postProcessInstance(ctx, registeredWith, typeName, resultInstance)
+		}
+
 		resultInstance, err := f.GetHotInstance(ctx, opts, typeName)
-		_, isMissing := errors.Has(err, DependencyMissingErrorCode)
-		if err != nil && !isMissing {
+		if err == nil {
+			return servedHot(opts, resultInstance), nil
+		}
+
+		if _, isMissing := errors.Has(err, DependencyMissingErrorCode); !isMissing {
 			return resultInstance, err
 		}
 
+		ctx, unlock := lockHotInstance(ctx, f, opts, typeName)
+		defer unlock()
+
+		// another creation may have set it while waiting for the lock
+		resultInstance, err = f.GetHotInstance(ctx, opts, typeName)
 		if err == nil {
-			return resultInstance, nil
+			return servedHot(opts, resultInstance), nil
 		}
 
 		resultInstance, err = fn(ctx, opts, c.(CT))
@@ -156,25 +364,54 @@ func fromHotMemoryRegisterWithConfig[T any, CT any](f Registry, fn TypedCreateIn
 			return nil, err
 		}
 
+		resultInstance, err = postProcessInstance(ctx, registeredWith, typeName, resultInstance)
+		if err != nil {
+			return nil, err
+		}
+
 		err = f.SetHotInstance(ctx, opts, typeName, resultInstance)
 		if err != nil {
 			return nil, err
 		}
 
+		recordDisposable(f, opts, typeName, true)
+
+		if expirer, ok := f.(hotExpirer); ok && ttl > 0 {
+			expirer.expireHotInstance(ctx, opts, typeName, ttl)
+		}
+
+		if tagger, ok := f.(hotTagger); ok && len(opts.tags) > 0 {
+			tagger.tagHotInstance(opts, typeName, opts.tags)
+			opts.tags = nil
+		}
+
+		if pinner, ok := f.(configPinner); ok {
+			pinner.pinConfig(opts, typeName, c)
+		}
+
 		return resultInstance, nil
 	}
 }
 
-func fromHotMemoryRegisterNoConfig[T any](f Registry, fn TypedCreateInstanceNoConfigHandler[T], typeName string) func(ctx Context, opts *RegistryOpts) (any, error) {
+func fromHotMemoryRegisterNoConfig[T any](f Registry, fn TypedCreateInstanceNoConfigHandler[T], typeName string, ttl time.Duration, ownership InstanceOwnership) func(ctx Context, opts *RegistryOpts) (any, error) {
 	return func(ctx Context, opts *RegistryOpts) (any, error) {
+		registeredWith, f := f, hotRegistry(ctx, f, ownership, opts)
 		resultInstance, err := f.GetHotInstance(ctx, opts, typeName)
-		_, isMissing := errors.Has(err, DependencyMissingErrorCode)
-		if err != nil && !isMissing {
+		if err == nil {
+			return servedHot(opts, resultInstance), nil
+		}
+
+		if _, isMissing := errors.Has(err, DependencyMissingErrorCode); !isMissing {
 			return resultInstance, err
 		}
 
+		ctx, unlock := lockHotInstance(ctx, f, opts, typeName)
+		defer unlock()
+
+		// another creation may have set it while waiting for the lock
+		resultInstance, err = f.GetHotInstance(ctx, opts, typeName)
 		if err == nil {
-			return resultInstance, nil
+			return servedHot(opts, resultInstance), nil
 		}
 
 		resultInstance, err = fn(ctx, opts)
@@ -182,11 +419,87 @@ func fromHotMemoryRegisterNoConfig[T any](f Registry, fn TypedCreateInstanceNoCo
 			return nil, err
 		}
 
+		resultInstance, err = postProcessInstance(ctx, registeredWith, typeName, resultInstance)
+		if err != nil {
+			return nil, err
+		}
+
 		err = f.SetHotInstance(ctx, opts, typeName, resultInstance)
 		if err != nil {
 			return nil, err
 		}
 
+		recordDisposable(f, opts, typeName, true)
+
+		if expirer, ok := f.(hotExpirer); ok && ttl > 0 {
+			expirer.expireHotInstance(ctx, opts, typeName, ttl)
+		}
+
+		if tagger, ok := f.(hotTagger); ok && len(opts.tags) > 0 {
+			tagger.tagHotInstance(opts, typeName, opts.tags)
+			opts.tags = nil
+		}
+
+		if recorder, ok := f.(configPathRecorder); ok && len(opts.configPath) > 0 {
+			recorder.recordConfigPath(opts, typeName, opts.configPath)
+			opts.configPath = ""
+		}
+
 		return resultInstance, nil
 	}
 }
+
+// servedHot marks the creation made with opts as served by the hot instance, see CreateEvent.HotInstance.
+func servedHot(opts *RegistryOpts, instance any) any {
+	if opts != nil {
+		opts.servedHot = true
+	}
+
+	return instance
+}
+
+// lockHotInstance serializes the creations of the hot instance typeName, so its factory runs once
+// under concurrent first use while the others wait for it and get the hot instance. It returns the
+// context to create with, remembering the instance is being created so a factory requesting its own
+// type again up the resolution doesn't wait for itself.
+//
+// The lock is released once the context is done, e.g. when WithCreateTimeout abandons the creation,
+// so the creations coming next run the factory again instead of waiting for a factory ignoring its
+// context forever.
+func lockHotInstance(ctx Context, f Registry, opts *RegistryOpts, typeName string) (Context, func()) {
+	locker, ok := f.(hotLocker)
+	diCtx, isDiCtx := ctx.(*context)
+	if !ok || !isDiCtx {
+		return ctx, func() {}
+	}
+
+	key := hotInstanceKey(opts, typeName)
+	if slices.Contains(diCtx.creating, key) {
+		return ctx, func() {}
+	}
+
+	creatingCtx := diCtx.Clone().(*context)
+	creatingCtx.isScoped = diCtx.isScoped
+	creatingCtx.creating = append(slices.Clone(diCtx.creating), key)
+	unlock := locker.lockHotInstance(key)
+	if creatingCtx.Done() == nil {
+		return creatingCtx, unlock
+	}
+
+	release := sync.OnceFunc(unlock)
+	stop := goctx.AfterFunc(creatingCtx.Inner(), release)
+	return creatingCtx, func() {
+		stop()
+		release()
+	}
+}
+
+// callSite returns the file:line of the caller skip frames above the function calling callSite.
+func callSite(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s:%d", file, line)
+}
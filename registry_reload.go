@@ -0,0 +1,100 @@
+package di
+
+import (
+	"slices"
+	"strings"
+)
+
+// configLink links a hot instance to the configuration node path it was looked up from.
+// instanceKey is the hot instance key of the pair instance built from it, if any.
+type configLink struct {
+	path        string
+	name        string
+	instanceKey string
+}
+
+// configPathRecorder is implemented by registries able to rebuild the hot instances
+// built from a configuration node when it changes, see HotInstanceEvictor.ConfigurationChanged.
+type configPathRecorder interface {
+	recordConfigPath(opts *RegistryOpts, typeName string, path string)
+}
+
+func (dif *diRegistry) recordConfigPath(opts *RegistryOpts, typeName string, path string) {
+	key := hotInstanceKey(opts, typeName)
+	link := configLink{path: path, name: typeName}
+	if first, second, isPair := strings.Cut(typeName, ";"); isPair {
+		link.instanceKey = hotInstanceKey(opts, PairTypeName(second, first))
+	}
+
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	if _, ok := dif.hotInstances[key]; ok {
+		dif.configLinks[key] = link
+	}
+}
+
+// ConfigurationChanged drops the hot instances built from the configuration nodes at paths, or
+// nested in them, along with the instances depending on them as recorded by RegistryInspector.Graph,
+// so they're rebuilt from the reloaded configuration on their next use, e.g. with rotated
+// credentials. Without paths every instance built from a configuration node is dropped.
+// It returns the registration names whose hot instances were dropped.
+func (dif *diRegistry) ConfigurationChanged(paths ...string) []string {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	dropped := map[string]struct{}{}
+	for key, link := range dif.configLinks {
+		if !pathChanged(link.path, paths) {
+			continue
+		}
+
+		dif.dropHotKeyLocked(key)
+		dropped[link.name] = struct{}{}
+
+		if len(link.instanceKey) > 0 {
+			first, second, _ := strings.Cut(link.name, ";")
+			dif.dropHotKeyLocked(link.instanceKey)
+			dropped[PairTypeName(second, first)] = struct{}{}
+		}
+	}
+
+	for _, name := range sortedKeys(dropped) {
+		node, _, _ := strings.Cut(name, ";")
+		dif.dropDependentsLocked(node, dropped)
+	}
+
+	return sortedKeys(dropped)
+}
+
+// dropDependentsLocked drops the hot instances of the registrations depending on node, recursively.
+// The caller must hold the write lock.
+func (dif *diRegistry) dropDependentsLocked(node string, dropped map[string]struct{}) {
+	for edge := range dif.dependencies {
+		if edge.To != node {
+			continue
+		}
+
+		for name := range dif.registrations {
+			if _, done := dropped[name]; done || (name != edge.From && !strings.HasPrefix(name, edge.From+";")) {
+				continue
+			}
+
+			dif.dropHotInstancesLocked(name)
+			dropped[name] = struct{}{}
+			dif.dropDependentsLocked(edge.From, dropped)
+		}
+	}
+}
+
+// pathChanged reports whether the node at path is affected by a change of the nodes at changed,
+// every node being affected when changed is empty.
+func pathChanged(path string, changed []string) bool {
+	if len(changed) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(changed, func(c string) bool {
+		return len(c) == 0 || path == c || strings.HasPrefix(path, c+".") || strings.HasPrefix(c, path+".")
+	})
+}
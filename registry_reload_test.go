@@ -0,0 +1,47 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationChanged(t *testing.T) {
+	registry := NewTestRegistry(t)
+	builds := map[string]int{}
+
+	require.NoError(t, Register[storageBackendTest](func(ctx Context, opts *RegistryOpts) (storageBackendTest, error) {
+		builds["storage"]++
+		driver, err := ConfigurationLookup[string](ctx, opts)
+		return namedStorageTest(driver), err
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+		builds["service"]++
+		_, err := Create[storageBackendTest](ctx, WithRegistry(registry), WithConfigNodePath("storage.driver"))
+		return &serviceTest{}, err
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		builds["logger"]++
+		return &loggerTest{}, nil
+	}, WithRegistry(registry)))
+
+	create := func(ctx Context) storageBackendTest {
+		_, err := Create[*serviceTest](ctx, WithRegistry(registry))
+		require.NoError(t, err)
+		_, err = Create[*loggerTest](ctx, WithRegistry(registry))
+		require.NoError(t, err)
+		storage, err := Create[storageBackendTest](ctx, WithRegistry(registry), WithConfigNodePath("storage.driver"))
+		require.NoError(t, err)
+		return storage
+	}
+
+	assert.Equal(t, "s3", create(storageContext("s3")).Name())
+	assert.Empty(t, registry.ConfigurationChanged("cache"))
+
+	assert.Equal(t, []string{TypeName[*serviceTest](), TypeName[storageBackendTest]()}, registry.ConfigurationChanged("storage"))
+	assert.Equal(t, "gcs", create(storageContext("gcs")).Name())
+	assert.Equal(t, map[string]int{"storage": 2, "service": 2, "logger": 1}, builds)
+
+	assert.Len(t, registry.ConfigurationChanged(), 2)
+}
@@ -0,0 +1,67 @@
+package di
+
+import (
+	goctx "context"
+)
+
+// TenantKey identifies a tenant sharing the process, see WithTenant.
+type TenantKey string
+
+func (t TenantKey) String() string {
+	return string(t)
+}
+
+// tenantContextKey is the key a TenantKey is stored under in the inner context of a Context.
+type tenantContextKey struct{}
+
+// ContextWithTenant returns a copy of ctx resolving its creations for tenant, e.g. in the middleware
+// authenticating a request, so every dependency created with it is the one of tenant.
+func ContextWithTenant(ctx Context, tenant TenantKey) Context {
+	return ctx.WithValue(tenantContextKey{}, tenant)
+}
+
+// TenantFrom returns the tenant ctx resolves its creations for, set by ContextWithTenant or WithTenant.
+func TenantFrom(ctx goctx.Context) (TenantKey, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(TenantKey)
+	return tenant, ok && len(tenant) > 0
+}
+
+// WithTenant returns a function that binds a registration or a creation to tenant.
+//
+// On Register, the registration is conditional, only considered by the creations made for tenant,
+// falling back to the registration without tenant for the others.
+// On Create, the creation, and every creation nested in it, is made for tenant instead of the
+// tenant of the context, if any.
+//
+// The hot instances created for a tenant are kept apart from the ones of the other tenants and from
+// the ones created without tenant, so each tenant gets its own singletons, e.g. DB pools or encryption keys.
+func WithTenant(tenant TenantKey) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.Tenant = tenant
+	}
+}
+
+// resolveTenant reconciles the tenant of a creation between its options and its context, the options
+// winning, so both carry it: the context for the nested creations, the options for the hot instance keys.
+func resolveTenant(ctx Context, opts *RegistryOpts) Context {
+	if len(opts.Tenant) > 0 {
+		if tenant, _ := TenantFrom(ctx); tenant != opts.Tenant {
+			return ContextWithTenant(ctx, opts.Tenant)
+		}
+
+		return ctx
+	}
+
+	opts.Tenant, _ = TenantFrom(ctx)
+	return ctx
+}
+
+// tenantHolds reports whether the registration made with o applies to the tenant of ctx.
+func (o *RegistryOpts) tenantHolds(ctx Context) bool {
+	if len(o.Tenant) == 0 {
+		return true
+	}
+
+	tenant, _ := TenantFrom(ctx)
+	return tenant == o.Tenant
+}
@@ -0,0 +1,59 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantPoolTest struct {
+	DSN string
+}
+
+type tenantRepositoryTest struct {
+	Pool *tenantPoolTest
+}
+
+func TestWithTenant(t *testing.T) {
+	registry := NewTestRegistry(t)
+	pools := 0
+	require.NoError(t, Register[*tenantPoolTest](func(ctx Context, opts *RegistryOpts) (*tenantPoolTest, error) {
+		pools++
+		tenant, _ := TenantFrom(ctx)
+		return &tenantPoolTest{DSN: "postgres://shared/" + tenant.String()}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*tenantPoolTest](func(ctx Context, opts *RegistryOpts) (*tenantPoolTest, error) {
+		return &tenantPoolTest{DSN: "postgres://dedicated/acme"}, nil
+	}, WithRegistry(registry), WithTenant("acme")))
+	require.NoError(t, Register[*tenantRepositoryTest](func(ctx Context, opts *RegistryOpts) (*tenantRepositoryTest, error) {
+		pool, err := Create[*tenantPoolTest](ctx, WithRegistry(opts.Registry))
+		return &tenantRepositoryTest{Pool: pool}, err
+	}, WithRegistry(registry)))
+
+	acme, err := Create[*tenantRepositoryTest](ContextWithTenant(NewContext(), "acme"), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://dedicated/acme", acme.Pool.DSN, "nested creations are made for the tenant of the context")
+
+	globex, err := Create[*tenantRepositoryTest](NewContext(), WithRegistry(registry), WithTenant("globex"))
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://shared/globex", globex.Pool.DSN)
+
+	shared, err := Create[*tenantPoolTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://shared/", shared.DSN)
+
+	again, err := Create[*tenantPoolTest](ContextWithTenant(NewContext(), "acme"), WithRegistry(registry), WithTenant("globex"))
+	require.NoError(t, err)
+	assert.Same(t, globex.Pool, again, "hot instances are kept per tenant, the option winning over the context")
+	assert.Equal(t, 2, pools)
+
+	require.NoError(t, InvalidateHot[*tenantPoolTest](WithRegistry(registry), WithTenant("globex")))
+	again, err = Create[*tenantPoolTest](NewContext(), WithRegistry(registry), WithTenant("globex"))
+	require.NoError(t, err)
+	assert.NotSame(t, globex.Pool, again)
+
+	again, err = Create[*tenantPoolTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, shared, again, "invalidating a tenant keeps the instances of the others")
+}
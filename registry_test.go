@@ -1,8 +1,13 @@
 package di
 
 import (
+	"github.com/pixie-sh/errors-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type someType struct {
@@ -55,3 +60,334 @@ func TestCreateNoConfig(t *testing.T) {
 	assert.NotNil(t, noCfgInstance)
 	assert.Equal(t, "A", noCfgInstance.cfg.A)
 }
+
+func TestUnregisterAndReplace(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, Register[someType](func(context Context, opts *RegistryOpts) (someType, error) {
+		return someType{cfg: someTypeConfig{"original"}}, nil
+	}, WithRegistry(registry)))
+
+	instance, err := Create[someType](NewContext(), WithRegistry(registry))
+	assert.NoError(t, err)
+	assert.Equal(t, "original", instance.cfg.A)
+
+	assert.NoError(t, Replace[someType](func(context Context, opts *RegistryOpts) (someType, error) {
+		return someType{cfg: someTypeConfig{"replaced"}}, nil
+	}, WithRegistry(registry)))
+
+	instance, err = Create[someType](NewContext(), WithRegistry(registry))
+	assert.NoError(t, err)
+	assert.Equal(t, "replaced", instance.cfg.A)
+
+	assert.NoError(t, Unregister[someType](WithRegistry(registry)))
+	_, err = Create[someType](NewContext(), WithRegistry(registry))
+	_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+	assert.True(t, isMissing)
+
+	err = Unregister[someType](WithRegistry(registry))
+	_, isMissing = errors.Has(err, DependencyMissingErrorCode)
+	assert.True(t, isMissing)
+}
+
+func TestMerge(t *testing.T) {
+	newLibrary := func(level string) Registry {
+		library := NewRegistry()
+		assert.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+			return &loggerTest{Level: level}, nil
+		}, WithRegistry(library)))
+		return library
+	}
+
+	t.Run("merges registrations", func(t *testing.T) {
+		app := NewRegistry()
+		assert.NoError(t, app.Merge(newLibrary("INFO"), MergeConflictError))
+
+		log, err := Create[*loggerTest](NewContext(), WithRegistry(app))
+		assert.NoError(t, err)
+		assert.Equal(t, "INFO", log.Level)
+	})
+
+	t.Run("conflict policies", func(t *testing.T) {
+		app := NewRegistry()
+		assert.NoError(t, app.Merge(newLibrary("INFO"), MergeConflictError))
+		_, err := Create[*loggerTest](NewContext(), WithRegistry(app))
+		assert.NoError(t, err)
+
+		err = app.Merge(newLibrary("DEBUG"), MergeConflictError)
+		_, isConflict := errors.Has(err, RegistrationConflictErrorCode)
+		assert.True(t, isConflict)
+		assert.Contains(t, err.Error(), "di.loggerTest")
+
+		assert.NoError(t, app.Merge(newLibrary("DEBUG"), MergeConflictSkip))
+		log, err := Create[*loggerTest](NewContext(), WithRegistry(app))
+		assert.NoError(t, err)
+		assert.Equal(t, "INFO", log.Level)
+
+		assert.NoError(t, app.Merge(newLibrary("DEBUG"), MergeConflictOverride))
+		log, err = Create[*loggerTest](NewContext(), WithRegistry(app))
+		assert.NoError(t, err)
+		assert.Equal(t, "DEBUG", log.Level)
+	})
+}
+
+func TestInstanceOwnership(t *testing.T) {
+	newLibrary := func(ownership InstanceOwnership) Registry {
+		library := NewRegistry()
+		assert.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+			return &loggerTest{Level: "INFO"}, nil
+		}, WithRegistry(library), WithInstanceOwnership(ownership)))
+		return library
+	}
+
+	hasHot := func(r Registry) bool {
+		_, err := r.GetHotInstance(NewContext(), &RegistryOpts{Registry: r}, TypeName[*loggerTest]())
+		return err == nil
+	}
+
+	t.Run("resolving registry by default", func(t *testing.T) {
+		library, app := newLibrary(OwnedByResolvingRegistry), NewRegistry()
+		assert.NoError(t, app.Merge(library, MergeConflictError))
+
+		_, err := Create[*loggerTest](NewContext(), WithRegistry(app))
+		assert.NoError(t, err)
+		assert.True(t, hasHot(app))
+		assert.False(t, hasHot(library))
+	})
+
+	t.Run("registering registry shares the instance", func(t *testing.T) {
+		library, app, other := newLibrary(OwnedByRegisteringRegistry), NewRegistry(), NewRegistry()
+		assert.NoError(t, app.Merge(library, MergeConflictError))
+		assert.NoError(t, other.Merge(library, MergeConflictError))
+
+		first, err := Create[*loggerTest](NewContext(), WithRegistry(app))
+		assert.NoError(t, err)
+		second, err := Create[*loggerTest](NewContext(), WithRegistry(other))
+		assert.NoError(t, err)
+		assert.Same(t, first, second)
+		assert.True(t, hasHot(library))
+		assert.False(t, hasHot(app))
+	})
+
+	t.Run("root registry scopes dependencies resolved elsewhere", func(t *testing.T) {
+		library, app := newLibrary(OwnedByRootRegistry), NewRegistry()
+		assert.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+			_, err := Create[*loggerTest](ctx, WithRegistry(library))
+			return &serviceTest{}, err
+		}, WithRegistry(app)))
+
+		_, err := Create[*serviceTest](NewContext(), WithRegistry(app))
+		assert.NoError(t, err)
+		assert.True(t, hasHot(app))
+		assert.False(t, hasHot(library))
+	})
+}
+
+func TestConfigOf(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, RegisterPair[*databaseTest, *databaseConfigTest](
+		func(ctx Context, opts *RegistryOpts, config *databaseConfigTest) (*databaseTest, error) {
+			return &databaseTest{ConnectionString: config.ConnectionString}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (*databaseConfigTest, error) {
+			return &databaseConfigTest{ConnectionString: "mongodb://primary:27017"}, nil
+		},
+		WithRegistry(registry),
+		WithToken("primary"),
+	))
+
+	db, err := CreatePair[*databaseTest, *databaseConfigTest](NewContext(), WithRegistry(registry), WithToken("primary"))
+	assert.NoError(t, err)
+
+	pinned, ok := registry.ConfigOf(db)
+	assert.True(t, ok)
+	assert.Equal(t, &databaseConfigTest{ConnectionString: "mongodb://primary:27017"}, pinned)
+
+	assert.False(t, ConfigChanged(registry, db, &databaseConfigTest{ConnectionString: "mongodb://primary:27017"}))
+	assert.True(t, ConfigChanged(registry, db, &databaseConfigTest{ConnectionString: "mongodb://rotated:27017"}))
+
+	_, ok = registry.ConfigOf(&databaseTest{})
+	assert.False(t, ok)
+	assert.True(t, ConfigChanged(registry, &databaseTest{}, nil))
+}
+
+func TestList(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, RegisterPair[*databaseTest, databaseConfigTest](
+		func(ctx Context, opts *RegistryOpts, cfg databaseConfigTest) (*databaseTest, error) {
+			return &databaseTest{}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (databaseConfigTest, error) {
+			return databaseConfigTest{}, nil
+		},
+		WithRegistry(registry), WithToken("primary"), WithConfigNodePath("databases.primary")))
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return &loggerTest{}, nil
+	}, WithRegistry(registry), WithEager()))
+
+	infos := registry.List()
+	require.Len(t, infos, 3)
+
+	assert.Equal(t, TypeName[*loggerTest](), infos[0].Name)
+	assert.Equal(t, TypeName[*loggerTest](), infos[0].TypeName)
+	assert.Equal(t, LifetimeSingleton, infos[0].Lifetime)
+	assert.True(t, infos[0].Eager)
+	assert.False(t, infos[0].HasConfig)
+	assert.Contains(t, infos[0].CallSite, "registry_test.go:")
+
+	assert.Equal(t, TypeName[databaseConfigTest](), infos[1].TypeName)
+	assert.True(t, infos[1].Configuration)
+
+	assert.Equal(t, TypeName[*databaseTest](), infos[2].TypeName)
+	assert.Equal(t, InjectionToken("primary"), infos[2].InjectionToken)
+	assert.Equal(t, "databases.primary", infos[2].ConfigNodePath)
+	assert.True(t, infos[2].HasConfig)
+	assert.False(t, infos[2].Configuration)
+}
+
+func TestStrictToken(t *testing.T) {
+	newRegistry := func(options ...RegistryOption) *diRegistry {
+		registry := NewRegistry(options...)
+		require.NoError(t, Register[*databaseTest](func(ctx Context, opts *RegistryOpts) (*databaseTest, error) {
+			return &databaseTest{}, nil
+		}, WithRegistry(registry)))
+		return registry
+	}
+
+	t.Run("fallback", func(t *testing.T) {
+		registry := newRegistry()
+		var events []CreateEvent
+		registry.AddHook(Hook{AfterCreate: func(ctx Context, event CreateEvent) { events = append(events, event) }})
+
+		_, err := Create[*databaseTest](NewContext(), WithRegistry(registry), WithToken("replica"))
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.False(t, events[0].TokenFallback)
+		assert.True(t, events[1].TokenFallback)
+
+		report, err := Explain[*databaseTest](NewContext(), WithRegistry(registry), WithToken("replica"))
+		require.NoError(t, err)
+		assert.True(t, report.TokenFallback)
+	})
+
+	t.Run("per call", func(t *testing.T) {
+		registry := newRegistry()
+		_, err := Create[*databaseTest](NewContext(), WithRegistry(registry), WithToken("replica"), WithStrictToken())
+		require.Error(t, err)
+		_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+		assert.True(t, isMissing)
+
+		report, err := Explain[*databaseTest](NewContext(), WithRegistry(registry), WithToken("replica"), WithStrictToken())
+		require.Error(t, err)
+		assert.Empty(t, report.Selected)
+		assert.Contains(t, report.String(), "untokened fallback disabled")
+
+		_, err = Create[*databaseTest](NewContext(), WithRegistry(registry), WithStrictToken())
+		assert.NoError(t, err)
+	})
+
+	t.Run("per registry", func(t *testing.T) {
+		registry := newRegistry(WithStrictTokens())
+		_, err := Create[*databaseTest](NewContext(), WithRegistry(registry), WithToken("replica"))
+		assert.Error(t, err)
+	})
+}
+
+func TestTTL(t *testing.T) {
+	registry := NewRegistry()
+	clock := NewFrozenClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, Register[Clock](func(ctx Context, opts *RegistryOpts) (Clock, error) {
+		return clock, nil
+	}, WithRegistry(registry)))
+
+	builds := 0
+	require.NoError(t, Register[*databaseTest](func(ctx Context, opts *RegistryOpts) (*databaseTest, error) {
+		builds++
+		return &databaseTest{}, nil
+	}, WithRegistry(registry), WithTTL(time.Minute)))
+
+	first, err := Create[*databaseTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+
+	clock.Advance(30 * time.Second)
+	second, err := Create[*databaseTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	clock.Advance(30 * time.Second)
+	_, err = registry.GetHotInstance(NewContext(), nil, TypeName[*databaseTest]())
+	assert.Error(t, err)
+
+	third, err := Create[*databaseTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+	assert.Equal(t, 2, builds)
+
+	assert.Equal(t, time.Minute, registry.List()[1].TTL)
+}
+
+func TestTTL_Clock(t *testing.T) {
+	for _, withClock := range []bool{false, true} {
+		registry := NewRegistry()
+		if withClock {
+			require.NoError(t, Register[Clock](func(ctx Context, opts *RegistryOpts) (Clock, error) {
+				return NewFrozenClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), nil
+			}, WithRegistry(registry)))
+		}
+		require.NoError(t, Register[*databaseTest](func(ctx Context, opts *RegistryOpts) (*databaseTest, error) {
+			return &databaseTest{}, nil
+		}, WithRegistry(registry), WithTTL(time.Minute)))
+
+		var created []string
+		registry.AddHook(Hook{AfterCreate: func(ctx Context, event CreateEvent) {
+			created = append(created, event.TypeName)
+		}})
+
+		for range 3 {
+			_, err := Create[*databaseTest](NewContext(), WithRegistry(registry))
+			require.NoError(t, err)
+		}
+
+		clockCreations := 0
+		for _, typeName := range created {
+			if typeName == TypeName[Clock]() {
+				clockCreations++
+			}
+		}
+		if withClock {
+			assert.Equal(t, 1, clockCreations, "the registered clock is created once, then read from its hot instance")
+		} else {
+			assert.Zero(t, clockCreations, "reading the system clock is not a creation")
+		}
+		for _, edge := range registry.Graph().Edges {
+			assert.NotEqual(t, TypeName[Clock](), edge.To, "the clock is not a dependency of the instances expiring")
+		}
+	}
+}
+
+func TestConcurrentFirstCreate(t *testing.T) {
+	registry := NewRegistry()
+	var builds atomic.Int32
+	require.NoError(t, Register[*databaseTest](func(ctx Context, opts *RegistryOpts) (*databaseTest, error) {
+		builds.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return &databaseTest{}, nil
+	}, WithRegistry(registry)))
+
+	var wg sync.WaitGroup
+	instances := make([]*databaseTest, 16)
+	for i := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instance, err := Create[*databaseTest](NewContext(), WithRegistry(registry))
+			assert.NoError(t, err)
+			instances[i] = instance
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), builds.Load())
+	for _, instance := range instances {
+		assert.Same(t, instances[0], instance)
+	}
+}
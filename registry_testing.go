@@ -0,0 +1,180 @@
+package di
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TestingT is the subset of testing.TB used by the test helpers,
+// declared here so the package does not depend on the testing package.
+type TestingT interface {
+	Helper()
+	Cleanup(func())
+	Fatalf(format string, args ...any)
+}
+
+// NewTestRegistry returns an isolated registry, as NewRegistry, whose registrations and hot instances
+// are dropped when the test finishes. When inheritInstance is true, the registrations of the
+// global Instance are copied into it, hot instances are never shared.
+//
+// Use it with WithRegistry instead of resetting the global Instance by hand.
+func NewTestRegistry(t TestingT, inheritInstance ...bool) *diRegistry {
+	t.Helper()
+
+	registry := NewRegistry()
+	if len(inheritInstance) > 0 && inheritInstance[0] {
+		err := registry.Merge(DefaultRegistry(), MergeConflictOverride)
+		if err != nil {
+			t.Fatalf("di.NewTestRegistry failed to inherit Instance registrations: %s", err)
+		}
+	}
+
+	t.Cleanup(func() {
+		if err := registry.reset(); err != nil {
+			t.Fatalf("di.NewTestRegistry failed to dispose the instances of the test: %s", err)
+		}
+	})
+	return registry
+}
+
+// Override swaps the registration of T for one returning instance, and restores the original
+// registration and hot instance when the test finishes. Options select the registry and token,
+// so a single dependency deep in the graph can be stubbed:
+//
+//	di.Override[*EmailSender](t, mockSender, di.WithRegistry(registry))
+//
+// Instances already built on top of the original keep holding it, create them after overriding.
+func Override[T any](t TestingT, instance T, options ...func(*RegistryOpts)) {
+	t.Helper()
+
+	registryOpts := RegistryOpts{
+		Registry:       DefaultRegistry(),
+		InjectionToken: "",
+	}
+
+	for _, opt := range options {
+		if opt != nil {
+			opt(&registryOpts)
+		}
+	}
+
+	registry, ok := registryOpts.Registry.(*diRegistry)
+	if !ok {
+		t.Fatalf("di.Override requires a registry created with NewRegistry, got %T", registryOpts.Registry)
+		return
+	}
+
+	snapshot := registry.snapshot(TypeNameIn[T](registry, registryOpts.InjectionToken))
+	err := Replace[T](func(ctx Context, opts *RegistryOpts) (T, error) {
+		return instance, nil
+	}, WithOpts(&registryOpts))
+	if err != nil {
+		t.Fatalf("di.Override failed to replace %s: %s", snapshot.name, err)
+		return
+	}
+
+	t.Cleanup(func() {
+		registry.restore(snapshot)
+	})
+}
+
+// registrationSnapshot holds everything stored in a registry under a registration name.
+type registrationSnapshot struct {
+	name           string
+	registration   *registration
+	registeredType reflect.Type
+	hotInstances   map[string]any
+	pinnedConfigs  map[string]any
+}
+
+// snapshot captures the registration stored under name and the hot instances created from it.
+func (dif *diRegistry) snapshot(name string) registrationSnapshot {
+	dif.mu.RLock()
+	defer dif.mu.RUnlock()
+
+	snapshot := registrationSnapshot{
+		name:           name,
+		registeredType: dif.registeredTypes[name],
+		hotInstances:   map[string]any{},
+		pinnedConfigs:  map[string]any{},
+	}
+
+	if reg, ok := dif.registrations[name]; ok {
+		snapshot.registration = &reg
+	}
+
+	for key, instance := range dif.hotInstances {
+		if key == name || strings.HasSuffix(key, ":"+name) {
+			snapshot.hotInstances[key] = instance
+		}
+	}
+
+	for key, config := range dif.pinnedConfigs {
+		if key == name || strings.HasSuffix(key, ":"+name) {
+			snapshot.pinnedConfigs[key] = config
+		}
+	}
+
+	return snapshot
+}
+
+// restore puts back the registration and hot instances captured by snapshot,
+// dropping whatever was stored under the same name in the meantime.
+func (dif *diRegistry) restore(snapshot registrationSnapshot) {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	delete(dif.registrations, snapshot.name)
+	delete(dif.registeredTypes, snapshot.name)
+	dif.dropHotInstancesLocked(snapshot.name)
+
+	if snapshot.registration != nil {
+		dif.registrations[snapshot.name] = *snapshot.registration
+	}
+
+	if snapshot.registeredType != nil {
+		dif.registeredTypes[snapshot.name] = snapshot.registeredType
+	}
+
+	for key, instance := range snapshot.hotInstances {
+		dif.hotInstances[key] = instance
+	}
+
+	for key, config := range snapshot.pinnedConfigs {
+		dif.pinnedConfigs[key] = config
+	}
+}
+
+// reset stops the workers of the registry and disposes the instances it created, then drops every
+// registration, hot instance, decorator, middleware, post processor, pool and hook of the registry.
+func (dif *diRegistry) reset() error {
+	dif.workers.reset()
+	err := dif.dispose(NewContext())
+	dif.pools.Clear()
+
+	dif.hotLocksMu.Lock()
+	dif.hotLocks = map[string]*hotLock{}
+	dif.hotLocksMu.Unlock()
+
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	dif.registrations = map[string]registration{}
+	dif.configurationRegistrations = map[string]configurationRegistration{}
+	dif.hotInstances = map[string]any{}
+	dif.hotExpirations = map[string]time.Time{}
+	dif.hotTags = map[string][]string{}
+	dif.configLinks = map[string]configLink{}
+	dif.pinnedConfigs = map[string]any{}
+	dif.registeredTypes = map[string]reflect.Type{}
+	dif.dependencies = map[GraphEdge]GraphNodeKind{}
+	dif.decorators = map[string][]decorator{}
+	dif.hooks = nil
+	dif.middlewares = nil
+	dif.createChain = chainMiddlewares(nil, dif.dispatchCreate)
+	dif.postProcessors = nil
+	dif.disposables = nil
+	dif.eagerSeq = 0
+	return err
+}
@@ -0,0 +1,150 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTestRegistry(t *testing.T) {
+	var registry Registry
+
+	t.Run("isolated registry", func(t *testing.T) {
+		registry = NewTestRegistry(t)
+		require.NoError(t, Register[someType](func(context Context, opts *RegistryOpts) (someType, error) {
+			return someType{cfg: someTypeConfig{"isolated"}}, nil
+		}, WithRegistry(registry)))
+
+		instance, err := Create[someType](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "isolated", instance.cfg.A)
+	})
+
+	_, err := Create[someType](NewContext(), WithRegistry(registry))
+	_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+	assert.True(t, isMissing, "registrations should be dropped on cleanup")
+}
+
+func TestNewTestRegistry_Cleanup(t *testing.T) {
+	var (
+		registry *diRegistry
+		events   []string
+		conn     *lifecycleComponentTest
+	)
+
+	t.Run("test", func(t *testing.T) {
+		registry = NewTestRegistry(t)
+		require.NoError(t, Register[*lifecycleComponentTest](func(ctx Context, opts *RegistryOpts) (*lifecycleComponentTest, error) {
+			return &lifecycleComponentTest{name: "conn", events: &events}, nil
+		}, WithRegistry(registry)))
+		require.NoError(t, Decorate[*lifecycleComponentTest](func(ctx Context, inner *lifecycleComponentTest) (*lifecycleComponentTest, error) {
+			inner.name = "decorated"
+			return inner, nil
+		}, WithRegistry(registry)))
+		registry.AddPostProcessor(func(ctx Context, typeName string, instance any) (any, error) {
+			events = append(events, "post process")
+			return instance, nil
+		})
+
+		var err error
+		conn, err = Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+	})
+
+	assert.Equal(t, 1, conn.disposed, "instances of the test are disposed on cleanup")
+
+	events = nil
+	require.NoError(t, Register[*lifecycleComponentTest](func(ctx Context, opts *RegistryOpts) (*lifecycleComponentTest, error) {
+		return &lifecycleComponentTest{name: "conn", events: &events}, nil
+	}, WithRegistry(registry)))
+	again, err := Create[*lifecycleComponentTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "conn", again.name, "decorators are dropped on cleanup")
+	assert.Equal(t, []string{"init conn"}, events, "post processors are dropped on cleanup")
+	require.NoError(t, registry.Shutdown(NewContext()))
+}
+
+func TestNewTestRegistry_InheritInstance(t *testing.T) {
+	useDefaultRegistryTest(t, NewRegistry())
+	calls := 0
+	require.NoError(t, Register[someType](func(context Context, opts *RegistryOpts) (someType, error) {
+		calls++
+		return someType{cfg: someTypeConfig{"global"}}, nil
+	}))
+
+	_, err := Create[someType](NewContext())
+	require.NoError(t, err)
+
+	registry := NewTestRegistry(t, true)
+	instance, err := Create[someType](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "global", instance.cfg.A)
+	assert.Equal(t, 2, calls, "hot instances must not be shared with the global Instance")
+
+	require.NoError(t, Replace[someType](func(context Context, opts *RegistryOpts) (someType, error) {
+		return someType{cfg: someTypeConfig{"replaced"}}, nil
+	}, WithRegistry(registry)))
+
+	instance, err = Create[someType](NewContext())
+	require.NoError(t, err)
+	assert.Equal(t, "global", instance.cfg.A, "global Instance must be left untouched")
+}
+
+type emailSenderTest struct {
+	from string
+}
+
+type notifierTest struct {
+	sender *emailSenderTest
+}
+
+func TestOverride(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, Register[*emailSenderTest](func(ctx Context, opts *RegistryOpts) (*emailSenderTest, error) {
+		return &emailSenderTest{from: "real"}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*notifierTest](func(ctx Context, opts *RegistryOpts) (*notifierTest, error) {
+		sender, err := Create[*emailSenderTest](ctx, WithRegistry(registry))
+		if err != nil {
+			return nil, err
+		}
+
+		return &notifierTest{sender: sender}, nil
+	}, WithRegistry(registry)))
+
+	real, err := Create[*emailSenderTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+
+	t.Run("overridden", func(t *testing.T) {
+		Override[*emailSenderTest](t, &emailSenderTest{from: "mock"}, WithRegistry(registry))
+
+		notifier, err := Create[*notifierTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "mock", notifier.sender.from)
+	})
+
+	restored, err := Create[*emailSenderTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Same(t, real, restored)
+}
+
+func TestOverride_TypeNamer(t *testing.T) {
+	registry := NewRegistry(WithQualifiedTypeNames())
+	require.NoError(t, Register[*emailSenderTest](func(ctx Context, opts *RegistryOpts) (*emailSenderTest, error) {
+		return &emailSenderTest{from: "real"}, nil
+	}, WithRegistry(registry)))
+
+	t.Run("overridden", func(t *testing.T) {
+		Override[*emailSenderTest](t, &emailSenderTest{from: "mock"}, WithRegistry(registry))
+
+		sender, err := Create[*emailSenderTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		assert.Equal(t, "mock", sender.from)
+	})
+
+	restored, err := Create[*emailSenderTest](NewContext(), WithRegistry(registry))
+	require.NoError(t, err)
+	assert.Equal(t, "real", restored.from, "the override must not outlive the test under the names of the registry")
+}
@@ -0,0 +1,140 @@
+package di
+
+import (
+	goctx "context"
+	goerrors "errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// WithCreateTimeout returns a function that bounds the time a creation may take, along with every
+// dependency it creates. Once d elapses the creation fails with CreateTimeoutErrorCode, naming the
+// factory executing at the time, while the creations still running see their context done and stop
+// at their next dependency. Factories ignoring their context keep running in the background.
+func WithCreateTimeout(d time.Duration) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.CreateTimeout = d
+	}
+}
+
+// createTimeout tracks the factories executing under the deadline of a creation.
+type createTimeout struct {
+	mu        sync.Mutex
+	executing []string
+}
+
+// enter marks the factory of typeName as executing until the returned function is called.
+func (t *createTimeout) enter(typeName string) func() {
+	t.mu.Lock()
+	t.executing = append(t.executing, typeName)
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		if i := slices.Index(t.executing, typeName); i >= 0 {
+			t.executing = slices.Delete(t.executing, i, i+1)
+		}
+	}
+}
+
+// snapshot returns the factories executing, from the outermost to the innermost.
+func (t *createTimeout) snapshot() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.executing)
+}
+
+// enterFactory marks the factory of typeName as executing for the creations made under WithCreateTimeout.
+func enterFactory(ctx Context, typeName string) func() {
+	diCtx, ok := ctx.(*context)
+	if !ok || diCtx.timeout == nil {
+		return func() {}
+	}
+
+	return diCtx.timeout.enter(typeName)
+}
+
+type createResult[T any] struct {
+	value    T
+	err      error
+	panicked any
+}
+
+// timeoutApplies reports whether a creation in ctx runs under the WithCreateTimeout of opts, the
+// callers only building the create function of createWithTimeout then, since it escapes.
+func timeoutApplies(ctx Context, opts *RegistryOpts) bool {
+	diCtx, ok := ctx.(*context)
+	return ok && opts.CreateTimeout > 0 && diCtx.timeout == nil
+}
+
+// createWithTimeout runs create under the WithCreateTimeout of opts, if any and not already under one.
+func createWithTimeout[T any](ctx Context, opts *RegistryOpts, typeName string, create func(ctx Context, opts *RegistryOpts) (T, error)) (T, error) {
+	if !timeoutApplies(ctx, opts) {
+		return create(ctx, opts)
+	}
+
+	diCtx := ctx.(*context)
+	inner, cancel := goctx.WithTimeout(ctx.Inner(), opts.CreateTimeout)
+	defer cancel()
+
+	timedCtx := diCtx.WithInner(inner).(*context)
+	timedCtx.timeout = &createTimeout{}
+
+	done := make(chan createResult[T], 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- createResult[T]{panicked: r}
+			}
+		}()
+
+		value, err := create(timedCtx, opts)
+		done <- createResult[T]{value: value, err: err}
+	}()
+
+	var zero T
+	select {
+	case result := <-done:
+		if result.panicked != nil {
+			panic(result.panicked)
+		}
+
+		if result.err != nil && goerrors.Is(inner.Err(), goctx.DeadlineExceeded) && goerrors.Is(result.err, goctx.DeadlineExceeded) {
+			return zero, newCreateTimeoutError(typeName, opts.CreateTimeout, timedCtx.timeout.snapshot(), result.err)
+		}
+
+		return result.value, result.err
+	case <-inner.Done():
+		if goerrors.Is(inner.Err(), goctx.DeadlineExceeded) {
+			return zero, newCreateTimeoutError(typeName, opts.CreateTimeout, timedCtx.timeout.snapshot(), inner.Err())
+		}
+
+		return zero, errors.Wrap(inner.Err(), "creation of %s aborted, context done", typeName, ResolutionCancelledErrorCode)
+	}
+}
+
+// newCreateTimeoutError returns the error of the creation of typeName timing out while the factories
+// executing were running.
+func newCreateTimeoutError(typeName string, timeout time.Duration, executing []string, cause error) error {
+	factory := typeName
+	if len(executing) > 0 {
+		factory = executing[len(executing)-1]
+	}
+
+	return errors.Wrap(
+		cause,
+		"creation of %s timed out after %s while the factory of %s was executing, chain: %s",
+		typeName,
+		timeout,
+		factory,
+		strings.Join(executing, " -> "),
+		CreateTimeoutErrorCode,
+	)
+}
@@ -0,0 +1,92 @@
+package di
+
+import (
+	goctx "context"
+	goerrors "errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutServiceTest struct{}
+
+type timeoutSlowTest struct{}
+
+type timeoutLeafTest struct{}
+
+func TestCreateTimeout(t *testing.T) {
+	registry := NewTestRegistry(t)
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	require.NoError(t, Register[timeoutSlowTest](func(ctx Context, opts *RegistryOpts) (timeoutSlowTest, error) {
+		<-release
+		return timeoutSlowTest{}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[timeoutServiceTest](func(ctx Context, opts *RegistryOpts) (timeoutServiceTest, error) {
+		_, err := Create[timeoutSlowTest](ctx, WithRegistry(opts.Registry))
+		return timeoutServiceTest{}, err
+	}, WithRegistry(registry)))
+
+	start := time.Now()
+	_, err := Create[timeoutServiceTest](NewContext(), WithRegistry(registry), WithCreateTimeout(20*time.Millisecond))
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	_, timedOut := errors.Has(err, CreateTimeoutErrorCode)
+	assert.True(t, timedOut)
+	assert.True(t, goerrors.Is(err, goctx.DeadlineExceeded))
+	assert.ErrorContains(t, err, "creation of di.timeoutServiceTest timed out after 20ms while the factory of di.timeoutSlowTest was executing")
+	assert.ErrorContains(t, err, "chain: di.timeoutServiceTest -> di.timeoutSlowTest")
+
+	t.Run("hops", func(t *testing.T) {
+		require.NoError(t, Register[timeoutLeafTest](func(ctx Context, opts *RegistryOpts) (timeoutLeafTest, error) {
+			time.Sleep(30 * time.Millisecond)
+			_, err := Create[SimpleConfig](ctx, WithRegistry(opts.Registry))
+			return timeoutLeafTest{}, err
+		}, WithRegistry(registry)))
+
+		_, err := Create[timeoutLeafTest](NewContext(), WithRegistry(registry), WithCreateTimeout(10*time.Millisecond))
+		_, timedOut := errors.Has(err, CreateTimeoutErrorCode)
+		assert.True(t, timedOut)
+	})
+
+	t.Run("abandoned flight", func(t *testing.T) {
+		var calls atomic.Int32
+		require.NoError(t, Register[*timeoutLeafTest](func(ctx Context, opts *RegistryOpts) (*timeoutLeafTest, error) {
+			if calls.Add(1) == 1 {
+				<-release
+			}
+
+			return &timeoutLeafTest{}, nil
+		}, WithRegistry(registry)))
+
+		_, err := Create[*timeoutLeafTest](NewContext(), WithRegistry(registry), WithCreateTimeout(10*time.Millisecond))
+		_, timedOut := errors.Has(err, CreateTimeoutErrorCode)
+		require.True(t, timedOut)
+
+		created := make(chan error, 1)
+		go func() {
+			_, err := Create[*timeoutLeafTest](NewContext(), WithRegistry(registry))
+			created <- err
+		}()
+
+		select {
+		case err := <-created:
+			assert.NoError(t, err)
+			assert.Equal(t, int32(2), calls.Load(), "the factory runs again instead of waiting for the abandoned one")
+		case <-time.After(time.Second):
+			t.Fatal("creation blocked behind the abandoned factory")
+		}
+	})
+
+	t.Run("in time", func(t *testing.T) {
+		_, err := CreateConfiguration[SimpleConfig](NewContext(), WithRegistry(registry), WithCreateTimeout(time.Second))
+		_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+		assert.True(t, isMissing, "errors in time are returned as is")
+	})
+}
@@ -0,0 +1,130 @@
+package di
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithTypeKeys makes the registry key its registrations by the full import path of their types,
+// see TypeKey, so two packages named alike, e.g. github.com/acme/billing/config and
+// github.com/acme/auth/config, stop overwriting each other's config.Config registrations.
+//
+// Deprecated: it's WithQualifiedTypeNames, use it instead.
+func WithTypeKeys() RegistryOption {
+	return WithQualifiedTypeNames()
+}
+
+// TypeKey returns the registration name of T in a registry made WithTypeKeys, prefixed by the injection
+// token when provided. Types declared with the same name in two functions of a package render alike,
+// register them under distinct tokens.
+//
+// Deprecated: it's QualifiedTypeName, use it instead.
+func TypeKey[T any](tokens ...InjectionToken) string {
+	return QualifiedTypeName[T](tokens...)
+}
+
+// qualifiedTypeName renders t like reflect does, naming every named type with its full import path.
+func qualifiedTypeName(t reflect.Type) string {
+	if len(t.Name()) > 0 {
+		if len(t.PkgPath()) == 0 {
+			return t.Name()
+		}
+
+		return t.PkgPath() + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + qualifiedTypeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + qualifiedTypeName(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + qualifiedTypeName(t.Elem())
+	case reflect.Map:
+		return "map[" + qualifiedTypeName(t.Key()) + "]" + qualifiedTypeName(t.Elem())
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + qualifiedTypeName(t.Elem())
+		case reflect.SendDir:
+			return "chan<- " + qualifiedTypeName(t.Elem())
+		default:
+			return "chan " + qualifiedTypeName(t.Elem())
+		}
+	case reflect.Func:
+		return "func" + qualifiedSignature(t)
+	case reflect.Struct:
+		fields := make([]string, 0, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := field.Name
+			if !field.IsExported() {
+				name = field.PkgPath + "." + name
+			}
+
+			rendered := qualifiedTypeName(field.Type)
+			if !field.Anonymous {
+				rendered = name + " " + rendered
+			}
+			if len(field.Tag) > 0 {
+				rendered += " " + strconv.Quote(string(field.Tag))
+			}
+
+			fields = append(fields, rendered)
+		}
+
+		if len(fields) == 0 {
+			return "struct {}"
+		}
+
+		return "struct { " + strings.Join(fields, "; ") + " }"
+	case reflect.Interface:
+		methods := make([]string, 0, t.NumMethod())
+		for i := 0; i < t.NumMethod(); i++ {
+			method := t.Method(i)
+			name := method.Name
+			if len(method.PkgPath) > 0 {
+				name = method.PkgPath + "." + name
+			}
+
+			methods = append(methods, name+qualifiedSignature(method.Type))
+		}
+
+		if len(methods) == 0 {
+			return "interface {}"
+		}
+
+		return "interface { " + strings.Join(methods, "; ") + " }"
+	default:
+		return t.String()
+	}
+}
+
+// qualifiedSignature renders the parameters and results of the func type t with qualifiedTypeName.
+func qualifiedSignature(t reflect.Type) string {
+	in := make([]string, 0, t.NumIn())
+	for i := 0; i < t.NumIn(); i++ {
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			in = append(in, "..."+qualifiedTypeName(t.In(i).Elem()))
+			continue
+		}
+
+		in = append(in, qualifiedTypeName(t.In(i)))
+	}
+
+	out := make([]string, 0, t.NumOut())
+	for i := 0; i < t.NumOut(); i++ {
+		out = append(out, qualifiedTypeName(t.Out(i)))
+	}
+
+	signature := "(" + strings.Join(in, ", ") + ")"
+	switch len(out) {
+	case 0:
+		return signature
+	case 1:
+		return signature + " " + out[0]
+	default:
+		return signature + " (" + strings.Join(out, ", ") + ")"
+	}
+}
@@ -0,0 +1,38 @@
+package di
+
+import (
+	"math/rand"
+	randv2 "math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typeKeyTest struct{}
+
+func TestWithTypeKeys(t *testing.T) {
+	registry := NewRegistry(WithTypeKeys())
+
+	require.NoError(t, Register[*rand.Rand](func(ctx Context, opts *RegistryOpts) (*rand.Rand, error) {
+		return rand.New(rand.NewSource(1)), nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*randv2.Rand](func(ctx Context, opts *RegistryOpts) (*randv2.Rand, error) {
+		return randv2.New(randv2.NewPCG(1, 2)), nil
+	}, WithRegistry(registry)))
+
+	assert.NotEqual(t, TypeKey[rand.Rand](), TypeKey[randv2.Rand]())
+	assert.Equal(t, QualifiedTypeName[rand.Rand](), TypeNameIn[rand.Rand](registry), "keys are qualified names")
+	assert.True(t, registry.IsRegistered(TypeKey[rand.Rand]()))
+	assert.True(t, registry.IsRegistered(TypeKey[randv2.Rand]()))
+	assert.False(t, registry.IsRegistered(TypeName[rand.Rand]()), "ambiguous TypeName")
+}
+
+func TestTypeKey(t *testing.T) {
+	assert.Equal(t, "github.com/pixie-sh/di-go.typeKeyTest", TypeKey[typeKeyTest]())
+	assert.Equal(t, TypeKey[typeKeyTest](), TypeKey[*typeKeyTest]())
+	assert.Equal(t, "ro:github.com/pixie-sh/di-go.typeKeyTest", TypeKey[*typeKeyTest]("ro"))
+	assert.Equal(t, "map[string][]*github.com/pixie-sh/di-go.typeKeyTest", TypeKey[map[string][]*typeKeyTest]())
+	assert.Equal(t, "func(github.com/pixie-sh/di-go.Context, ...string) (*github.com/pixie-sh/di-go.typeKeyTest, error)", TypeKey[func(Context, ...string) (*typeKeyTest, error)]())
+	assert.Equal(t, "github.com/pixie-sh/di-go.cachedNameRepoTest[github.com/pixie-sh/di-go.typeKeyTest]", TypeKey[cachedNameRepoTest[typeKeyTest]]())
+}
@@ -1,8 +1,12 @@
 package di
 
 import (
-	"fmt"
+	"maps"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pixie-sh/errors-go"
 )
@@ -30,46 +34,172 @@ func RegisterInjectionToken(tkn string) InjectionToken {
 		errors.Must(errors.New("injection token %s already registered", tkn))
 	}
 
+	errors.Must(validateInjectionToken(tkn))
+
+	injectionTokenMap[InjectionToken(tkn)] = struct{}{}
+	return InjectionToken(tkn)
+}
+
+// RegisterInjectionTokens registers a batch of tokens, returned in order, with the rules of RegisterInjectionToken.
+// Every token is validated before any is registered, so on an invalid or duplicate token it panics
+// leaving none of the batch registered:
+//
+//	var tokens = di.RegisterInjectionTokens("db.primary", "db.replica")
+func RegisterInjectionTokens(tkns ...string) []InjectionToken {
+	tokens := make([]InjectionToken, 0, len(tkns))
+	batch := map[InjectionToken]struct{}{}
+	for _, tkn := range tkns {
+		_, existing := injectionTokenMap[InjectionToken(tkn)]
+		_, repeated := batch[InjectionToken(tkn)]
+		if existing || repeated {
+			errors.Must(errors.New("injection token %s already registered", tkn))
+		}
+
+		errors.Must(validateInjectionToken(tkn))
+
+		batch[InjectionToken(tkn)] = struct{}{}
+		tokens = append(tokens, InjectionToken(tkn))
+	}
+
+	for _, token := range tokens {
+		injectionTokenMap[token] = struct{}{}
+	}
+
+	return tokens
+}
+
+// validateInjectionToken checks tkn against the rules documented in RegisterInjectionToken.
+func validateInjectionToken(tkn string) error {
 	if tkn == "" {
-		errors.Must(errors.New("injection token cannot be empty"))
+		return errors.New("injection token cannot be empty")
 	}
 
 	for i, r := range tkn {
 		if r == '.' {
 			if i == 0 || i == len(tkn)-1 {
-				errors.Must(errors.New("injection token %s cannot start or end with a dot", tkn))
+				return errors.New("injection token %s cannot start or end with a dot", tkn)
 			}
 
 			if tkn[i-1] == '.' {
-				errors.Must(errors.New("injection token %s cannot contain consecutive dots", tkn))
+				return errors.New("injection token %s cannot contain consecutive dots", tkn)
 			}
 		}
 	}
 
-	injectionTokenMap[InjectionToken(tkn)] = struct{}{}
+	return nil
+}
+
+// Child returns the token nested under t, joining both with a dot: db.Child("replica") is "db.replica".
+// The resulting token is validated with the RegisterInjectionToken rules, panicking when invalid,
+// but it's not registered. The child of an empty token is the child itself.
+func (t InjectionToken) Child(child string) InjectionToken {
+	tkn := child
+	if len(t) > 0 {
+		tkn = t.String() + injectionTokenSeparator + child
+	}
+
+	errors.Must(validateInjectionToken(tkn))
 	return InjectionToken(tkn)
 }
 
+// Parent returns the token t is nested under: "db.replica" has "db" as parent.
+// A token without dots has the empty token as parent.
+func (t InjectionToken) Parent() InjectionToken {
+	i := strings.LastIndex(t.String(), injectionTokenSeparator)
+	if i < 0 {
+		return ""
+	}
+
+	return t[:i]
+}
+
+// TypeName returns the registration name of T, prefixed by the injection token when provided.
+// Pointers are dereferenced once, so T and *T share a name. Names are rendered by reflect:
+// the outer type uses its short package name while type arguments of instantiated generics
+// use their full import path, giving each instantiation its own stable name:
+//
+//	TypeName[Repo[User]]()       // "repo.Repo[github.com/acme/app/users.User]"
+//	TypeName[*Repo[*User]]("ro") // "ro:repo.Repo[*github.com/acme/app/users.User]"
 func TypeName[T any](tokens ...InjectionToken) string {
-	var typeName string
-	var t *T
+	names := typeNamesOf(reflect.TypeOf((*T)(nil)).Elem())
+	if len(tokens) > 0 && len(tokens[0]) > 0 {
+		return names.tokened(tokens[0])
+	}
+
+	return names.name
+}
+
+// maxCachedTokens bounds the tokened names cached per type, in case tokens are built at runtime.
+const maxCachedTokens = 64
 
-	typeOfT := reflect.TypeOf(t).Elem()
-	if typeOfT.Kind() == reflect.Ptr {
-		typeName = typeOfT.Elem().String()
-	} else {
-		typeName = typeOfT.String()
+// typeNameCache caches the TypeName of every reflect.Type, rendered once since TypeName is called
+// several times per resolution, by Create and by the registry.
+var typeNameCache sync.Map
+
+// typeNames holds the untokened TypeName of a type and its tokened names, copied on write.
+type typeNames struct {
+	name   string
+	tokens atomic.Pointer[map[InjectionToken]string]
+}
+
+// tokened returns the TypeName of the type with token.
+func (n *typeNames) tokened(token InjectionToken) string {
+	tokens := n.tokens.Load()
+	if tokens != nil {
+		if name, ok := (*tokens)[token]; ok {
+			return name
+		}
 	}
 
-	if len(tokens) > 0 && len(tokens[0]) > 0 {
-		return fmt.Sprintf("%s:%s", tokens[0], typeName)
+	name := token.String() + ":" + n.name
+	if tokens == nil || len(*tokens) < maxCachedTokens {
+		updated := make(map[InjectionToken]string, 1)
+		if tokens != nil {
+			updated = maps.Clone(*tokens)
+		}
+		updated[token] = name
+
+		// losing the race only costs rendering the name again
+		n.tokens.CompareAndSwap(tokens, &updated)
+	}
+
+	return name
+}
+
+// typeNamesOf returns the cached names of t.
+func typeNamesOf(t reflect.Type) *typeNames {
+	if cached, ok := typeNameCache.Load(t); ok {
+		return cached.(*typeNames)
+	}
+
+	names := &typeNames{name: t.String()}
+	if t.Kind() == reflect.Ptr {
+		names.name = t.Elem().String()
 	}
 
-	return typeName
+	cached, _ := typeNameCache.LoadOrStore(t, names)
+	return cached.(*typeNames)
+}
+
+// typeNameOf returns the untokened TypeName of t.
+func typeNameOf(t reflect.Type) string {
+	return typeNamesOf(t).name
+}
+
+// splitTokenedName splits a registration name built by TypeName into its token and type name.
+// The token is only recognized when the text before the first colon cannot be part of a type
+// name, since struct tags in type arguments may contain colons.
+func splitTokenedName(name string) (InjectionToken, string, bool) {
+	token, typeName, found := strings.Cut(name, ":")
+	if !found || len(token) == 0 || strings.ContainsAny(token, "[]{}()*\" \t") {
+		return "", name, false
+	}
+
+	return InjectionToken(token), typeName, true
 }
 
 func PairTypeName(first, second string) string {
-	return fmt.Sprintf("%s;%s", first, second)
+	return first + ";" + second
 }
 
 // RegistryOpts defines the configuration options for dependency injection registry operations.
@@ -82,6 +212,37 @@ type RegistryOpts struct {
 	InjectionToken InjectionToken // Optional token to identify specific type registrations
 	ConfigNodePath string         // Path to configuration node in structured config
 	ConfigNode     Configuration  // Configuration struct that's going to be returned if set whenever CreateConfiguration is called
+
+	InterfaceUpcast    bool                   // When creating an interface type without registration, resolve the unique registered implementation
+	OptionalConfigNode bool                   // Registered configuration resolves to its zero value when the config node is absent
+	Condition          func(ctx Context) bool // Registration is only considered when the predicate holds at creation
+	Profiles           []string               // Registration is only considered while one of the profiles is active
+	Eager              bool                   // Registration is created by RegistryLifecycle.WarmUp instead of on first use
+	WarmupPriority     int                    // Eager registrations with higher priority are warmed up first
+	TTL                time.Duration          // Hot instances expire and are created again once older than TTL
+	Ownership          InstanceOwnership      // Registry keeping the hot instances when resolutions cross registries
+	CreateTimeout      time.Duration          // Creations fail once they take longer, along with their dependencies
+	Tenant             TenantKey              // Registration is only considered, or creation made, for the tenant
+	Args               map[string]any         // Runtime arguments handed to the factory of a creation, see WithArgs
+
+	ConfigDefaults   any  // Defaults the configuration node looked up is merged over, see DefaultsProvider
+	ConvertibleTypes bool // Created instances of compatible types are converted to the requested type, see SafeConvertTypeAssert
+	StrictToken      bool // Tokened creations fail instead of falling back to the untokened registration
+
+	callSite   string   // file:line of the registration, set by the registration functions
+	tags       []string // tags given by the provider to the instance it creates, see Tag
+	configPath string   // configuration node path looked up by ConfigurationLookup for the instance created
+
+	fallback       CreateConfigurationHandler // provider used when the registered one fails, see WithFallback
+	configOverride any                        // configuration fed to the pair factory, see WithConfigOverride
+	freshInstance  bool                       // the creation skips the hot instance, see WithFreshInstance
+	key            string                     // runtime key of the instance created, see CreateKeyed
+	poolSize       int                        // instances of the registration pooled for Acquire, see WithPool
+	poolSizePath   string                     // configuration node holding the pool size, see WithPoolSizeAt
+	memoize        bool                       // the resolution tree reuses its instances, see WithResolutionMemo
+	disposable     Disposable                 // instance created, recorded for disposal once its creation succeeded
+	servedHot      bool                       // the creation returned an existing hot instance, reported to hooks
+	viewed         bool                       // the registry was set by WithRegistryView, registrations are rejected
 }
 
 // WithOpts returns a function that replaces all registry options with the provided options.
@@ -97,6 +258,7 @@ func WithOpts(opt *RegistryOpts) func(opts *RegistryOpts) {
 func WithRegistry(instance Registry) func(opts *RegistryOpts) {
 	return func(opts *RegistryOpts) {
 		opts.Registry = instance
+		opts.viewed = false
 	}
 }
 
@@ -126,6 +288,142 @@ func WithConfigNodePath(path string, isAbsolutePath ...bool) func(opts *Registry
 	}
 }
 
+// WithInterfaceUpcast returns a function that enables interface upcasting in the options.
+// When the requested type is an interface with no registration of its own, the registry is
+// scanned for a unique registration or hot instance implementing it, failing on ambiguity.
+func WithInterfaceUpcast() func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.InterfaceUpcast = true
+	}
+}
+
+// WithOptionalConfigNode returns a function that marks a configuration registration as optional.
+// When the configuration node it would look up is absent, the zero value is returned instead of an error.
+func WithOptionalConfigNode() func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.OptionalConfigNode = true
+	}
+}
+
+// WithCondition returns a function that sets a registration condition in the options.
+// Conditional registrations under the same type are evaluated in registration order at creation,
+// the first one holding is used, falling back to the registration made without condition.
+// When none applies, the type is reported missing, so Create falls through to the untokened type.
+//
+// Conditional registrations share the hot instance of their type, the first one created is kept.
+func WithCondition(condition func(ctx Context) bool) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.Condition = condition
+	}
+}
+
+// WithProfiles returns a function that restricts a registration to the given profiles.
+// The registration is conditional, only considered while one of the profiles is active,
+// see SetActiveProfiles. When combined with WithCondition, both must hold.
+func WithProfiles(profiles ...string) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.Profiles = append(opts.Profiles, profiles...)
+	}
+}
+
+// WithEager returns a function that marks a registration as eager.
+// Eager registrations are created by RegistryLifecycle.WarmUp, usually at startup, surfacing
+// misconfiguration at boot instead of on first use. Configuration registrations aren't warmed up.
+func WithEager() func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.Eager = true
+	}
+}
+
+// WithWarmupPriority returns a function that marks a registration as eager with the given priority.
+// WarmUp creates higher priorities first, so latency critical components such as pools and caches
+// are ready before nice-to-have ones. Eager registrations default to priority 0.
+func WithWarmupPriority(priority int) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.Eager = true
+		opts.WarmupPriority = priority
+	}
+}
+
+// WithTTL returns a function that makes the hot instances of a registration expire after ttl.
+// Once expired, the next creation runs the factory again, e.g. for short-lived credentials.
+// Expiration is measured with the Clock of the registry, see ClockFor.
+func WithTTL(ttl time.Duration) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.TTL = ttl
+	}
+}
+
+// WithConvertibleTypes returns a function that lets creations convert instances of compatible types.
+// Providers returning a named type for its underlying one (type MyInt int for int), or a value whose
+// pointer satisfies the requested interface, are converted instead of failing the type assertion.
+func WithConvertibleTypes() func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.ConvertibleTypes = true
+	}
+}
+
+// WithStrictToken returns a function that disables the untokened fallback of the creation.
+// By default a creation with a token missing its tokened registration silently resolves the
+// untokened one, with it the creation fails instead. See WithStrictTokens for a whole registry.
+func WithStrictToken() func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.StrictToken = true
+	}
+}
+
+// InstanceOwnership selects the registry keeping the hot instances of a registration when
+// resolutions cross registries, e.g. after RegistryComposer.Merge or when the resolution tree started
+// with another registry than the one a dependency is created with.
+type InstanceOwnership int
+
+const (
+	// OwnedByResolvingRegistry keeps the hot instances in the registry the creation is made with, the default.
+	OwnedByResolvingRegistry InstanceOwnership = iota
+	// OwnedByRegisteringRegistry keeps the hot instances in the registry the type was registered with,
+	// shared by every registry merging the registration.
+	OwnedByRegisteringRegistry
+	// OwnedByRootRegistry keeps the hot instances in the registry the resolution tree started with,
+	// so dependencies resolved through other registries are still scoped to it.
+	OwnedByRootRegistry
+)
+
+// WithInstanceOwnership returns a function that selects the registry keeping the hot instances
+// of the registration, see InstanceOwnership.
+func WithInstanceOwnership(ownership InstanceOwnership) func(opts *RegistryOpts) {
+	return func(opts *RegistryOpts) {
+		opts.Ownership = ownership
+	}
+}
+
+// Tag tags the instance being created, called by its provider with the opts it's handed:
+//
+//	opts.Tag("uses-db-primary")
+//
+// Once the instance is kept as hot instance, HotInstanceEvictor.EvictByTag drops it along with every
+// other instance sharing the tag, e.g. to rebuild everything talking to a failed over database.
+func (o *RegistryOpts) Tag(tags ...string) {
+	o.tags = append(o.tags, tags...)
+}
+
+// isConditional reports whether the registration made with o only applies under conditions.
+func (o *RegistryOpts) isConditional() bool {
+	return o != nil && (o.Condition != nil || len(o.Profiles) > 0 || len(o.Tenant) > 0)
+}
+
+// conditionHolds reports whether the registration made with o applies to ctx.
+func (o *RegistryOpts) conditionHolds(ctx Context) bool {
+	if len(o.Profiles) > 0 && !anyProfileActive(o.Profiles) {
+		return false
+	}
+
+	if !o.tenantHolds(ctx) {
+		return false
+	}
+
+	return o.Condition == nil || o.Condition(ctx)
+}
+
 // WithConfigNode returns a function that sets the configuration node path in the options.
 // This allows specifying which configuration path should be used for dependency management.
 func WithConfigNode(configNode Configuration) func(opts *RegistryOpts) {
@@ -153,6 +451,8 @@ type TypedCreateInstanceNoConfigHandler[T any] func(Context, *RegistryOpts) (T,
 //  1. Direct type assertion from unknownInstance to T
 //  2. If source is a pointer but target isn't, attempts to dereference and convert
 //  3. If target is a pointer but source isn't, attempts to create pointer and convert
+//
+// See SafeConvertTypeAssert to convert compatible types as well.
 func SafeTypeAssert[T any](unknownInstance any) (T, bool) {
 	var typedInstance T
 
@@ -197,3 +497,68 @@ func SafeTypeAssert[T any](unknownInstance any) (T, bool) {
 
 	return typedInstance, false
 }
+
+// SafeConvertTypeAssert is SafeTypeAssert converting compatible types when the assertion fails:
+// named types of the same kind sharing their underlying type (type MyInt int and int) are converted,
+// and values whose pointer satisfies an interface T are assigned through a pointer to a copy.
+func SafeConvertTypeAssert[T any](unknownInstance any) (T, bool) {
+	typedInstance, ok := SafeTypeAssert[T](unknownInstance)
+	if ok || unknownInstance == nil {
+		return typedInstance, ok
+	}
+
+	return convertTypeAssert[T](reflect.ValueOf(unknownInstance), reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// typeAssertFor asserts unknownInstance to T as the creations made with opts do, with
+// SafeConvertTypeAssert when they convert types, see WithConvertibleTypes, SafeTypeAssert otherwise.
+func typeAssertFor[T any](unknownInstance any, opts *RegistryOpts) (T, bool) {
+	if opts != nil && opts.ConvertibleTypes {
+		return SafeConvertTypeAssert[T](unknownInstance)
+	}
+
+	return SafeTypeAssert[T](unknownInstance)
+}
+
+// convertTypeAssert converts value to targetType, see SafeConvertTypeAssert. Kinds must match so
+// conversions changing the value, like int to string or float to int, are never made.
+func convertTypeAssert[T any](value reflect.Value, targetType reflect.Type) (T, bool) {
+	var typedInstance T
+
+	if targetType.Kind() == reflect.Interface {
+		if value.Kind() != reflect.Ptr && reflect.PointerTo(value.Type()).Implements(targetType) {
+			ptrValue := reflect.New(value.Type())
+			ptrValue.Elem().Set(value)
+			typedInstance, ok := ptrValue.Interface().(T)
+			return typedInstance, ok
+		}
+
+		return typedInstance, false
+	}
+
+	// *X to Y and X to *Y convert between X and Y
+	wrapPointer := false
+	if value.Kind() == reflect.Ptr && targetType.Kind() != reflect.Ptr {
+		if value.IsNil() {
+			return typedInstance, false
+		}
+
+		value = value.Elem()
+	} else if targetType.Kind() == reflect.Ptr && value.Kind() != reflect.Ptr {
+		targetType, wrapPointer = targetType.Elem(), true
+	}
+
+	if value.Kind() != targetType.Kind() || !value.Type().ConvertibleTo(targetType) {
+		return typedInstance, false
+	}
+
+	converted := value.Convert(targetType)
+	if wrapPointer {
+		ptrValue := reflect.New(targetType)
+		ptrValue.Elem().Set(converted)
+		converted = ptrValue
+	}
+
+	typedInstance, ok := converted.Interface().(T)
+	return typedInstance, ok
+}
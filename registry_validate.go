@@ -0,0 +1,148 @@
+package di
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// Validate checks the wiring of the registry against ctx without constructing any instance, reporting
+// every problem at once. Factories may have side effects so they aren't run, Validate reports:
+//   - configuration registrations failing to resolve, run against a scratch copy of the registry
+//     so no configuration is cached;
+//   - registrations whose conditions don't hold for ctx, leaving the type without a creator;
+//   - dependencies the factories resolved so far, as recorded for Graph, no longer registered.
+//
+// Use WarmUp to construct the eager registrations once Validate passes.
+func (dif *diRegistry) Validate(ctx Context) error {
+	scratch := dif.scratchRegistry()
+	err := scratch.Merge(dif, MergeConflictOverride)
+	if err != nil {
+		return err
+	}
+
+	scratch.mu.RLock()
+	registrations := scratch.registrations
+	configurationRegistrations := scratch.configurationRegistrations
+	scratch.mu.RUnlock()
+
+	dif.mu.RLock()
+	dependencies := maps.Clone(dif.dependencies)
+	dif.mu.RUnlock()
+
+	var errs []error
+	for _, name := range sortedKeys(registrations) {
+		if _, ok := registrations[name].selectFor(ctx); ok {
+			continue
+		}
+
+		// Create falls back to the untokened registration
+		if _, ok := registrations[untokenedName(name)].selectFor(ctx); ok {
+			continue
+		}
+
+		errs = append(errs, errors.New("dependency %s has no registration whose condition holds", name, DependencyMissingErrorCode))
+	}
+
+	for _, name := range sortedKeys(configurationRegistrations) {
+		reg, ok := configurationRegistrations[name].selectFor(ctx)
+		if !ok {
+			if _, ok = configurationRegistrations[untokenedName(name)].selectFor(ctx); ok {
+				continue
+			}
+
+			errs = append(errs, errors.New("configuration dependency %s has no registration whose condition holds", name, DependencyMissingErrorCode))
+			continue
+		}
+
+		if err = validateConfiguration(ctx, scratch, name, reg); err != nil {
+			errs = append(errs, errors.Wrap(err, "configuration dependency %s does not resolve", name, ConfigurationLookupErrorCode))
+		}
+	}
+
+	registered := map[GraphNodeKind]map[string]bool{GraphInstance: {}, GraphConfiguration: {}}
+	for name := range registrations {
+		first, _, _ := strings.Cut(name, ";")
+		registered[GraphInstance][first] = true
+	}
+
+	for name := range configurationRegistrations {
+		registered[GraphConfiguration][name] = true
+	}
+
+	edges := slices.SortedFunc(maps.Keys(dependencies), func(a, b GraphEdge) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To))
+	})
+	for _, edge := range edges {
+		known := registered[dependencies[edge]]
+		if known[edge.To] || (!scratch.strictTokens && known[untokenedName(edge.To)]) {
+			continue
+		}
+
+		errs = append(errs, errors.New("dependency %s of %s is not registered", edge.To, edge.From, DependencyMissingErrorCode))
+	}
+
+	return errors.Join(errs...)
+}
+
+// scratchRegistry returns an empty registry naming and resolving types as the registry does,
+// without its hooks, tracer, metrics or node cache so validating it is neither observed nor cached.
+func (dif *diRegistry) scratchRegistry() *diRegistry {
+	scratch := NewRegistry()
+	scratch.namer = dif.namer
+	scratch.strictTokens = dif.strictTokens
+	scratch.maxResolutionDepth = dif.maxResolutionDepth
+	scratch.log = dif.log
+	scratch.logLevel.Store(dif.logLevel.Load())
+	return scratch
+}
+
+// validateConfiguration runs the configuration creator of reg the way Create would, with scratch as registry.
+func validateConfiguration(ctx Context, scratch *diRegistry, name string, reg configurationRegistration) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("configuration creator panicked: %v", r, ErrorCreatingDependencyErrorCode)
+		}
+	}()
+
+	opts := RegistryOpts{}
+	if reg.opts != nil {
+		opts = *reg.opts
+	}
+	opts.Registry = scratch
+
+	validationCtx := ctx.Clone()
+
+	// pair configurations are created by CreatePair, which appends the token breadcrumb
+	first, second, isPair := strings.Cut(name, ";")
+	if isPair && scratch.IsRegistered(PairTypeName(second, first)) {
+		validationCtx.AppendBreadcrumb(opts.InjectionToken)
+	}
+
+	_, err = reg.creator(validationCtx, &opts)
+	return err
+}
+
+// untokenedName strips the injection tokens from a registration name, pair names included.
+func untokenedName(name string) string {
+	parts := strings.Split(name, ";")
+	for i, part := range parts {
+		_, parts[i], _ = splitTokenedName(part)
+	}
+
+	return strings.Join(parts, ";")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
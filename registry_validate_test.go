@@ -0,0 +1,114 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	registerStorage := func(registry Registry, token InjectionToken) {
+		require.NoError(t, RegisterPair[*databaseTest, storageConfigTest](
+			func(ctx Context, opts *RegistryOpts, cfg storageConfigTest) (*databaseTest, error) {
+				t.Fatal("factories must not run during validation")
+				return nil, nil
+			},
+			ConfigurationLookup[storageConfigTest],
+			WithRegistry(registry), WithToken(token)))
+	}
+
+	t.Run("valid wiring", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		registerStorage(registry, "")
+		require.NoError(t, Register[storageBackendTest](func(ctx Context, opts *RegistryOpts) (storageBackendTest, error) {
+			return namedStorageTest("s3"), nil
+		}, WithRegistry(registry), WithCondition(storageDriverIs("s3")), WithToken("blobs")))
+		require.NoError(t, Register[storageBackendTest](func(ctx Context, opts *RegistryOpts) (storageBackendTest, error) {
+			return namedStorageTest("local"), nil
+		}, WithRegistry(registry)))
+
+		require.NoError(t, registry.Validate(storageContext("gcs")))
+
+		_, err := registry.GetHotInstance(NewContext(), nil, PairTypeName(TypeName[storageConfigTest](), TypeName[*databaseTest]()))
+		assert.Error(t, err, "validation must not cache configurations")
+	})
+
+	t.Run("reports every problem", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		registerStorage(registry, "replica")
+		require.NoError(t, Register[storageBackendTest](func(ctx Context, opts *RegistryOpts) (storageBackendTest, error) {
+			return namedStorageTest("s3"), nil
+		}, WithRegistry(registry), WithCondition(storageDriverIs("s3"))))
+		require.NoError(t, RegisterConfiguration[someTypeConfig](func(ctx Context, opts *RegistryOpts) (someTypeConfig, error) {
+			panic("broken lookup")
+		}, WithRegistry(registry)))
+
+		err := registry.Validate(storageContext("gcs"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), TypeName[storageBackendTest]())
+		assert.Contains(t, err.Error(), "broken lookup")
+		assert.Contains(t, err.Error(), TypeName[storageConfigTest]("replica"))
+	})
+
+	t.Run("missing dependencies", func(t *testing.T) {
+		registry := NewTestRegistry(t)
+		require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+			return &loggerTest{}, nil
+		}, WithRegistry(registry)))
+		require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+			if _, err := Create[*loggerTest](ctx, WithRegistry(registry), WithToken("audit")); err != nil {
+				return nil, err
+			}
+
+			return &serviceTest{}, nil
+		}, WithRegistry(registry)))
+
+		_, err := Create[*serviceTest](NewContext(), WithRegistry(registry))
+		require.NoError(t, err)
+		require.NoError(t, registry.Validate(NewContext()), "tokened dependencies fall back to the untokened registration")
+
+		require.NoError(t, Unregister[*loggerTest](WithRegistry(registry)))
+		err = registry.Validate(NewContext())
+		require.Error(t, err)
+		_, isMissing := errors.Has(err, DependencyMissingErrorCode)
+		assert.True(t, isMissing)
+		assert.Contains(t, err.Error(), "dependency "+TypeName[*loggerTest]("audit")+" of "+TypeName[*serviceTest]()+" is not registered")
+	})
+
+	t.Run("type namer", func(t *testing.T) {
+		registry := NewRegistry(WithQualifiedTypeNames())
+		require.NoError(t, RegisterConfiguration[storageConfigTest](func(ctx Context, opts *RegistryOpts) (storageConfigTest, error) {
+			return storageConfigTest{}, nil
+		}, WithRegistry(registry)))
+		require.NoError(t, RegisterConfiguration[*overrideClientConfigTest](func(ctx Context, opts *RegistryOpts) (*overrideClientConfigTest, error) {
+			if _, err := CreateConfiguration[storageConfigTest](ctx, WithRegistry(opts.Registry)); err != nil {
+				return nil, err
+			}
+
+			return &overrideClientConfigTest{}, nil
+		}, WithRegistry(registry)))
+
+		assert.NoError(t, registry.Validate(NewContext()), "the scratch registry names types as the validated one")
+	})
+}
+
+func TestDryRun(t *testing.T) {
+	registry := NewRegistry(WithDryRun())
+	require.NoError(t, RegisterPair[*databaseTest, storageConfigTest](
+		func(ctx Context, opts *RegistryOpts, cfg storageConfigTest) (*databaseTest, error) {
+			t.Fatal("factories must not run in dry-run")
+			return nil, nil
+		},
+		ConfigurationLookup[storageConfigTest],
+		WithRegistry(registry)))
+
+	_, err := CreatePair[*databaseTest, storageConfigTest](storageContext("s3"), WithRegistry(registry))
+	require.Error(t, err)
+	_, isDryRun := errors.Has(err, DryRunErrorCode)
+	assert.True(t, isDryRun)
+
+	assert.Len(t, registry.List(), 2)
+	assert.NoError(t, registry.Validate(storageContext("s3")))
+}
@@ -0,0 +1,90 @@
+package di
+
+import (
+	"sort"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// WarmUpStep is an eager registration as planned by RegistryInspector.WarmUpPlan.
+type WarmUpStep struct {
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+}
+
+// WarmUpPlan returns the eager registrations in the order WarmUp creates them:
+// highest priority first, registration order among equal priorities.
+func (dif *diRegistry) WarmUpPlan() []WarmUpStep {
+	dif.mu.RLock()
+	defer dif.mu.RUnlock()
+
+	return dif.warmUpPlanLocked()
+}
+
+// warmUpPlanLocked computes the plan of WarmUpPlan. The caller must hold the read lock.
+func (dif *diRegistry) warmUpPlanLocked() []WarmUpStep {
+	names := make([]string, 0)
+	for name, reg := range dif.registrations {
+		if reg.warm != nil {
+			names = append(names, name)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		a, b := dif.registrations[names[i]], dif.registrations[names[j]]
+		if a.eagerPriority != b.eagerPriority {
+			return a.eagerPriority > b.eagerPriority
+		}
+
+		return a.eagerSeq < b.eagerSeq
+	})
+
+	plan := make([]WarmUpStep, 0, len(names))
+	for _, name := range names {
+		plan = append(plan, WarmUpStep{Name: name, Priority: dif.registrations[name].eagerPriority})
+	}
+
+	return plan
+}
+
+// WarmUp creates every registration marked WithEager, following WarmUpPlan. Dependencies
+// are created first as each factory resolves them, so eager registrations may rely on lazy ones.
+// Every failure is reported, WarmUp doesn't stop at the first one.
+func (dif *diRegistry) WarmUp(ctx Context) error {
+	dif.mu.RLock()
+	plan := dif.warmUpPlanLocked()
+	warms := make([]func(ctx Context) error, 0, len(plan))
+	for _, step := range plan {
+		warms = append(warms, dif.registrations[step.Name].warm)
+	}
+	dif.mu.RUnlock()
+
+	if diCtx, ok := ctx.(*context); ok {
+		warmUpCtx := diCtx.Clone().(*context)
+		warmUpCtx.isScoped, warmUpCtx.warmUp = diCtx.isScoped, true
+		ctx = warmUpCtx
+	}
+
+	var errs []error
+	for i, warm := range warms {
+		if err := warm(ctx); err != nil {
+			errs = append(errs, errors.Wrap(err, "failed to warm up %s", plan[i].Name, ErrorCreatingDependencyErrorCode))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (dif *diRegistry) recordEager(typeNameOf string, priority int, warm func(ctx Context) error) {
+	dif.mu.Lock()
+	defer dif.mu.Unlock()
+
+	reg, ok := dif.registrations[typeNameOf]
+	if !ok {
+		return
+	}
+
+	dif.eagerSeq++
+	reg.warm, reg.eagerSeq, reg.eagerPriority = warm, dif.eagerSeq, priority
+	dif.registrations[typeNameOf] = reg
+}
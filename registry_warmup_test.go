@@ -0,0 +1,123 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmUp(t *testing.T) {
+	registry := NewTestRegistry(t)
+	var created []string
+
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		created = append(created, "logger")
+		return &loggerTest{}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+		_, err := Create[*loggerTest](ctx, WithRegistry(registry))
+		created = append(created, "service")
+		return &serviceTest{}, err
+	}, WithRegistry(registry), WithEager()))
+	require.NoError(t, Register[*someType](func(ctx Context, opts *RegistryOpts) (*someType, error) {
+		created = append(created, "lazy")
+		return &someType{}, nil
+	}, WithRegistry(registry)))
+	require.NoError(t, Register[*metricsCollectorTest](func(ctx Context, opts *RegistryOpts) (*metricsCollectorTest, error) {
+		created = append(created, "metrics")
+		return &metricsCollectorTest{}, nil
+	}, WithRegistry(registry), WithEager(), WithToken("warm")))
+
+	require.NoError(t, registry.WarmUp(NewContext()))
+	assert.Equal(t, []string{"logger", "service", "metrics"}, created)
+
+	_, err := registry.GetHotInstance(NewContext(), &RegistryOpts{InjectionToken: "warm"}, TypeName[*metricsCollectorTest]("warm"))
+	assert.NoError(t, err, "eager instances are kept as hot instances")
+
+	require.NoError(t, registry.WarmUp(NewContext()))
+	assert.Len(t, created, 3, "warming up again reuses the hot instances")
+}
+
+func TestWarmUp_AggregatesErrors(t *testing.T) {
+	registry := NewTestRegistry(t)
+
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		return nil, errors.New("logger sink unavailable")
+	}, WithRegistry(registry), WithEager()))
+	require.NoError(t, RegisterPair[*databaseTest, databaseConfigTest](
+		func(ctx Context, opts *RegistryOpts, cfg databaseConfigTest) (*databaseTest, error) {
+			return &databaseTest{}, nil
+		},
+		func(ctx Context, opts *RegistryOpts) (databaseConfigTest, error) {
+			return databaseConfigTest{}, errors.New("database config missing")
+		},
+		WithRegistry(registry), WithEager()))
+
+	err := registry.WarmUp(NewContext())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "logger sink unavailable")
+	assert.Contains(t, err.Error(), "database config missing")
+}
+
+func TestWarmUp_Priority(t *testing.T) {
+	registry := NewTestRegistry(t)
+	var created []string
+
+	require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+		created = append(created, "logger")
+		return &loggerTest{}, nil
+	}, WithRegistry(registry), WithEager()))
+	require.NoError(t, Register[*databaseTest](func(ctx Context, opts *RegistryOpts) (*databaseTest, error) {
+		created = append(created, "database")
+		return &databaseTest{}, nil
+	}, WithRegistry(registry), WithWarmupPriority(100)))
+	require.NoError(t, Register[*metricsCollectorTest](func(ctx Context, opts *RegistryOpts) (*metricsCollectorTest, error) {
+		created = append(created, "metrics")
+		return &metricsCollectorTest{}, nil
+	}, WithRegistry(registry), WithWarmupPriority(-1)))
+	require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+		created = append(created, "service")
+		return &serviceTest{}, nil
+	}, WithRegistry(registry), WithEager()))
+
+	assert.Equal(t, []WarmUpStep{
+		{Name: TypeName[*databaseTest](), Priority: 100},
+		{Name: TypeName[*loggerTest](), Priority: 0},
+		{Name: TypeName[*serviceTest](), Priority: 0},
+		{Name: TypeName[*metricsCollectorTest](), Priority: -1},
+	}, registry.WarmUpPlan())
+	assert.Empty(t, created, "planning must not create anything")
+
+	require.NoError(t, registry.WarmUp(NewContext()))
+	assert.Equal(t, []string{"database", "logger", "service", "metrics"}, created)
+}
+
+func TestCreateInfo(t *testing.T) {
+	newRegistry := func(infos map[string]CreateInfo) *diRegistry {
+		registry := NewRegistry()
+		require.NoError(t, Register[*loggerTest](func(ctx Context, opts *RegistryOpts) (*loggerTest, error) {
+			infos["logger"] = ctx.CreateInfo()
+			return &loggerTest{}, nil
+		}, WithRegistry(registry)))
+		require.NoError(t, Register[*serviceTest](func(ctx Context, opts *RegistryOpts) (*serviceTest, error) {
+			infos["service"] = ctx.CreateInfo()
+			log, err := Create[*loggerTest](ctx, WithRegistry(registry))
+			return &serviceTest{Logger: log}, err
+		}, WithRegistry(registry), WithEager()))
+		return registry
+	}
+
+	infos := map[string]CreateInfo{}
+	_, err := Create[*serviceTest](NewContext(), WithRegistry(newRegistry(infos)))
+	require.NoError(t, err)
+	assert.Equal(t, CreateInfo{TypeName: TypeName[*serviceTest]()}, infos["service"])
+	assert.Equal(t, CreateInfo{TypeName: TypeName[*loggerTest](), Parent: TypeName[*serviceTest](), Depth: 1}, infos["logger"])
+
+	infos = map[string]CreateInfo{}
+	require.NoError(t, newRegistry(infos).WarmUp(NewContext()))
+	assert.True(t, infos["service"].WarmUp)
+	assert.True(t, infos["logger"].WarmUp)
+	assert.Equal(t, 1, infos["logger"].Depth)
+}
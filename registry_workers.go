@@ -0,0 +1,207 @@
+package di
+
+import (
+	goctx "context"
+	goerrors "errors"
+	"sort"
+	"sync"
+
+	"github.com/pixie-sh/errors-go"
+)
+
+// WorkerState is the lifecycle state of a worker started through RegistryLifecycle.Go.
+type WorkerState string
+
+const (
+	WorkerPending WorkerState = "pending" // registered, waiting for RegistryLifecycle.Ready
+	WorkerRunning WorkerState = "running"
+	WorkerStopped WorkerState = "stopped" // returned without error
+	WorkerFailed  WorkerState = "failed"  // returned an error or panicked
+)
+
+// WorkerStatus reports a worker started through RegistryLifecycle.Go.
+type WorkerStatus struct {
+	Name  string      `json:"name"`
+	State WorkerState `json:"state"`
+	Err   error       `json:"-"`
+}
+
+type worker struct {
+	name  string
+	ctx   Context
+	fn    func(ctx Context) error
+	state WorkerState
+	err   error
+}
+
+// workerGroup runs the workers of a registry, tying them to its Ready and Shutdown lifecycle.
+type workerGroup struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	workers  []*worker
+	ready    bool
+	shutdown bool
+	cancel   goctx.CancelFunc
+	done     goctx.Context
+}
+
+// Go runs fn as a worker managed by the registry under name. Workers registered before Ready
+// wait for it, the ones registered after start right away. The context given to fn is cancelled
+// by Shutdown, which waits for every worker to return.
+func (dif *diRegistry) Go(ctx Context, name string, fn func(ctx Context) error) error {
+	g := &dif.workers
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.shutdown {
+		return errors.New("cannot start worker %s, registry is shut down", name, ErrorCreatingDependencyErrorCode)
+	}
+
+	w := &worker{name: name, ctx: ctx, fn: fn, state: WorkerPending}
+	g.workers = append(g.workers, w)
+	if g.ready {
+		g.startLocked(w)
+	}
+
+	return nil
+}
+
+// Ready starts the workers registered through Go, usually once the application is wired and warmed up.
+func (dif *diRegistry) Ready() error {
+	g := &dif.workers
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.shutdown {
+		return errors.New("registry is shut down", ErrorCreatingDependencyErrorCode)
+	}
+
+	if g.ready {
+		return nil
+	}
+
+	g.ready = true
+	for _, w := range g.workers {
+		g.startLocked(w)
+	}
+
+	return nil
+}
+
+// Shutdown cancels the workers and waits for them to return, or for ctx to be done, then disposes
+// the Disposable instances created by the registry. It reports the worker and Dispose failures,
+// context cancellation errors excluded.
+func (dif *diRegistry) Shutdown(ctx Context) error {
+	g := &dif.workers
+
+	g.mu.Lock()
+	g.shutdown = true
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.mu.Unlock()
+
+	waited := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "shutdown interrupted waiting for workers", ErrorCreatingDependencyErrorCode)
+	}
+
+	var errs []error
+	g.mu.Lock()
+	for _, w := range g.workers {
+		if w.err != nil {
+			errs = append(errs, errors.Wrap(w.err, "worker %s failed", w.name, ErrorCreatingDependencyErrorCode))
+		}
+	}
+	g.mu.Unlock()
+
+	if err := dif.dispose(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// reset cancels the workers of the group and waits for them to return, then forgets them so the
+// group runs the ones registered next. Their failures are left to Shutdown to report.
+func (g *workerGroup) reset() {
+	g.mu.Lock()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.shutdown = true
+	g.mu.Unlock()
+
+	g.wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.workers, g.ready, g.shutdown, g.cancel, g.done = nil, false, false, nil, nil
+}
+
+// Workers reports the state of the workers started through Go, sorted by name.
+func (dif *diRegistry) Workers() []WorkerStatus {
+	g := &dif.workers
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	statuses := make([]WorkerStatus, 0, len(g.workers))
+	for _, w := range g.workers {
+		statuses = append(statuses, WorkerStatus{Name: w.name, State: w.state, Err: w.err})
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// startLocked runs w in its own goroutine. The caller must hold g.mu.
+func (g *workerGroup) startLocked(w *worker) {
+	if g.done == nil {
+		g.done, g.cancel = goctx.WithCancel(goctx.Background())
+	}
+
+	workerCtx, cancel := goctx.WithCancel(w.ctx)
+	stop := goctx.AfterFunc(g.done, cancel)
+
+	w.state = WorkerRunning
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer stop()
+		defer cancel()
+
+		err := runWorker(NewContext(w.ctx, workerCtx), w.fn)
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		w.state = WorkerStopped
+		if err != nil && !goerrors.Is(err, goctx.Canceled) {
+			w.state, w.err = WorkerFailed, err
+		}
+	}()
+}
+
+func runWorker(ctx Context, fn func(ctx Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("worker panicked: %v", r, ErrorCreatingDependencyErrorCode)
+		}
+	}()
+
+	return fn(ctx)
+}
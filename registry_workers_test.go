@@ -0,0 +1,81 @@
+package di
+
+import (
+	goctx "context"
+	"testing"
+	"time"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryGo(t *testing.T) {
+	registry := NewTestRegistry(t)
+	started := make(chan string, 3)
+
+	require.NoError(t, registry.Go(NewContext(), "consumer", func(ctx Context) error {
+		started <- "consumer"
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	require.NoError(t, registry.Go(NewContext(), "failing", func(ctx Context) error {
+		started <- "failing"
+		return errors.New("queue unreachable")
+	}))
+
+	select {
+	case name := <-started:
+		t.Fatalf("worker %s started before Ready", name)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	assert.Equal(t, []WorkerStatus{
+		{Name: "consumer", State: WorkerPending},
+		{Name: "failing", State: WorkerPending},
+	}, registry.Workers())
+
+	require.NoError(t, registry.Ready())
+	require.NoError(t, registry.Go(NewContext(), "late", func(ctx Context) error {
+		started <- "late"
+		<-ctx.Done()
+		return nil
+	}))
+
+	for range 3 {
+		<-started
+	}
+
+	ctx, cancel := goctx.WithTimeout(goctx.Background(), time.Second)
+	defer cancel()
+
+	err := registry.Shutdown(NewContext(ctx))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "queue unreachable")
+
+	statuses := registry.Workers()
+	require.Len(t, statuses, 3)
+	assert.Equal(t, WorkerStopped, statuses[0].State)
+	assert.Equal(t, WorkerFailed, statuses[1].State)
+	assert.Equal(t, WorkerStopped, statuses[2].State)
+
+	assert.Error(t, registry.Go(NewContext(), "too late", func(ctx Context) error { return nil }))
+}
+
+func TestRegistryShutdown_Deadline(t *testing.T) {
+	registry := NewTestRegistry(t)
+	release := make(chan struct{})
+	defer close(release)
+
+	require.NoError(t, registry.Go(NewContext(), "stubborn", func(ctx Context) error {
+		<-release
+		return nil
+	}))
+	require.NoError(t, registry.Ready())
+
+	ctx, cancel := goctx.WithTimeout(goctx.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Error(t, registry.Shutdown(NewContext(ctx)))
+	assert.Equal(t, WorkerRunning, registry.Workers()[0].State)
+}
@@ -0,0 +1,73 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type portTest int
+
+type pointerCloserTest struct {
+	closed bool
+}
+
+func (p *pointerCloserTest) Close() error {
+	p.closed = true
+	return nil
+}
+
+type closerIfaceTest interface {
+	Close() error
+}
+
+func TestSafeConvertTypeAssert(t *testing.T) {
+	_, ok := SafeTypeAssert[int](portTest(8080))
+	assert.False(t, ok, "conversions are opt-in")
+
+	port, ok := SafeConvertTypeAssert[int](portTest(8080))
+	require.True(t, ok)
+	assert.Equal(t, 8080, port)
+
+	named, ok := SafeConvertTypeAssert[portTest](8080)
+	require.True(t, ok)
+	assert.Equal(t, portTest(8080), named)
+
+	value := portTest(443)
+	port, ok = SafeConvertTypeAssert[int](&value)
+	require.True(t, ok)
+	assert.Equal(t, 443, port)
+
+	portPtr, ok := SafeConvertTypeAssert[*int](portTest(443))
+	require.True(t, ok)
+	assert.Equal(t, 443, *portPtr)
+
+	_, ok = SafeConvertTypeAssert[string](65)
+	assert.False(t, ok, "conversions changing the value are refused")
+
+	_, ok = SafeConvertTypeAssert[int](3.7)
+	assert.False(t, ok, "conversions changing the value are refused")
+
+	_, ok = SafeConvertTypeAssert[int]((*portTest)(nil))
+	assert.False(t, ok)
+
+	closer, ok := SafeConvertTypeAssert[closerIfaceTest](pointerCloserTest{})
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+}
+
+func TestWithConvertibleTypes(t *testing.T) {
+	registry := NewTestRegistry(t)
+	require.NoError(t, registry.Register(TypeName[int](), func(ctx Context, opts *RegistryOpts, _ any) (any, error) {
+		return portTest(8080), nil
+	}, &RegistryOpts{}))
+
+	assert.Panics(t, func() {
+		_, _ = Create[int](NewContext(), WithRegistry(registry))
+	})
+
+	port, err := Create[int](NewContext(), WithRegistry(registry), WithConvertibleTypes())
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+}
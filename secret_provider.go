@@ -0,0 +1,139 @@
+package di
+
+import (
+	goctx "context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// SecretProvider resolves the ${secret.name} references of configurations from a secret store.
+// Implement it to pull secrets from Vault, a cloud secret manager or any other store, and plug it
+// with SetSecretProvider. Implementations must be safe for concurrent use.
+type SecretProvider interface {
+	Secret(ctx goctx.Context, name string) (string, error)
+}
+
+var (
+	secretProviderMu sync.RWMutex
+	secretProvider   SecretProvider = EnvSecretProvider{}
+	secretProviders                 = map[string]SecretProvider{}
+
+	secretReferenceRegex = regexp.MustCompile(`"?\$\{secret\.([^}]+)\}"?`)
+)
+
+// SetSecretProvider makes ResolveDIReferences, and so every configuration load, resolve the
+// ${secret.name} references through provider instead of the default EnvSecretProvider.
+func SetSecretProvider(provider SecretProvider) {
+	secretProviderMu.Lock()
+	defer secretProviderMu.Unlock()
+
+	secretProvider = provider
+}
+
+// RegisterSecretProvider routes the references prefixed by scheme to provider, ${secret.aws:/db/password}
+// resolving /db/password with the provider registered for aws. References without a registered
+// scheme resolve through the provider set with SetSecretProvider.
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProviderMu.Lock()
+	defer secretProviderMu.Unlock()
+
+	secretProviders[scheme] = provider
+}
+
+// UnregisterSecretProvider removes the provider registered for scheme, reporting whether there was one.
+// Its references resolve through the provider set with SetSecretProvider again.
+func UnregisterSecretProvider(scheme string) bool {
+	secretProviderMu.Lock()
+	defer secretProviderMu.Unlock()
+
+	_, ok := secretProviders[scheme]
+	delete(secretProviders, scheme)
+	return ok
+}
+
+// secretProviderFor returns the provider of the secret name and the name to resolve with it.
+func secretProviderFor(name string) (SecretProvider, string) {
+	secretProviderMu.RLock()
+	defer secretProviderMu.RUnlock()
+
+	if scheme, rest, ok := strings.Cut(name, ":"); ok {
+		if provider, registered := secretProviders[scheme]; registered {
+			return provider, rest
+		}
+	}
+
+	return secretProvider, name
+}
+
+// EnvSecretProvider is the default SecretProvider, reading secrets from environment variables.
+// The variable of a secret is its name upper cased, with every character but letters and digits
+// replaced by an underscore, after Prefix: ${secret.db.password} reads DB_PASSWORD.
+type EnvSecretProvider struct {
+	Prefix string
+}
+
+func (p EnvSecretProvider) Secret(_ goctx.Context, name string) (string, error) {
+	variable := p.Prefix + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+
+	value, ok := os.LookupEnv(variable)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", variable)
+	}
+
+	return value, nil
+}
+
+// resolveSecretReferences replaces the ${secret.name} references found in the strings of a JSON document
+// with the secrets of the SecretProvider.
+func resolveSecretReferences(ctx goctx.Context, jsonStr string) (string, error) {
+	if !strings.Contains(jsonStr, "${secret.") {
+		return jsonStr, nil
+	}
+
+	return replaceStringReferences(jsonStr, secretReferenceRegex, func(name string) (string, error) {
+		provider, providerName := secretProviderFor(name)
+		secret, err := provider.Secret(ctx, providerName)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve secret reference ${secret.%s}: %w", name, err)
+		}
+
+		return secret, nil
+	})
+}
+
+// replaceStringReferences replaces the references matched by regex in the strings of a JSON document
+// with the values resolved from their first submatch, stopping at the first error.
+func replaceStringReferences(jsonStr string, regex *regexp.Regexp, resolve func(name string) (string, error)) (string, error) {
+	var err error
+	resolved := regex.ReplaceAllStringFunc(jsonStr, func(reference string) string {
+		if err != nil {
+			return reference
+		}
+
+		name := regex.FindStringSubmatch(reference)[1]
+		value, resolveErr := resolve(name)
+		if resolveErr != nil {
+			err = resolveErr
+			return reference
+		}
+
+		// keep the quotes around the reference, the value may be part of a longer string
+		encoded, _ := gojson.Marshal(value)
+		escaped := string(encoded[1 : len(encoded)-1])
+		unquoted := strings.TrimSuffix(strings.TrimPrefix(reference, `"`), `"`)
+		return strings.Replace(reference, unquoted, escaped, 1)
+	})
+
+	return resolved, err
+}
@@ -0,0 +1,70 @@
+package di
+
+import (
+	goctx "context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mapSecretProvider map[string]string
+
+func (p mapSecretProvider) Secret(_ goctx.Context, name string) (string, error) {
+	secret, ok := p[name]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", name)
+	}
+
+	return secret, nil
+}
+
+func TestSecretReferences(t *testing.T) {
+	var cfg struct {
+		Database struct {
+			Password string `json:"password"`
+			DSN      string `json:"dsn"`
+		} `json:"database"`
+		Replica struct {
+			Password string `json:"password"`
+		} `json:"replica"`
+	}
+
+	document := []byte(`{
+		"database": {"password": "${secret.db.password}", "dsn": "postgres://app:${secret.db.password}@db/app"},
+		"replica": "${di.database}"
+	}`)
+
+	t.Setenv("DB_PASSWORD", `p"ss`)
+	require.NoError(t, UnmarshalJSONWithDIResolution(document, &cfg))
+	assert.Equal(t, `p"ss`, cfg.Database.Password)
+	assert.Equal(t, `postgres://app:p"ss@db/app`, cfg.Database.DSN)
+	assert.Equal(t, `p"ss`, cfg.Replica.Password)
+
+	t.Run("custom provider", func(t *testing.T) {
+		SetSecretProvider(mapSecretProvider{"db.password": "vault"})
+		t.Cleanup(func() { SetSecretProvider(EnvSecretProvider{}) })
+
+		require.NoError(t, UnmarshalJSONWithDIResolution(document, &cfg))
+		assert.Equal(t, "vault", cfg.Database.Password)
+
+		_, err := ResolveDIReferences(`{"token": "${secret.api.token}"}`)
+		assert.ErrorContains(t, err, "failed to resolve secret reference ${secret.api.token}: secret api.token not found")
+	})
+
+	t.Run("scheme provider", func(t *testing.T) {
+		RegisterSecretProvider("vault", mapSecretProvider{"db.password": "vault"})
+
+		resolved, err := ResolveDIReferences(`{"password": "${secret.vault:db.password}"}`)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"password": "vault"}`, resolved)
+
+		assert.True(t, UnregisterSecretProvider("vault"))
+		assert.False(t, UnregisterSecretProvider("vault"))
+		t.Setenv("VAULT_DB_PASSWORD", "env")
+		resolved, err = ResolveDIReferences(`{"password": "${secret.vault:db.password}"}`)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"password": "env"}`, resolved, "unregistered schemes resolve through the default provider")
+	})
+}
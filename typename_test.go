@@ -1,6 +1,8 @@
 package di
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -66,3 +68,38 @@ func TestTypeName(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeNameCache(t *testing.T) {
+	first := TypeName[cachedNameRepoTest[TestNestedStruct]]()
+	_, cached := typeNameCache.Load(reflect.TypeOf((*cachedNameRepoTest[TestNestedStruct])(nil)).Elem())
+	assert.True(t, cached)
+	assert.Equal(t, first, TypeName[*cachedNameRepoTest[TestNestedStruct]]())
+	assert.Equal(t, "ro:"+first, TypeName[cachedNameRepoTest[TestNestedStruct]]("ro"))
+}
+
+type cachedNameRepoTest[T any] struct{}
+
+func BenchmarkTypeName(b *testing.B) {
+	b.Run("untokened", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = TypeName[*cachedNameRepoTest[TestNestedStruct]]()
+		}
+	})
+
+	b.Run("token", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = TypeName[*cachedNameRepoTest[TestNestedStruct]]("ro")
+		}
+	})
+
+	// renders the tokened name on every call, as TypeName did before caching
+	b.Run("token uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		t := reflect.TypeOf((*cachedNameRepoTest[TestNestedStruct])(nil))
+		for b.Loop() {
+			_ = fmt.Sprintf("%s:%s", InjectionToken("ro"), t.Elem().String())
+		}
+	})
+}